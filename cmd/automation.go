@@ -1,8 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"strconv"
 
+	"github.com/abhinavxd/libredesk/internal/automation"
 	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/valyala/fasthttp"
@@ -63,6 +65,13 @@ func handleUpdateAutomationRule(r *fastglue.Request) error {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", nil, envelope.InputError)
 	}
 
+	if err := automation.ValidateRules(rule.Rules); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := automation.ValidateSchedule(rule.Type, rule.Schedule); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
 	if err = app.automation.UpdateRule(id, rule);err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -78,6 +87,12 @@ func handleCreateAutomationRule(r *fastglue.Request) error {
 	if err := r.Decode(&rule, "json"); err != nil {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", nil, envelope.InputError)
 	}
+	if err := automation.ValidateRules(rule.Rules); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := automation.ValidateSchedule(rule.Type, rule.Schedule); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
 	if err := app.automation.CreateRule(rule); err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -134,3 +149,61 @@ func handleUpdateAutomationRuleExecutionMode(r *fastglue.Request) error {
 	}
 	return r.SendEnvelope("Execution mode updated successfully")
 }
+
+// handleDryRunAutomationRule evaluates an unsaved rule against a conversation or a sample of recent
+// conversations and returns the result of every condition without applying any actions.
+func handleDryRunAutomationRule(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = struct {
+			Rules            json.RawMessage `json:"rules"`
+			ConversationUUID string          `json:"conversation_uuid"`
+			SampleSize       int             `json:"sample_size"`
+		}{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", nil, envelope.InputError)
+	}
+	out, err := app.automation.DryRun(req.Rules, req.ConversationUUID, req.SampleSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleGetAutomationRuleExecutions returns the execution log of an automation rule.
+func handleGetAutomationRuleExecutions(r *fastglue.Request) error {
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest,
+			"Invalid rule `id`.", nil, envelope.InputError)
+	}
+	return sendRuleExecutions(r, id, 0)
+}
+
+// sendRuleExecutions sends a page of automation rule executions filtered by rule and conversation, 0 matches all.
+func sendRuleExecutions(r *fastglue.Request, ruleID, conversationID int) error {
+	var (
+		app         = r.Context.(*App)
+		page, _     = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page")))
+		pageSize, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page_size")))
+		total       = 0
+	)
+	executions, pageSize, err := app.automation.GetExecutions(ruleID, conversationID, page, pageSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if len(executions) > 0 {
+		total = executions[0].Total
+	}
+	if page < 1 {
+		page = 1
+	}
+	return r.SendEnvelope(envelope.PageResults{
+		Total:      total,
+		Results:    executions,
+		Page:       page,
+		PerPage:    pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	})
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -10,8 +12,11 @@ import (
 	authzModels "github.com/abhinavxd/libredesk/internal/authz/models"
 	"github.com/abhinavxd/libredesk/internal/automation/models"
 	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	smodels "github.com/abhinavxd/libredesk/internal/conversation/status/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/valyala/fasthttp"
 	"github.com/volatiletech/null/v9"
 	"github.com/zerodha/fastglue"
@@ -135,26 +140,8 @@ func handleGetViewConversations(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
-	// Prepare lists user has access to based on user permissions, internally this affects the SQL query.
-	lists := []string{}
-	for _, perm := range user.Permissions {
-		if perm == authzModels.PermConversationsReadAll {
-			// No further lists required as user has access to all conversations.
-			lists = []string{cmodels.AllConversations}
-			break
-		}
-		if perm == authzModels.PermConversationsReadUnassigned {
-			lists = append(lists, cmodels.UnassignedConversations)
-		}
-		if perm == authzModels.PermConversationsReadAssigned {
-			lists = append(lists, cmodels.AssignedConversations)
-		}
-		if perm == authzModels.PermConversationsReadTeamInbox {
-			lists = append(lists, cmodels.TeamUnassignedConversations)
-		}
-	}
-
 	// No lists found, user doesn't have access to any conversations.
+	lists := getConversationLists(user)
 	if len(lists) == 0 {
 		return r.SendErrorEnvelope(fasthttp.StatusForbidden, "Permission denied", nil, envelope.PermissionError)
 	}
@@ -234,7 +221,7 @@ func handleGetConversation(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
-	conv, err := enforceConversationAccess(app, uuid, user)
+	conv, err := enforceConversationReadAccess(app, uuid, user)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -276,7 +263,7 @@ func handleGetConversationParticipants(r *fastglue.Request) error {
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
-	_, err = enforceConversationAccess(app, uuid, user)
+	_, err = enforceConversationReadAccess(app, uuid, user)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -287,6 +274,170 @@ func handleGetConversationParticipants(r *fastglue.Request) error {
 	return r.SendEnvelope(p)
 }
 
+// handleGetConversationFollowers returns the users following a conversation.
+func handleGetConversationFollowers(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	_, err = enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	followers, err := app.conversation.GetConversationFollowers(uuid)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(followers)
+}
+
+// handleFollowConversation adds the current user as a follower of a conversation.
+func handleFollowConversation(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	_, err = enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.conversation.AddConversationFollower(uuid, user.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleUnfollowConversation removes the current user from the followers of a conversation.
+func handleUnfollowConversation(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	if err := app.conversation.RemoveConversationFollower(uuid, auser.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetConversationTranscript exports a conversation transcript as printable HTML or as an EML file.
+// HTML transcripts are meant to be printed or saved as PDF from the browser.
+func handleGetConversationTranscript(r *fastglue.Request) error {
+	var (
+		app            = r.Context.(*App)
+		uuid           = r.RequestCtx.UserValue("uuid").(string)
+		auser          = r.RequestCtx.UserValue("user").(amodels.User)
+		format         = string(r.RequestCtx.QueryArgs().Peek("format"))
+		includePrivate = r.RequestCtx.QueryArgs().GetBool("include_private")
+	)
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conversation, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	var (
+		body        []byte
+		contentType string
+		fileName    = "conversation-" + conversation.ReferenceNumber
+	)
+	switch format {
+	case "", "html":
+		html, err := app.conversation.GetTranscriptHTML(uuid, includePrivate)
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		body, contentType, fileName = []byte(html), "text/html; charset=utf-8", fileName+".html"
+	case "eml":
+		if body, err = app.conversation.GetTranscriptEML(uuid, includePrivate); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		contentType, fileName = "message/rfc822", fileName+".eml"
+	default:
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid `format`, must be `html` or `eml`", nil, envelope.InputError)
+	}
+
+	// HTML is shown inline so it can be printed, EML is downloaded.
+	disposition := "inline"
+	if format == "eml" {
+		disposition = "attachment"
+	}
+	r.RequestCtx.Response.Header.Set("Content-Type", contentType)
+	r.RequestCtx.Response.Header.Set("Content-Disposition", fmt.Sprintf("%s; filename=\"%s\"", disposition, fileName))
+	r.RequestCtx.SetBody(body)
+	return nil
+}
+
+type transcriptSendReq struct {
+	To             []string `json:"to"`
+	IncludePrivate bool     `json:"include_private"`
+}
+
+// handleSendConversationTranscript emails a conversation transcript to the passed addresses or to the contact.
+func handleSendConversationTranscript(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = transcriptSendReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid request", nil, envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.conversation.SendTranscript(uuid, req.IncludePrivate, req.To); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetMentions returns the mentions of the current user in private notes.
+func handleGetMentions(r *fastglue.Request) error {
+	var (
+		app         = r.Context.(*App)
+		auser       = r.RequestCtx.UserValue("user").(amodels.User)
+		page, _     = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page")))
+		pageSize, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page_size")))
+		total       = 0
+	)
+	mentions, pageSize, err := app.conversation.GetUserMentions(auser.ID, page, pageSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if len(mentions) > 0 {
+		total = mentions[0].Total
+	}
+	if page < 1 {
+		page = 1
+	}
+	return r.SendEnvelope(envelope.PageResults{
+		Total:      total,
+		Results:    mentions,
+		Page:       page,
+		PerPage:    pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	})
+}
+
 // handleUpdateUserAssignee updates the user assigned to a conversation.
 func handleUpdateUserAssignee(r *fastglue.Request) error {
 	var (
@@ -404,15 +555,6 @@ func handleUpdateConversationStatus(r *fastglue.Request) error {
 	if status == "" {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid `status`", nil, envelope.InputError)
 	}
-	if snoozedUntil == "" && status == cmodels.StatusSnoozed {
-		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid `snoozed_until`", nil, envelope.InputError)
-	}
-	if status == cmodels.StatusSnoozed {
-		_, err := time.ParseDuration(snoozedUntil)
-		if err != nil {
-			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid `snoozed_until`", nil, envelope.InputError)
-		}
-	}
 
 	// Enforce conversation access.
 	user, err := app.user.GetAgent(auser.ID)
@@ -424,8 +566,17 @@ func handleUpdateConversationStatus(r *fastglue.Request) error {
 		return sendErrorEnvelope(r, err)
 	}
 
+	// Fetch the status, its category decides the resolve checks and CSAT.
+	newStatus, err := app.status.GetByName(status)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if snoozedUntil == "" && newStatus.IsSnooze {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid `snoozed_until`", nil, envelope.InputError)
+	}
+
 	// Make sure a user is assigned before resolving conversation.
-	if status == cmodels.StatusResolved && conversation.AssignedUserID.Int == 0 {
+	if newStatus.Category == smodels.CategorySolved && conversation.AssignedUserID.Int == 0 {
 		return sendErrorEnvelope(r, envelope.NewError(envelope.InputError, "Cannot resolve the conversation without an assigned user, Please assign a user before attempting to resolve.", nil))
 	}
 
@@ -437,8 +588,8 @@ func handleUpdateConversationStatus(r *fastglue.Request) error {
 	// Evaluate automation rules.
 	app.automation.EvaluateConversationUpdateRules(uuid, models.EventConversationStatusChange)
 
-	// If status is `Resolved`, send CSAT survey if enabled on inbox.
-	if status == cmodels.StatusResolved {
+	// If status is in the solved category, send CSAT survey if enabled on inbox.
+	if newStatus.Category == smodels.CategorySolved {
 		// Check if CSAT is enabled on the inbox and send CSAT survey message.
 		inbox, err := app.inbox.GetDBRecord(conversation.InboxID)
 		if err != nil {
@@ -513,6 +664,24 @@ func handleDashboardCharts(r *fastglue.Request) error {
 	return r.SendEnvelope(charts)
 }
 
+// handleGetConversationAutomationExecutions returns the automation rules that ran on a conversation.
+func handleGetConversationAutomationExecutions(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+	)
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	conversation, err := enforceConversationAccess(app, uuid, user)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return sendRuleExecutions(r, 0, conversation.ID)
+}
+
 // enforceConversationAccess fetches the conversation and checks if the user has access to it.
 func enforceConversationAccess(app *App, uuid string, user umodels.User) (*cmodels.Conversation, error) {
 	conversation, err := app.conversation.GetConversation(0, uuid)
@@ -529,6 +698,23 @@ func enforceConversationAccess(app *App, uuid string, user umodels.User) (*cmode
 	return &conversation, nil
 }
 
+// enforceConversationReadAccess fetches the conversation and checks if the user can view it, including through a mention.
+// Use it only in handlers that do not change the conversation.
+func enforceConversationReadAccess(app *App, uuid string, user umodels.User) (*cmodels.Conversation, error) {
+	conversation, err := app.conversation.GetConversation(0, uuid)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := app.authz.EnforceConversationReadAccess(user, conversation)
+	if err != nil {
+		return nil, envelope.NewError(envelope.GeneralError, "Error checking permissions", nil)
+	}
+	if !allowed {
+		return nil, envelope.NewError(envelope.PermissionError, "Permission denied", nil)
+	}
+	return &conversation, nil
+}
+
 // handleRemoveUserAssignee removes the user assigned to a conversation.
 func handleRemoveUserAssignee(r *fastglue.Request) error {
 	var (
@@ -544,9 +730,13 @@ func handleRemoveUserAssignee(r *fastglue.Request) error {
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
-	if err = app.conversation.RemoveConversationAssignee(uuid, "user"); err != nil {
+	if err = app.conversation.RemoveConversationAssignee(uuid, cmodels.AssigneeTypeUser, user); err != nil {
 		return sendErrorEnvelope(r, err)
 	}
+
+	// Evaluate automation rules.
+	app.automation.EvaluateConversationUpdateRules(uuid, models.EventConversationUserAssigned)
+
 	return r.SendEnvelope(true)
 }
 
@@ -565,9 +755,13 @@ func handleRemoveTeamAssignee(r *fastglue.Request) error {
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
-	if err = app.conversation.RemoveConversationAssignee(uuid, "team"); err != nil {
+	if err = app.conversation.RemoveConversationAssignee(uuid, cmodels.AssigneeTypeTeam, user); err != nil {
 		return sendErrorEnvelope(r, err)
 	}
+
+	// Evaluate automation rules.
+	app.automation.EvaluateConversationUpdateRules(uuid, models.EventConversationTeamAssigned)
+
 	return r.SendEnvelope(true)
 }
 
@@ -669,6 +863,9 @@ func handleCreateConversation(r *fastglue.Request) error {
 		app.conversation.UpdateConversationTeamAssignee(conversationUUID, assignedTeamID, user)
 	}
 
+	// Send the created event to webhooks.
+	app.webhook.TriggerConversationEvent(wmodels.EventConversationCreated, conversationID, conversationUUID, nil)
+
 	// Send the created conversation back to the client.
 	conversation, err := app.conversation.GetConversation(conversationID, "")
 	if err != nil {
@@ -676,3 +873,161 @@ func handleCreateConversation(r *fastglue.Request) error {
 	}
 	return r.SendEnvelope(conversation)
 }
+
+// getConversationLists returns the conversation lists the user has access to based on their permissions, internally this affects the SQL query.
+func getConversationLists(user umodels.User) []string {
+	lists := []string{}
+	for _, perm := range user.Permissions {
+		if perm == authzModels.PermConversationsReadAll {
+			// No further lists required as user has access to all conversations.
+			return []string{cmodels.AllConversations}
+		}
+		if perm == authzModels.PermConversationsReadUnassigned {
+			lists = append(lists, cmodels.UnassignedConversations)
+		}
+		if perm == authzModels.PermConversationsReadAssigned {
+			lists = append(lists, cmodels.AssignedConversations)
+		}
+		if perm == authzModels.PermConversationsReadTeamInbox {
+			lists = append(lists, cmodels.TeamUnassignedConversations)
+		}
+	}
+	return lists
+}
+
+type bulkActionReq struct {
+	ConversationUUIDs []string            `json:"conversation_uuids"`
+	ViewID            int                 `json:"view_id"`
+	Filters           string              `json:"filters"`
+	Actions           []models.RuleAction `json:"actions"`
+	MacroID           int                 `json:"macro_id"`
+}
+
+// handleBulkConversationAction starts a background job applying actions or a macro to many conversations.
+// Conversations are picked either from the passed UUIDs or from a view or filters, progress is pushed over websocket.
+func handleBulkConversationAction(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		req   = bulkActionReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid request", nil, envelope.InputError)
+	}
+
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Pick actions from the macro if one is passed.
+	if req.MacroID > 0 {
+		if len(req.Actions) > 0 {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Pass either actions or a macro, not both", nil, envelope.InputError)
+		}
+		macro, err := app.macro.Get(req.MacroID)
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		if err := json.Unmarshal(macro.Actions, &req.Actions); err != nil {
+			app.lo.Error("error unmarshalling macro actions", "macro_id", macro.ID, "error", err)
+			return r.SendErrorEnvelope(fasthttp.StatusInternalServerError, "Error unmarshalling macro actions", nil, envelope.GeneralError)
+		}
+	}
+	if len(req.Actions) == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "No actions to apply", nil, envelope.InputError)
+	}
+
+	// Validate action permissions, bulk actions follow the same rules as macros.
+	for _, act := range req.Actions {
+		if !isMacroActionAllowed(act.Type) {
+			app.lo.Warn("action not allowed in bulk action", "action", act.Type, "user_id", user.ID)
+			return r.SendErrorEnvelope(fasthttp.StatusForbidden, "Action not allowed in bulk action", nil, envelope.PermissionError)
+		}
+		if !hasActionPermission(act, user.Permissions) {
+			app.lo.Warn("no permission to execute bulk action", "action", act.Type, "user_id", user.ID)
+			return r.SendErrorEnvelope(fasthttp.StatusForbidden, "No permission to execute this action", nil, envelope.PermissionError)
+		}
+	}
+
+	uuids := req.ConversationUUIDs
+	if len(uuids) == 0 {
+		if uuids, err = getBulkConversationUUIDs(app, user, req.ViewID, req.Filters); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+	uuids = stringutil.RemoveEmpty(uuids)
+	slices.Sort(uuids)
+	uuids = slices.Compact(uuids)
+
+	// Access is checked for every conversation when the job runs.
+	canAccess := func(conversation cmodels.Conversation) bool {
+		allowed, err := app.authz.EnforceConversationAccess(user, conversation)
+		return err == nil && allowed
+	}
+	job, err := app.conversation.StartBulkJob(user, uuids, req.Actions, canAccess)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	if req.MacroID > 0 {
+		app.macro.IncrementUsageCount(req.MacroID)
+	}
+
+	return r.SendEnvelope(job)
+}
+
+// handleGetBulkConversationAction returns the progress and per-conversation results of a bulk job.
+func handleGetBulkConversationAction(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		id    = r.RequestCtx.UserValue("id").(string)
+	)
+	job, err := app.conversation.GetBulkJob(id, auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(job)
+}
+
+// getBulkConversationUUIDs returns the UUIDs of conversations matching a view or filters that the user can list.
+func getBulkConversationUUIDs(app *App, user umodels.User, viewID int, filters string) ([]string, error) {
+	if viewID > 0 {
+		view, err := app.view.Get(viewID)
+		if err != nil {
+			return nil, err
+		}
+		if view.UserID != user.ID {
+			return nil, envelope.NewError(envelope.PermissionError, "You don't have access to this view.", nil)
+		}
+		filters = string(view.Filters)
+	} else if filters == "" {
+		return nil, envelope.NewError(envelope.InputError, "Pass conversation UUIDs, a view or filters", nil)
+	}
+
+	lists := getConversationLists(user)
+	if len(lists) == 0 {
+		return nil, envelope.NewError(envelope.PermissionError, "Permission denied", nil)
+	}
+
+	const pageSize = 100
+	uuids := []string{}
+	for page := 1; ; page++ {
+		conversations, err := app.conversation.GetConversations(user.ID, user.Teams.IDs(), lists, "", "", filters, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(conversations) > 0 && conversations[0].Total > cmodels.MaxBulkConversations {
+			return nil, envelope.NewError(envelope.InputError,
+				fmt.Sprintf("Too many conversations match, bulk actions are limited to %d conversations", cmodels.MaxBulkConversations), nil)
+		}
+		for _, c := range conversations {
+			uuids = append(uuids, c.UUID)
+		}
+		if len(conversations) < pageSize {
+			break
+		}
+	}
+	return uuids, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"strconv"
 
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/zerodha/fastglue"
 )
 
@@ -96,6 +97,13 @@ func handleUpdateCSATResponse(r *fastglue.Request) error {
 		})
 	}
 
+	// Send the response to webhooks.
+	if csat, err := app.csat.Get(uuid); err == nil {
+		app.webhook.TriggerConversationEvent(wmodels.EventCSATReceived, csat.ConversationID, "", map[string]any{
+			"csat": map[string]any{"rating": ratingI, "feedback": feedback},
+		})
+	}
+
 	return app.tmpl.RenderWebPage(r.RequestCtx, "info", map[string]interface{}{
 		"Data": map[string]interface{}{
 			"Title":   "Thank you!",
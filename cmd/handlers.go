@@ -51,6 +51,25 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/views/{id}/conversations", perm(handleGetViewConversations, "conversations:read"))
 	g.GET("/api/v1/conversations/{uuid}", perm(handleGetConversation, "conversations:read"))
 	g.GET("/api/v1/conversations/{uuid}/participants", perm(handleGetConversationParticipants, "conversations:read"))
+	g.GET("/api/v1/conversations/{uuid}/followers", perm(handleGetConversationFollowers, "conversations:read"))
+	g.POST("/api/v1/conversations/{uuid}/follow", perm(handleFollowConversation, "conversations:read"))
+	g.DELETE("/api/v1/conversations/{uuid}/follow", perm(handleUnfollowConversation, "conversations:read"))
+	g.POST("/api/v1/conversations/bulk", perm(handleBulkConversationAction, "conversations:read"))
+	g.GET("/api/v1/conversations/bulk/{id}", perm(handleGetBulkConversationAction, "conversations:read"))
+	g.GET("/api/v1/conversations/{uuid}/transcript", perm(handleGetConversationTranscript, "conversations:read"))
+	g.POST("/api/v1/conversations/{uuid}/transcript/send", perm(handleSendConversationTranscript, "messages:write"))
+	g.GET("/api/v1/conversations/{uuid}/time-entries", perm(handleGetTimeEntries, "conversations:read"))
+	g.POST("/api/v1/conversations/{uuid}/time-entries", perm(handleCreateTimeEntry, "messages:write"))
+	g.PUT("/api/v1/conversations/{uuid}/time-entries/{id}", perm(handleUpdateTimeEntry, "messages:write"))
+	g.DELETE("/api/v1/conversations/{uuid}/time-entries/{id}", perm(handleDeleteTimeEntry, "messages:write"))
+	g.GET("/api/v1/conversations/{uuid}/tasks", perm(handleGetTasks, "conversations:read"))
+	g.POST("/api/v1/conversations/{uuid}/tasks", perm(handleCreateTask, "messages:write"))
+	g.PUT("/api/v1/conversations/{uuid}/tasks/{id}", perm(handleUpdateTask, "messages:write"))
+	g.PUT("/api/v1/conversations/{uuid}/tasks/{id}/done", perm(handleUpdateTaskDone, "messages:write"))
+	g.DELETE("/api/v1/conversations/{uuid}/tasks/{id}", perm(handleDeleteTask, "messages:write"))
+	g.POST("/api/v1/conversations/{uuid}/timer/start", perm(handleStartTimer, "messages:write"))
+	g.GET("/api/v1/mentions", perm(handleGetMentions, "conversations:read"))
+	g.GET("/api/v1/conversations/{uuid}/automation-executions", perm(handleGetConversationAutomationExecutions, "conversations:read"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/user", perm(handleUpdateUserAssignee, "conversations:update_user_assignee"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/team", perm(handleUpdateTeamAssignee, "conversations:update_team_assignee"))
 	g.PUT("/api/v1/conversations/{uuid}/assignee/user/remove", perm(handleRemoveUserAssignee, "conversations:update_user_assignee"))
@@ -63,6 +82,16 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/conversations/{uuid}/messages", perm(handleGetMessages, "messages:read"))
 	g.POST("/api/v1/conversations/{cuuid}/messages", perm(handleSendMessage, "messages:write"))
 	g.PUT("/api/v1/conversations/{cuuid}/messages/{uuid}/retry", perm(handleRetryMessage, "messages:write"))
+	g.PUT("/api/v1/conversations/{cuuid}/messages/{uuid}", perm(handleUpdateScheduledMessage, "messages:write"))
+	g.POST("/api/v1/conversations/{cuuid}/messages/{uuid}/cancel", perm(handleCancelScheduledMessage, "messages:write"))
+	g.POST("/api/v1/conversations/{cuuid}/forward", perm(handleForwardMessages, "messages:write"))
+	g.GET("/api/v1/conversations/{cuuid}/side-threads", perm(handleGetSideThreads, "messages:read"))
+	g.POST("/api/v1/conversations/{cuuid}/side-threads", perm(handleCreateSideThread, "messages:write"))
+	g.GET("/api/v1/conversations/{cuuid}/side-threads/{id}", perm(handleGetSideThread, "messages:read"))
+	g.POST("/api/v1/conversations/{cuuid}/side-threads/{id}/messages", perm(handleReplyToSideThread, "messages:write"))
+	g.GET("/api/v1/conversations/{cuuid}/draft", perm(handleGetDraft, "messages:write"))
+	g.PUT("/api/v1/conversations/{cuuid}/draft", perm(handleSaveDraft, "messages:write"))
+	g.DELETE("/api/v1/conversations/{cuuid}/draft", perm(handleDeleteDraft, "messages:write"))
 	g.POST("/api/v1/conversations", perm(handleCreateConversation, "conversations:write"))
 
 	// Search.
@@ -82,6 +111,10 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.PUT("/api/v1/statuses/{id}", perm(handleUpdateStatus, "status:manage"))
 	g.DELETE("/api/v1/statuses/{id}", perm(handleDeleteStatus, "status:manage"))
 	g.GET("/api/v1/priorities", auth(handleGetPriorities))
+	g.POST("/api/v1/priorities", perm(handleCreatePriority, "priority:manage"))
+	g.PUT("/api/v1/priorities/order", perm(handleReorderPriorities, "priority:manage"))
+	g.PUT("/api/v1/priorities/{id}", perm(handleUpdatePriority, "priority:manage"))
+	g.DELETE("/api/v1/priorities/{id}", perm(handleDeletePriority, "priority:manage"))
 
 	// Tag.
 	g.GET("/api/v1/tags", auth(handleGetTags))
@@ -103,6 +136,9 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/users/me/teams", auth(handleGetCurrentUserTeams))
 	g.PUT("/api/v1/users/me/availability", auth(handleUpdateUserAvailability))
 	g.DELETE("/api/v1/users/me/avatar", auth(handleDeleteAvatar))
+	g.GET("/api/v1/users/me/timer", auth(handleGetTimer))
+	g.POST("/api/v1/users/me/timer/stop", auth(handleStopTimer))
+	g.GET("/api/v1/users/me/tasks", auth(handleGetMyTasks))
 	g.GET("/api/v1/users/compact", auth(handleGetUsersCompact))
 	g.GET("/api/v1/users", perm(handleGetUsers, "users:manage"))
 	g.GET("/api/v1/users/{id}", perm(handleGetUser, "users:manage"))
@@ -127,11 +163,15 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.GET("/api/v1/automation/rules", perm(handleGetAutomationRules, "automations:manage"))
 	g.GET("/api/v1/automation/rules/{id}", perm(handleGetAutomationRule, "automations:manage"))
 	g.POST("/api/v1/automation/rules", perm(handleCreateAutomationRule, "automations:manage"))
+	g.POST("/api/v1/automation/rules/dry-run", perm(handleDryRunAutomationRule, "automations:manage"))
+	g.GET("/api/v1/automation/rules/{id}/executions", perm(handleGetAutomationRuleExecutions, "automations:manage"))
 	g.PUT("/api/v1/automation/rules/{id}/toggle", perm(handleToggleAutomationRule, "automations:manage"))
 	g.PUT("/api/v1/automation/rules/{id}", perm(handleUpdateAutomationRule, "automations:manage"))
 	g.PUT("/api/v1/automation/rules/weights", perm(handleUpdateAutomationRuleWeights, "automations:manage"))
 	g.PUT("/api/v1/automation/rules/execution-mode", perm(handleUpdateAutomationRuleExecutionMode, "automations:manage"))
 	g.DELETE("/api/v1/automation/rules/{id}", perm(handleDeleteAutomationRule, "automations:manage"))
+	g.GET("/api/v1/automation/webhook-deliveries", perm(handleGetAutomationWebhookDeliveries, "automations:manage"))
+	g.PUT("/api/v1/automation/webhook-deliveries/{delivery_id}/retry", perm(handleRetryAutomationWebhookDelivery, "automations:manage"))
 
 	// Inbox.
 	g.GET("/api/v1/inboxes", auth(handleGetInboxes))
@@ -151,6 +191,7 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	// Dashboard.
 	g.GET("/api/v1/reports/overview/counts", perm(handleDashboardCounts, "reports:manage"))
 	g.GET("/api/v1/reports/overview/charts", perm(handleDashboardCharts, "reports:manage"))
+	g.GET("/api/v1/reports/time-tracking", perm(handleGetTimeTrackingReport, "reports:manage"))
 
 	// Template.
 	g.GET("/api/v1/templates", perm(handleGetTemplates, "templates:manage"))
@@ -173,6 +214,25 @@ func initHandlers(g *fastglue.Fastglue, hub *ws.Hub) {
 	g.PUT("/api/v1/sla/{id}", perm(fastglue.ReqLenRangeParams(handleUpdateSLA, slaReqFields), "sla:manage"))
 	g.DELETE("/api/v1/sla/{id}", perm(handleDeleteSLA, "sla:manage"))
 
+	// Data retention.
+	g.GET("/api/v1/retention-policies", perm(handleGetRetentionPolicies, "retention:manage"))
+	g.GET("/api/v1/retention-policies/logs", perm(handleGetRetentionLogs, "retention:manage"))
+	g.GET("/api/v1/retention-policies/{id}", perm(handleGetRetentionPolicy, "retention:manage"))
+	g.POST("/api/v1/retention-policies", perm(handleCreateRetentionPolicy, "retention:manage"))
+	g.PUT("/api/v1/retention-policies/{id}", perm(handleUpdateRetentionPolicy, "retention:manage"))
+	g.DELETE("/api/v1/retention-policies/{id}", perm(handleDeleteRetentionPolicy, "retention:manage"))
+
+	// Webhooks.
+	g.GET("/api/v1/webhooks", perm(handleGetWebhooks, "webhooks:manage"))
+	g.GET("/api/v1/webhooks/events", perm(handleGetWebhookEvents, "webhooks:manage"))
+	g.GET("/api/v1/webhooks/{id}", perm(handleGetWebhook, "webhooks:manage"))
+	g.POST("/api/v1/webhooks", perm(handleCreateWebhook, "webhooks:manage"))
+	g.PUT("/api/v1/webhooks/{id}", perm(handleUpdateWebhook, "webhooks:manage"))
+	g.DELETE("/api/v1/webhooks/{id}", perm(handleDeleteWebhook, "webhooks:manage"))
+	g.POST("/api/v1/webhooks/{id}/test", perm(handleTestWebhook, "webhooks:manage"))
+	g.GET("/api/v1/webhooks/{id}/deliveries", perm(handleGetWebhookDeliveries, "webhooks:manage"))
+	g.PUT("/api/v1/webhooks/{id}/deliveries/{delivery_id}/retry", perm(handleRetryWebhookDelivery, "webhooks:manage"))
+
 	// AI completion.
 	g.GET("/api/v1/ai/prompts", auth(handleGetAIPrompts))
 	g.POST("/api/v1/ai/completion", auth(handleAICompletion))
@@ -33,6 +33,7 @@ import (
 	notifier "github.com/abhinavxd/libredesk/internal/notification"
 	emailnotifier "github.com/abhinavxd/libredesk/internal/notification/providers/email"
 	"github.com/abhinavxd/libredesk/internal/oidc"
+	"github.com/abhinavxd/libredesk/internal/retention"
 	"github.com/abhinavxd/libredesk/internal/role"
 	"github.com/abhinavxd/libredesk/internal/search"
 	"github.com/abhinavxd/libredesk/internal/setting"
@@ -40,8 +41,10 @@ import (
 	"github.com/abhinavxd/libredesk/internal/tag"
 	"github.com/abhinavxd/libredesk/internal/team"
 	tmpl "github.com/abhinavxd/libredesk/internal/template"
+	"github.com/abhinavxd/libredesk/internal/timetrack"
 	"github.com/abhinavxd/libredesk/internal/user"
 	"github.com/abhinavxd/libredesk/internal/view"
+	"github.com/abhinavxd/libredesk/internal/webhook"
 	"github.com/abhinavxd/libredesk/internal/ws"
 	"github.com/jmoiron/sqlx"
 	"github.com/knadh/go-i18n"
@@ -68,6 +71,7 @@ type constants struct {
 	UploadProvider              string
 	AllowedUploadFileExtensions []string
 	MaxFileUploadSizeMB         int
+	ReplyCollisionMode          string
 }
 
 // Config loads config files into koanf.
@@ -122,6 +126,7 @@ func initConstants() *constants {
 		UploadProvider:              ko.MustString("upload.provider"),
 		AllowedUploadFileExtensions: ko.Strings("app.allowed_file_upload_extensions"),
 		MaxFileUploadSizeMB:         ko.Int("app.max_file_upload_size"),
+		ReplyCollisionMode:          ko.String("message.reply_collision"),
 	}
 }
 
@@ -223,6 +228,8 @@ func initConversations(
 		Lo:                       initLogger("conversation_manager"),
 		OutgoingMessageQueueSize: ko.MustInt("message.outgoing_queue_size"),
 		IncomingMessageQueueSize: ko.MustInt("message.incoming_queue_size"),
+		MentionAccessDuration:    ko.Duration("conversation.mention_access_duration"),
+		UndoSendWindow:           ko.Duration("message.undo_send_window"),
 	})
 	if err != nil {
 		log.Fatalf("error initializing conversation manager: %v", err)
@@ -459,6 +466,50 @@ func initMedia(db *sqlx.DB) *media.Manager {
 	return media
 }
 
+// initRetention inits the data retention manager.
+func initRetention(db *sqlx.DB, mediaStore *media.Manager) *retention.Manager {
+	mgr, err := retention.New(mediaStore, retention.Opts{
+		DB:            db,
+		Lo:            initLogger("retention"),
+		BatchSize:     ko.Int("retention.batch_size"),
+		LegalHoldTags: ko.Strings("retention.legal_hold_tags"),
+	})
+	if err != nil {
+		log.Fatalf("error initializing retention manager: %v", err)
+	}
+	return mgr
+}
+
+// initTimeTrack inits the conversation time tracking manager.
+func initTimeTrack(db *sqlx.DB) *timetrack.Manager {
+	mgr, err := timetrack.New(timetrack.Opts{
+		DB:             db,
+		Lo:             initLogger("timetrack"),
+		AutoCaptureMin: ko.Duration("time_tracking.auto_capture_min"),
+		AutoCaptureMax: ko.Duration("time_tracking.auto_capture_max"),
+	})
+	if err != nil {
+		log.Fatalf("error initializing time tracking manager: %v", err)
+	}
+	return mgr
+}
+
+// initWebhook inits the outgoing webhook manager.
+func initWebhook(db *sqlx.DB) *webhook.Manager {
+	mgr, err := webhook.New(webhook.Opts{
+		DB:          db,
+		Lo:          initLogger("webhook"),
+		MaxAttempts: ko.Int("webhook.max_attempts"),
+		Timeout:     ko.Duration("webhook.timeout"),
+		Backoff:     ko.Duration("webhook.backoff"),
+		Retention:   ko.Duration("webhook.delivery_retention"),
+	})
+	if err != nil {
+		log.Fatalf("error initializing webhook manager: %v", err)
+	}
+	return mgr
+}
+
 // initInbox initializes the inbox manager without registering inboxes.
 func initInbox(db *sqlx.DB) *inbox.Manager {
 	var lo = initLogger("inbox-manager")
@@ -473,8 +524,13 @@ func initInbox(db *sqlx.DB) *inbox.Manager {
 func initAutomationEngine(db *sqlx.DB) *automation.Engine {
 	var lo = initLogger("automation_engine")
 	engine, err := automation.New(automation.Opts{
-		DB: db,
-		Lo: lo,
+		DB:                        db,
+		Lo:                        lo,
+		ExecutionLogRetention:     ko.Duration("automation.execution_log_retention"),
+		MaxRuleDepth:              ko.Int("automation.max_rule_depth"),
+		MaxRuleExecutions:         ko.Int("automation.max_rule_executions"),
+		MaxConversationExecutions: ko.Int("automation.max_conversation_executions"),
+		ExecutionLimitWindow:      ko.Duration("automation.execution_limit_window"),
 	})
 	if err != nil {
 		log.Fatalf("error initializing automation engine: %v", err)
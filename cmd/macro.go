@@ -186,7 +186,7 @@ func handleApplyMacro(r *fastglue.Request) error {
 			app.lo.Warn("action not allowed in macro", "action", act.Type, "user_id", user.ID)
 			return r.SendErrorEnvelope(fasthttp.StatusForbidden, "Action not allowed in macro", nil, envelope.PermissionError)
 		}
-		if !hasActionPermission(act.Type, user.Permissions) {
+		if !hasActionPermission(act, user.Permissions) {
 			app.lo.Warn("no permission to execute macro action", "action", act.Type, "user_id", user.ID)
 			return r.SendErrorEnvelope(fasthttp.StatusForbidden, "No permission to execute this macro", nil, envelope.PermissionError)
 		}
@@ -219,8 +219,8 @@ func handleApplyMacro(r *fastglue.Request) error {
 }
 
 // hasActionPermission checks user permission for given action
-func hasActionPermission(action string, userPerms []string) bool {
-	requiredPerm, exists := autoModels.ActionPermissions[action]
+func hasActionPermission(action autoModels.RuleAction, userPerms []string) bool {
+	requiredPerm, exists := autoModels.ActionPermission(action)
 	if !exists {
 		return false
 	}
@@ -229,6 +229,14 @@ func hasActionPermission(action string, userPerms []string) bool {
 
 // setDisplayValues sets display values for actions.
 func setDisplayValues(app *App, actions []autoModels.RuleAction) error {
+	agentName := func(id int) (string, error) {
+		u, err := app.user.GetAgent(id)
+		if err != nil {
+			app.lo.Warn("user not found for macro action", "user_id", id)
+			return "", err
+		}
+		return u.FullName(), nil
+	}
 	getters := map[string]func(int) (string, error){
 		autoModels.ActionAssignTeam: func(id int) (string, error) {
 			t, err := app.team.Get(id)
@@ -238,14 +246,8 @@ func setDisplayValues(app *App, actions []autoModels.RuleAction) error {
 			}
 			return t.Name, nil
 		},
-		autoModels.ActionAssignUser: func(id int) (string, error) {
-			u, err := app.user.GetAgent(id)
-			if err != nil {
-				app.lo.Warn("user not found for macro action", "user_id", id)
-				return "", err
-			}
-			return u.FullName(), nil
-		},
+		autoModels.ActionAssignUser:  agentName,
+		autoModels.ActionAddFollower: agentName,
 		autoModels.ActionSetPriority: func(id int) (string, error) {
 			p, err := app.priority.Get(id)
 			if err != nil {
@@ -286,7 +288,7 @@ func validateMacro(macro models.Macro) error {
 		return envelope.NewError(envelope.InputError, "Could not parse macro actions", nil)
 	}
 	for _, a := range act {
-		if len(a.Value) == 0 {
+		if len(a.Value) == 0 && a.Type != autoModels.ActionCloseConversation {
 			return envelope.NewError(envelope.InputError, fmt.Sprintf("Empty value for action: %s", a.Type), nil)
 		}
 	}
@@ -298,7 +300,10 @@ func isMacroActionAllowed(action string) bool {
 	switch action {
 	case autoModels.ActionSendPrivateNote, autoModels.ActionReply:
 		return false
-	case autoModels.ActionAssignTeam, autoModels.ActionAssignUser, autoModels.ActionSetStatus, autoModels.ActionSetPriority, autoModels.ActionSetTags:
+	case autoModels.ActionAssignTeam, autoModels.ActionAssignUser, autoModels.ActionSetStatus, autoModels.ActionSetPriority, autoModels.ActionSetTags, autoModels.ActionAddFollower:
+		return true
+	case autoModels.ActionAddTags, autoModels.ActionRemoveTags, autoModels.ActionSnoozeFor, autoModels.ActionRemoveAssignee,
+		autoModels.ActionSetCustomAttribute, autoModels.ActionSendEmailTo, autoModels.ActionCloseConversation:
 		return true
 	default:
 		return false
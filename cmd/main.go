@@ -21,9 +21,12 @@ import (
 	"github.com/abhinavxd/libredesk/internal/csat"
 	"github.com/abhinavxd/libredesk/internal/macro"
 	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/retention"
 	"github.com/abhinavxd/libredesk/internal/search"
 	"github.com/abhinavxd/libredesk/internal/sla"
+	"github.com/abhinavxd/libredesk/internal/timetrack"
 	"github.com/abhinavxd/libredesk/internal/view"
+	"github.com/abhinavxd/libredesk/internal/webhook"
 
 	"github.com/abhinavxd/libredesk/internal/automation"
 	"github.com/abhinavxd/libredesk/internal/conversation"
@@ -86,6 +89,9 @@ type App struct {
 	ai            *ai.Manager
 	search        *search.Manager
 	notifier      *notifier.Service
+	retention     *retention.Manager
+	timetrack     *timetrack.Manager
+	webhook       *webhook.Manager
 
 	// Global state that stores data on an available app update.
 	update *AppUpdate
@@ -157,11 +163,14 @@ func main() {
 	var (
 		autoAssignInterval          = ko.MustDuration("autoassigner.autoassign_interval")
 		unsnoozeInterval            = ko.MustDuration("conversation.unsnooze_interval")
+		taskReminderInterval        = ko.Duration("conversation.task_reminder_interval")
 		automationWorkers           = ko.MustInt("automation.worker_count")
 		messageOutgoingQWorkers     = ko.MustDuration("message.outgoing_queue_workers")
 		messageIncomingQWorkers     = ko.MustDuration("message.incoming_queue_workers")
 		messageOutgoingScanInterval = ko.MustDuration("message.message_outoing_scan_interval")
 		slaEvaluationInterval       = ko.MustDuration("sla.evaluation_interval")
+		retentionInterval           = ko.Duration("retention.interval")
+		webhookInterval             = ko.Duration("webhook.interval")
 		lo                          = initLogger(appName)
 		rdb                         = initRedis()
 		constants                   = initConstants()
@@ -183,18 +192,31 @@ func main() {
 		sla                         = initSLA(db, team, settings, businessHours)
 		conversation                = initConversations(i18n, sla, status, priority, wsHub, notifier, db, inbox, user, team, media, settings, csat, automation, template)
 		autoassigner                = initAutoAssigner(team, user, conversation)
+		retention                   = initRetention(db, media)
+		timetrack                   = initTimeTrack(db)
+		webhook                     = initWebhook(db)
+		authz                       = initAuthz()
 	)
 	automation.SetConversationStore(conversation)
+	automation.SetWebhookStore(webhook)
+	automation.SetNotifier(notifier)
+	webhook.SetConversationStore(conversation)
+	conversation.SetWebhookStore(webhook)
+	sla.SetWebhookStore(webhook)
+	authz.SetMentionStore(conversation)
 
 	startInboxes(ctx, inbox, conversation)
 	go automation.Run(ctx, automationWorkers)
 	go autoassigner.Run(ctx, autoAssignInterval)
 	go conversation.Run(ctx, messageIncomingQWorkers, messageOutgoingQWorkers, messageOutgoingScanInterval)
 	go conversation.RunUnsnoozer(ctx, unsnoozeInterval)
+	go conversation.RunTaskReminder(ctx, taskReminderInterval)
 	go notifier.Run(ctx)
 	go sla.Run(ctx, slaEvaluationInterval)
 	go media.DeleteUnlinkedMedia(ctx)
 	go user.MonitorAgentAvailability(ctx)
+	go retention.Run(ctx, retentionInterval)
+	go webhook.Run(ctx, webhookInterval)
 
 	var app = &App{
 		lo:            lo,
@@ -212,11 +234,14 @@ func main() {
 		priority:      priority,
 		tmpl:          template,
 		notifier:      notifier,
+		retention:     retention,
+		timetrack:     timetrack,
+		webhook:       webhook,
 		consts:        atomic.Value{},
 		conversation:  conversation,
 		automation:    automation,
 		businessHours: businessHours,
-		authz:         initAuthz(),
+		authz:         authz,
 		view:          initView(db),
 		csat:          initCSAT(db),
 		search:        initSearch(db),
@@ -226,6 +251,16 @@ func main() {
 		ai:            initAI(db),
 	}
 	app.consts.Store(constants)
+	wsHub.SetConversationAccessChecker(func(userID int, conversationUUID string) bool {
+		user, err := app.user.GetAgent(userID)
+		if err != nil {
+			return false
+		}
+		_, err = enforceConversationReadAccess(app, conversationUUID, user)
+		return err == nil
+	})
+
+	wsHub.SetConversationViewRecorder(timetrack.RecordView)
 
 	g := fastglue.NewGlue()
 	g.SetContext(app)
@@ -271,6 +306,10 @@ func main() {
 	conversation.Close()
 	colorlog.Red("Shutting down SLA...")
 	sla.Close()
+	colorlog.Red("Shutting down retention...")
+	retention.Close()
+	colorlog.Red("Shutting down webhooks...")
+	webhook.Close()
 	colorlog.Red("Shutting down database...")
 	db.Close()
 	colorlog.Red("Shutting down redis...")
@@ -174,7 +174,7 @@ func handleServeMedia(r *fastglue.Request) error {
 		if err != nil {
 			return sendErrorEnvelope(r, err)
 		}
-		allowed, err = app.authz.EnforceConversationAccess(user, conversation)
+		allowed, err = app.authz.EnforceConversationReadAccess(user, conversation)
 		if err != nil {
 			return sendErrorEnvelope(r, err)
 		}
@@ -2,21 +2,53 @@ package main
 
 import (
 	"strconv"
+	"time"
 
 	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
 	"github.com/abhinavxd/libredesk/internal/automation/models"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	medModels "github.com/abhinavxd/libredesk/internal/media/models"
+	"github.com/google/uuid"
 	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
 	"github.com/zerodha/fastglue"
 )
 
+type draftReq struct {
+	Content    string   `json:"content"`
+	CC         []string `json:"cc"`
+	BCC        []string `json:"bcc"`
+	MediaUUIDs []string `json:"media_uuids"`
+}
+
+const (
+	replyCollisionWarn  = "warn"
+	replyCollisionBlock = "block"
+)
+
 type messageReq struct {
 	Attachments []int    `json:"attachments"`
 	Message     string   `json:"message"`
 	Private     bool     `json:"private"`
 	CC          []string `json:"cc"`
 	BCC         []string `json:"bcc"`
+	// DraftStartedAt is when the agent started writing the reply, used to detect replies sent by other agents in the meantime.
+	DraftStartedAt  null.Time `json:"draft_started_at"`
+	IgnoreCollision bool      `json:"ignore_collision"`
+	// SendAt schedules the reply, replies without it are held for the undo send window.
+	SendAt null.Time `json:"send_at"`
+}
+
+type scheduledMessageReq struct {
+	Message string    `json:"message"`
+	SendAt  null.Time `json:"send_at"`
+}
+
+type forwardReq struct {
+	MessageUUIDs []string `json:"message_uuids"`
+	To           []string `json:"to"`
+	CC           []string `json:"cc"`
+	Message      string   `json:"message"`
 }
 
 // handleGetMessages returns messages for a conversation.
@@ -36,7 +68,7 @@ func handleGetMessages(r *fastglue.Request) error {
 	}
 
 	// Check permission
-	_, err = enforceConversationAccess(app, uuid, user)
+	_, err = enforceConversationReadAccess(app, uuid, user)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -76,7 +108,7 @@ func handleGetMessage(r *fastglue.Request) error {
 	}
 
 	// Check permission
-	_, err = enforceConversationAccess(app, cuuid, user)
+	_, err = enforceConversationReadAccess(app, cuuid, user)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -158,12 +190,23 @@ func handleSendMessage(r *fastglue.Request) error {
 		media = append(media, m)
 	}
 
+	if !req.Private && req.SendAt.Valid && req.SendAt.Time.Before(time.Now()) {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "`send_at` should be in the future", nil, envelope.InputError)
+	}
+
+	// Check if another agent replied while this reply was being drafted.
+	if !req.Private && req.DraftStartedAt.Valid {
+		if err := checkReplyCollision(app, cuuid, user.ID, req); err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+	}
+
 	if req.Private {
 		if err := app.conversation.SendPrivateNote(media, user.ID, cuuid, req.Message); err != nil {
 			return sendErrorEnvelope(r, err)
 		}
 	} else {
-		if err := app.conversation.SendReply(media, conv.InboxID, user.ID, cuuid, req.Message, req.CC, req.BCC, map[string]any{} /**meta**/); err != nil {
+		if err := app.conversation.SendReplyAt(media, conv.InboxID, user.ID, cuuid, req.Message, req.CC, req.BCC, map[string]any{} /**meta**/, req.SendAt.Time); err != nil {
 			return sendErrorEnvelope(r, err)
 		}
 		// Evaluate automation rules.
@@ -172,3 +215,170 @@ func handleSendMessage(r *fastglue.Request) error {
 
 	return r.SendEnvelope("Message sent successfully")
 }
+
+// handleUpdateScheduledMessage updates the content and send time of a scheduled reply that has not been sent yet.
+func handleUpdateScheduledMessage(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+		req   = scheduledMessageReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	if req.Message == "" {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Empty `message`", nil, envelope.InputError)
+	}
+	if req.SendAt.Valid && req.SendAt.Time.Before(time.Now()) {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "`send_at` should be in the future", nil, envelope.InputError)
+	}
+
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.conversation.UpdateScheduledMessage(cuuid, uuid, user.ID, req.Message, req.SendAt.Time); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleCancelScheduledMessage cancels a scheduled reply that has not been sent yet, the reply is restored as the agent's draft.
+func handleCancelScheduledMessage(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+	)
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	draft, err := app.conversation.CancelScheduledMessage(cuuid, uuid, user.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(draft)
+}
+
+// handleForwardMessages forwards messages of a conversation with their attachments to external addresses.
+func handleForwardMessages(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+		req   = forwardReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.conversation.ForwardMessages(user.ID, cuuid, req.MessageUUIDs, req.To, req.CC, req.Message); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// checkReplyCollision returns a conflict error if another agent replied to the conversation after the draft was started,
+// depending on the configured collision mode.
+func checkReplyCollision(app *App, conversationUUID string, userID int, req messageReq) error {
+	mode := app.consts.Load().(*constants).ReplyCollisionMode
+	if mode != replyCollisionWarn && mode != replyCollisionBlock {
+		return nil
+	}
+	if mode == replyCollisionWarn && req.IgnoreCollision {
+		return nil
+	}
+	replied, err := app.conversation.HasAgentReplySince(conversationUUID, userID, req.DraftStartedAt.Time)
+	if err != nil {
+		return err
+	}
+	if !replied {
+		return nil
+	}
+	if mode == replyCollisionBlock {
+		return envelope.NewError(envelope.ConflictError, "Another agent replied to this conversation after you started your reply", nil)
+	}
+	return envelope.NewError(envelope.ConflictError, "Another agent replied to this conversation after you started your reply, send again to confirm", nil)
+}
+
+// handleGetDraft returns the reply draft of the current user in a conversation.
+func handleGetDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+	)
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	draft, err := app.conversation.GetDraft(cuuid, user.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(draft)
+}
+
+// handleSaveDraft creates or replaces the reply draft of the current user in a conversation.
+func handleSaveDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+		req   = draftReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	for _, id := range req.MediaUUIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid media `uuid`", nil, envelope.InputError)
+		}
+	}
+
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	draft, err := app.conversation.SaveDraft(cuuid, user.ID, req.Content, req.CC, req.BCC, req.MediaUUIDs)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(draft)
+}
+
+// handleDeleteDraft deletes the reply draft of the current user in a conversation.
+func handleDeleteDraft(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+	)
+	if err := app.conversation.DeleteDraft(cuuid, auser.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
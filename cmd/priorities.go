@@ -1,9 +1,24 @@
 package main
 
 import (
+	"strconv"
+
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
 	"github.com/zerodha/fastglue"
 )
 
+type priorityReq struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	SLAPolicyID int    `json:"sla_policy_id"`
+}
+
+type priorityOrderReq struct {
+	IDs []int `json:"ids"`
+}
+
 // handleGetPriorities returns all priorities.
 func handleGetPriorities(r *fastglue.Request) error {
 	var app = r.Context.(*App)
@@ -13,3 +28,96 @@ func handleGetPriorities(r *fastglue.Request) error {
 	}
 	return r.SendEnvelope(out)
 }
+
+// handleCreatePriority creates a new priority.
+func handleCreatePriority(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = priorityReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	priority, err := app.priority.Create(req.Name, req.Color, req.SLAPolicyID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(priority)
+}
+
+// handleUpdatePriority updates a priority.
+func handleUpdatePriority(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = priorityReq{}
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid priority `id`.", nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	priority, err := app.priority.Update(id, req.Name, req.Color, req.SLAPolicyID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(priority)
+}
+
+// handleDeletePriority deletes a priority, conversations with the priority are moved to the `replace_with` priority if passed.
+func handleDeletePriority(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid priority `id`.", nil, envelope.InputError)
+	}
+	var replaceWithID int
+	if v := string(r.RequestCtx.QueryArgs().Peek("replace_with")); v != "" {
+		if replaceWithID, err = strconv.Atoi(v); err != nil {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid `replace_with` priority.", nil, envelope.InputError)
+		}
+	}
+	uuids, err := app.priority.Delete(id, replaceWithID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+
+	// Record the move on the conversations and let the agents viewing them know.
+	if len(uuids) > 0 {
+		replacement, err := app.priority.Get(replaceWithID)
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		user, err := app.user.GetAgent(auser.ID)
+		if err != nil {
+			return sendErrorEnvelope(r, err)
+		}
+		for _, uuid := range uuids {
+			if err := app.conversation.RecordPriorityChange(replacement.Name, uuid, user); err != nil {
+				app.lo.Error("error recording priority change", "conversation_uuid", uuid, "error", err)
+			}
+			app.conversation.BroadcastConversationUpdate(uuid, "priority", replacement.Name)
+			app.conversation.BroadcastConversationUpdate(uuid, "priority_id", replacement.ID)
+		}
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleReorderPriorities ranks priorities by their position in the passed IDs, least urgent first.
+func handleReorderPriorities(r *fastglue.Request) error {
+	var (
+		app = r.Context.(*App)
+		req = priorityOrderReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	if err := app.priority.Reorder(req.IDs); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
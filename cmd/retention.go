@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	rmodels "github.com/abhinavxd/libredesk/internal/retention/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+// handleGetRetentionPolicies returns all retention policies.
+func handleGetRetentionPolicies(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	out, err := app.retention.GetAll()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleGetRetentionPolicy returns a retention policy.
+func handleGetRetentionPolicy(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid retention policy `id`.", nil, envelope.InputError)
+	}
+	out, err := app.retention.Get(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleCreateRetentionPolicy creates a new retention policy.
+func handleCreateRetentionPolicy(r *fastglue.Request) error {
+	var (
+		app    = r.Context.(*App)
+		policy = rmodels.Policy{}
+	)
+	if err := r.Decode(&policy, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	if err := validateRetentionPolicy(&policy); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	out, err := app.retention.Create(policy)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleUpdateRetentionPolicy updates a retention policy.
+func handleUpdateRetentionPolicy(r *fastglue.Request) error {
+	var (
+		app    = r.Context.(*App)
+		policy = rmodels.Policy{}
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid retention policy `id`.", nil, envelope.InputError)
+	}
+	if err := r.Decode(&policy, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	if err := validateRetentionPolicy(&policy); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	out, err := app.retention.Update(id, policy)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleDeleteRetentionPolicy deletes a retention policy.
+func handleDeleteRetentionPolicy(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid retention policy `id`.", nil, envelope.InputError)
+	}
+	if err := app.retention.Delete(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleGetRetentionLogs returns the removals performed by retention policies.
+func handleGetRetentionLogs(r *fastglue.Request) error {
+	var (
+		app         = r.Context.(*App)
+		page, _     = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page")))
+		pageSize, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page_size")))
+		total       = 0
+	)
+	logs, pageSize, err := app.retention.GetLogs(page, pageSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if len(logs) > 0 {
+		total = logs[0].Total
+	}
+	if page < 1 {
+		page = 1
+	}
+	return r.SendEnvelope(envelope.PageResults{
+		Total:      total,
+		Results:    logs,
+		Page:       page,
+		PerPage:    pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	})
+}
+
+// validateRetentionPolicy validates an incoming retention policy.
+func validateRetentionPolicy(p *rmodels.Policy) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return envelope.NewError(envelope.InputError, "Empty retention policy `name`", nil)
+	}
+	if p.Action != rmodels.ActionDeleteConversations && p.Action != rmodels.ActionStripAttachments {
+		return envelope.NewError(envelope.InputError, "Invalid retention policy `action`", nil)
+	}
+	if p.Days < 1 {
+		return envelope.NewError(envelope.InputError, "Retention policy `days` should be greater than zero", nil)
+	}
+	return nil
+}
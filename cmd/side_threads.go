@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strconv"
+
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	medModels "github.com/abhinavxd/libredesk/internal/media/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+type sideThreadReq struct {
+	Subject     string   `json:"subject"`
+	To          []string `json:"to"`
+	CC          []string `json:"cc"`
+	Message     string   `json:"message"`
+	Attachments []int    `json:"attachments"`
+}
+
+// handleGetSideThreads returns the side threads of a conversation with their messages.
+func handleGetSideThreads(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+	)
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	threads, err := app.conversation.GetSideThreads(cuuid)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(threads)
+}
+
+// handleGetSideThread returns a side thread of a conversation with its messages.
+func handleGetSideThread(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid side thread `id`.", nil, envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	thread, err := app.conversation.GetSideThread(cuuid, id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(thread)
+}
+
+// handleCreateSideThread starts a side thread with external parties from within a conversation.
+func handleCreateSideThread(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+		req   = sideThreadReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	media, err := getSideThreadMedia(app, req.Attachments)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	thread, err := app.conversation.CreateSideThread(media, user.ID, cuuid, req.Subject, req.To, req.CC, req.Message)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(thread)
+}
+
+// handleReplyToSideThread sends a message in a side thread.
+func handleReplyToSideThread(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		cuuid = r.RequestCtx.UserValue("cuuid").(string)
+		req   = sideThreadReq{}
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid side thread `id`.", nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, cuuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	media, err := getSideThreadMedia(app, req.Attachments)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.conversation.ReplyToSideThread(media, user.ID, cuuid, id, req.Message); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// getSideThreadMedia fetches the uploaded media attached to a side thread message.
+func getSideThreadMedia(app *App, ids []int) ([]medModels.Media, error) {
+	var media = make([]medModels.Media, 0, len(ids))
+	for _, id := range ids {
+		m, err := app.media.Get(id, "")
+		if err != nil {
+			app.lo.Error("error fetching media", "error", err)
+			return nil, envelope.NewError(envelope.GeneralError, "Error fetching media", nil)
+		}
+		media = append(media, m)
+	}
+	return media, nil
+}
@@ -33,7 +33,7 @@ func handleCreateStatus(r *fastglue.Request) error {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Empty status `Name`", nil, envelope.InputError)
 	}
 
-	err := app.status.Create(status.Name)
+	err := app.status.Create(status.Name, status.Category)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
@@ -82,7 +82,7 @@ func handleUpdateStatus(r *fastglue.Request) error {
 		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Empty status `Name`", nil, envelope.InputError)
 	}
 
-	err = app.status.Update(id, status.Name)
+	err = app.status.Update(id, status.Name, status.Category)
 	if err != nil {
 		return sendErrorEnvelope(r, err)
 	}
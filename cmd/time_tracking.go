@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	amodels "github.com/abhinavxd/libredesk/internal/auth/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/valyala/fasthttp"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/fastglue"
+)
+
+type timeEntryReq struct {
+	// Duration is in seconds.
+	Duration  int       `json:"duration"`
+	Note      string    `json:"note"`
+	Billable  bool      `json:"billable"`
+	StartedAt null.Time `json:"started_at"`
+}
+
+// handleGetTimeEntries returns the time entries of a conversation.
+func handleGetTimeEntries(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+	)
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	entries, err := app.timetrack.GetEntries(uuid)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(entries)
+}
+
+// handleCreateTimeEntry adds time spent by the current agent to a conversation.
+func handleCreateTimeEntry(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		req   = timeEntryReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	if req.Duration <= 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Empty `duration`", nil, envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	entry, err := app.timetrack.Create(uuid, user.ID, time.Duration(req.Duration)*time.Second, req.Note, req.Billable, req.StartedAt.Time)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(entry)
+}
+
+// handleUpdateTimeEntry updates a time entry of the current agent.
+func handleUpdateTimeEntry(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		req   = timeEntryReq{}
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid time entry `id`.", nil, envelope.InputError)
+	}
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	entry, err := app.timetrack.Update(id, uuid, user.ID, time.Duration(req.Duration)*time.Second, req.Note, req.Billable)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(entry)
+}
+
+// handleDeleteTimeEntry deletes a time entry of the current agent.
+func handleDeleteTimeEntry(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid time entry `id`.", nil, envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if err := app.timetrack.Delete(id, uuid, user.ID); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleStartTimer starts a timer for the current agent on a conversation, stopping any running timer.
+func handleStartTimer(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+		uuid  = r.RequestCtx.UserValue("uuid").(string)
+		req   = timeEntryReq{}
+	)
+	if err := r.Decode(&req, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	user, err := app.user.GetAgent(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if _, err := enforceConversationAccess(app, uuid, user); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	entry, err := app.timetrack.StartTimer(uuid, user.ID, req.Note, req.Billable)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(entry)
+}
+
+// handleStopTimer stops the running timer of the current agent.
+func handleStopTimer(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	entry, err := app.timetrack.StopTimer(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(entry)
+}
+
+// handleGetTimer returns the running timer of the current agent, null if no timer is running.
+func handleGetTimer(r *fastglue.Request) error {
+	var (
+		app   = r.Context.(*App)
+		auser = r.RequestCtx.UserValue("user").(amodels.User)
+	)
+	entry, err := app.timetrack.GetRunningTimer(auser.ID)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(entry)
+}
+
+// handleGetTimeTrackingReport returns the tracked time grouped by agent, team, contact organization or tag as JSON or CSV.
+// The report defaults to the last 30 days.
+func handleGetTimeTrackingReport(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		groupBy = string(r.RequestCtx.QueryArgs().Peek("group_by"))
+		format  = string(r.RequestCtx.QueryArgs().Peek("format"))
+		to      = time.Now()
+		from    = to.AddDate(0, 0, -30)
+	)
+	if v := string(r.RequestCtx.QueryArgs().Peek("from")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid `from` time", nil, envelope.InputError)
+		}
+		from = t
+	}
+	if v := string(r.RequestCtx.QueryArgs().Peek("to")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid `to` time", nil, envelope.InputError)
+		}
+		to = t
+	}
+
+	rows, err := app.timetrack.GetReport(groupBy, from, to)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if format != "csv" {
+		return r.SendEnvelope(rows)
+	}
+
+	var (
+		buf bytes.Buffer
+		w   = csv.NewWriter(&buf)
+	)
+	w.Write([]string{groupBy, "entries", "total_seconds", "billable_seconds", "total_hours", "billable_hours"})
+	for _, row := range rows {
+		w.Write([]string{
+			row.Label,
+			strconv.Itoa(row.Entries),
+			strconv.Itoa(row.TotalDuration),
+			strconv.Itoa(row.BillableDuration),
+			strconv.FormatFloat(float64(row.TotalDuration)/3600, 'f', 2, 64),
+			strconv.FormatFloat(float64(row.BillableDuration)/3600, 'f', 2, 64),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		app.lo.Error("error writing time tracking report CSV", "error", err)
+		return r.SendErrorEnvelope(fasthttp.StatusInternalServerError, "Error exporting report", nil, envelope.GeneralError)
+	}
+
+	fileName := fmt.Sprintf("time-tracking-%s-%s-%s.csv", groupBy, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	r.RequestCtx.Response.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	r.RequestCtx.Response.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+	r.RequestCtx.SetBody(buf.Bytes())
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
+	"github.com/valyala/fasthttp"
+	"github.com/zerodha/fastglue"
+)
+
+// handleGetWebhooks returns all webhooks.
+func handleGetWebhooks(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	out, err := app.webhook.GetAll()
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleGetWebhookEvents returns the events webhooks can subscribe to.
+func handleGetWebhookEvents(r *fastglue.Request) error {
+	return r.SendEnvelope(wmodels.Events)
+}
+
+// handleGetWebhook returns a webhook.
+func handleGetWebhook(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid webhook `id`.", nil, envelope.InputError)
+	}
+	out, err := app.webhook.Get(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleCreateWebhook creates a new webhook.
+func handleCreateWebhook(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		webhook = wmodels.Webhook{}
+	)
+	if err := r.Decode(&webhook, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	if err := validateWebhook(&webhook); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	out, err := app.webhook.Create(webhook)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleUpdateWebhook updates a webhook.
+func handleUpdateWebhook(r *fastglue.Request) error {
+	var (
+		app     = r.Context.(*App)
+		webhook = wmodels.Webhook{}
+	)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid webhook `id`.", nil, envelope.InputError)
+	}
+	if err := r.Decode(&webhook, "json"); err != nil {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "decode failed", err.Error(), envelope.InputError)
+	}
+	if err := validateWebhook(&webhook); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	out, err := app.webhook.Update(id, webhook)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleDeleteWebhook deletes a webhook.
+func handleDeleteWebhook(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid webhook `id`.", nil, envelope.InputError)
+	}
+	if err := app.webhook.Delete(id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// handleTestWebhook sends a test event to a webhook and returns the response status.
+func handleTestWebhook(r *fastglue.Request) error {
+	var app = r.Context.(*App)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid webhook `id`.", nil, envelope.InputError)
+	}
+	out, err := app.webhook.SendTest(id)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(out)
+}
+
+// handleGetWebhookDeliveries returns the deliveries of a webhook, `status=failed` lists the deliveries that failed every attempt.
+func handleGetWebhookDeliveries(r *fastglue.Request) error {
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid webhook `id`.", nil, envelope.InputError)
+	}
+	return sendWebhookDeliveries(r, id)
+}
+
+// handleGetAutomationWebhookDeliveries returns the deliveries of the send webhook automation action.
+func handleGetAutomationWebhookDeliveries(r *fastglue.Request) error {
+	return sendWebhookDeliveries(r, 0)
+}
+
+// handleRetryWebhookDelivery queues a failed delivery of a webhook again.
+func handleRetryWebhookDelivery(r *fastglue.Request) error {
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid webhook `id`.", nil, envelope.InputError)
+	}
+	return retryWebhookDelivery(r, id)
+}
+
+// handleRetryAutomationWebhookDelivery queues a failed delivery of the send webhook automation action again.
+func handleRetryAutomationWebhookDelivery(r *fastglue.Request) error {
+	return retryWebhookDelivery(r, 0)
+}
+
+// retryWebhookDelivery queues a failed delivery of a webhook again, webhook ID 0 is for the send webhook automation action.
+func retryWebhookDelivery(r *fastglue.Request, webhookID int) error {
+	var app = r.Context.(*App)
+	id, err := strconv.Atoi(r.RequestCtx.UserValue("delivery_id").(string))
+	if err != nil || id == 0 {
+		return r.SendErrorEnvelope(fasthttp.StatusBadRequest, "Invalid webhook delivery `id`.", nil, envelope.InputError)
+	}
+	if err := app.webhook.RetryDelivery(webhookID, id); err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	return r.SendEnvelope(true)
+}
+
+// sendWebhookDeliveries sends a page of deliveries of a webhook, webhook ID 0 is for the send webhook automation action.
+func sendWebhookDeliveries(r *fastglue.Request, webhookID int) error {
+	var (
+		app         = r.Context.(*App)
+		status      = string(r.RequestCtx.QueryArgs().Peek("status"))
+		page, _     = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page")))
+		pageSize, _ = strconv.Atoi(string(r.RequestCtx.QueryArgs().Peek("page_size")))
+		total       = 0
+	)
+	deliveries, pageSize, err := app.webhook.GetDeliveries(webhookID, status, page, pageSize)
+	if err != nil {
+		return sendErrorEnvelope(r, err)
+	}
+	if len(deliveries) > 0 {
+		total = deliveries[0].Total
+	}
+	if page < 1 {
+		page = 1
+	}
+	return r.SendEnvelope(envelope.PageResults{
+		Total:      total,
+		Results:    deliveries,
+		Page:       page,
+		PerPage:    pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	})
+}
+
+// validateWebhook validates an incoming webhook.
+func validateWebhook(w *wmodels.Webhook) error {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return envelope.NewError(envelope.InputError, "Empty webhook `name`", nil)
+	}
+	w.URL = strings.TrimSpace(w.URL)
+	if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return envelope.NewError(envelope.InputError, "Invalid webhook `url`", nil)
+	}
+	if len(w.Events) == 0 {
+		return envelope.NewError(envelope.InputError, "Select at least one webhook `event`", nil)
+	}
+	for _, e := range w.Events {
+		if !slices.Contains(wmodels.Events, e) {
+			return envelope.NewError(envelope.InputError, "Invalid webhook event `"+e+"`", nil)
+		}
+	}
+	if len(w.Headers) == 0 || string(w.Headers) == "null" {
+		w.Headers = json.RawMessage("{}")
+	}
+	var headers map[string]string
+	if err := json.Unmarshal(w.Headers, &headers); err != nil {
+		return envelope.NewError(envelope.InputError, "Invalid webhook `headers`, expected an object of header names to values", nil)
+	}
+	return nil
+}
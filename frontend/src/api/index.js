@@ -42,6 +42,25 @@ const deleteUser = (id) => http.delete(`/api/v1/users/${id}`)
 const getEmailNotificationSettings = () => http.get('/api/v1/settings/notifications/email')
 const updateEmailNotificationSettings = (data) => http.put('/api/v1/settings/notifications/email', data)
 const getPriorities = () => http.get('/api/v1/priorities')
+const createPriority = (data) =>
+  http.post('/api/v1/priorities', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updatePriority = (id, data) =>
+  http.put(`/api/v1/priorities/${id}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deletePriority = (id, params) => http.delete(`/api/v1/priorities/${id}`, { params })
+const reorderPriorities = (data) =>
+  http.put('/api/v1/priorities/order', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
 const getStatuses = () => http.get('/api/v1/statuses')
 const createStatus = (data) => http.post('/api/v1/statuses', data)
 const updateStatus = (id, data) => http.put(`/api/v1/statuses/${id}`, data)
@@ -135,7 +154,37 @@ const updateAutomationRuleWeights = (data) =>
       'Content-Type': 'application/json'
     }
   })
+const dryRunAutomationRule = (data) =>
+  http.post('/api/v1/automation/rules/dry-run', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const getAutomationRuleExecutions = (id, params) =>
+  http.get(`/api/v1/automation/rules/${id}/executions`, { params })
 const updateAutomationRulesExecutionMode = (data) => http.put(`/api/v1/automation/rules/execution-mode`, data)
+const getWebhookDeliveries = (params) => http.get('/api/v1/automation/webhook-deliveries', { params })
+const retryWebhookDelivery = (id) => http.put(`/api/v1/automation/webhook-deliveries/${id}/retry`)
+const getWebhooks = () => http.get('/api/v1/webhooks')
+const getWebhookEvents = () => http.get('/api/v1/webhooks/events')
+const createWebhook = (data) =>
+  http.post('/api/v1/webhooks', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updateWebhook = (id, data) =>
+  http.put(`/api/v1/webhooks/${id}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteWebhook = (id) => http.delete(`/api/v1/webhooks/${id}`)
+const testWebhook = (id) => http.post(`/api/v1/webhooks/${id}/test`)
+const getWebhookSubscriptionDeliveries = (id, params) =>
+  http.get(`/api/v1/webhooks/${id}/deliveries`, { params })
+const retryWebhookSubscriptionDelivery = (id, deliveryId) =>
+  http.put(`/api/v1/webhooks/${id}/deliveries/${deliveryId}/retry`)
 const getRoles = () => http.get('/api/v1/roles')
 const getRole = (id) => http.get(`/api/v1/roles/${id}`)
 const createRole = (data) =>
@@ -181,6 +230,13 @@ const updateConversationPriority = (uuid, data) => http.put(`/api/v1/conversatio
 const updateAssigneeLastSeen = (uuid) => http.put(`/api/v1/conversations/${uuid}/last-seen`)
 const getConversationMessage = (cuuid, uuid) => http.get(`/api/v1/conversations/${cuuid}/messages/${uuid}`)
 const retryMessage = (cuuid, uuid) => http.put(`/api/v1/conversations/${cuuid}/messages/${uuid}/retry`)
+const updateScheduledMessage = (cuuid, uuid, data) =>
+  http.put(`/api/v1/conversations/${cuuid}/messages/${uuid}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const cancelScheduledMessage = (cuuid, uuid) => http.post(`/api/v1/conversations/${cuuid}/messages/${uuid}/cancel`)
 const getConversationMessages = (uuid, params) => http.get(`/api/v1/conversations/${uuid}/messages`, { params })
 const sendMessage = (uuid, data) =>
   http.post(`/api/v1/conversations/${uuid}/messages`, data, {
@@ -188,8 +244,98 @@ const sendMessage = (uuid, data) =>
       'Content-Type': 'application/json'
     }
   })
+const getDraft = (uuid) => http.get(`/api/v1/conversations/${uuid}/draft`)
+const saveDraft = (uuid, data) =>
+  http.put(`/api/v1/conversations/${uuid}/draft`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteDraft = (uuid) => http.delete(`/api/v1/conversations/${uuid}/draft`)
 const getConversation = (uuid) => http.get(`/api/v1/conversations/${uuid}`)
 const getConversationParticipants = (uuid) => http.get(`/api/v1/conversations/${uuid}/participants`)
+const getConversationFollowers = (uuid) => http.get(`/api/v1/conversations/${uuid}/followers`)
+const followConversation = (uuid) => http.post(`/api/v1/conversations/${uuid}/follow`)
+const unfollowConversation = (uuid) => http.delete(`/api/v1/conversations/${uuid}/follow`)
+const sendTranscript = (uuid, data) =>
+  http.post(`/api/v1/conversations/${uuid}/transcript/send`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const forwardMessages = (uuid, data) =>
+  http.post(`/api/v1/conversations/${uuid}/forward`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const getSideThreads = (uuid) => http.get(`/api/v1/conversations/${uuid}/side-threads`)
+const createSideThread = (uuid, data) =>
+  http.post(`/api/v1/conversations/${uuid}/side-threads`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const replyToSideThread = (uuid, id, data) =>
+  http.post(`/api/v1/conversations/${uuid}/side-threads/${id}/messages`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const getTimeEntries = (uuid) => http.get(`/api/v1/conversations/${uuid}/time-entries`)
+const createTimeEntry = (uuid, data) =>
+  http.post(`/api/v1/conversations/${uuid}/time-entries`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updateTimeEntry = (uuid, id, data) =>
+  http.put(`/api/v1/conversations/${uuid}/time-entries/${id}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteTimeEntry = (uuid, id) => http.delete(`/api/v1/conversations/${uuid}/time-entries/${id}`)
+const startTimer = (uuid, data) =>
+  http.post(`/api/v1/conversations/${uuid}/timer/start`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const getTimer = () => http.get('/api/v1/users/me/timer')
+const stopTimer = () => http.post('/api/v1/users/me/timer/stop')
+const getConversationAutomationExecutions = (uuid, params) =>
+  http.get(`/api/v1/conversations/${uuid}/automation-executions`, { params })
+const getTasks = (uuid) => http.get(`/api/v1/conversations/${uuid}/tasks`)
+const createTask = (uuid, data) =>
+  http.post(`/api/v1/conversations/${uuid}/tasks`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updateTask = (uuid, id, data) =>
+  http.put(`/api/v1/conversations/${uuid}/tasks/${id}`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const updateTaskDone = (uuid, id, data) =>
+  http.put(`/api/v1/conversations/${uuid}/tasks/${id}/done`, data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const deleteTask = (uuid, id) => http.delete(`/api/v1/conversations/${uuid}/tasks/${id}`)
+const getMyTasks = (params) => http.get('/api/v1/users/me/tasks', { params })
+const getTimeTrackingReport = (params) => http.get('/api/v1/reports/time-tracking', { params })
+const getMentions = (params) => http.get('/api/v1/mentions', { params })
+const bulkConversationAction = (data) =>
+  http.post('/api/v1/conversations/bulk', data, {
+    headers: {
+      'Content-Type': 'application/json'
+    }
+  })
+const getBulkConversationAction = (id) => http.get(`/api/v1/conversations/bulk/${id}`)
 const getAllMacros = () => http.get('/api/v1/macros')
 const getMacro = (id) => http.get(`/api/v1/macros/${id}`)
 const createMacro = (data) => http.post('/api/v1/macros', data, {
@@ -309,6 +455,13 @@ export default {
   getOverviewCharts,
   getOverviewCounts,
   getConversationParticipants,
+  getConversationFollowers,
+  followConversation,
+  unfollowConversation,
+  getMentions,
+  sendTranscript,
+  bulkConversationAction,
+  getBulkConversationAction,
   getConversationMessage,
   getConversationMessages,
   getCurrentUser,
@@ -335,9 +488,45 @@ export default {
   createAutomationRule,
   toggleAutomationRule,
   deleteAutomationRule,
+  getWebhookDeliveries,
+  retryWebhookDelivery,
+  dryRunAutomationRule,
+  getAutomationRuleExecutions,
+  getConversationAutomationExecutions,
+  getWebhooks,
+  getWebhookEvents,
+  createWebhook,
+  updateWebhook,
+  deleteWebhook,
+  testWebhook,
+  getWebhookSubscriptionDeliveries,
+  retryWebhookSubscriptionDelivery,
   createConversation,
   sendMessage,
+  getDraft,
+  saveDraft,
+  deleteDraft,
   retryMessage,
+  updateScheduledMessage,
+  cancelScheduledMessage,
+  forwardMessages,
+  getSideThreads,
+  createSideThread,
+  replyToSideThread,
+  getTimeEntries,
+  createTimeEntry,
+  updateTimeEntry,
+  deleteTimeEntry,
+  startTimer,
+  getTimer,
+  stopTimer,
+  getTimeTrackingReport,
+  getTasks,
+  createTask,
+  updateTask,
+  updateTaskDone,
+  deleteTask,
+  getMyTasks,
   createUser,
   createInbox,
   updateInbox,
@@ -365,6 +554,10 @@ export default {
   deleteTag,
   getStatuses,
   getPriorities,
+  createPriority,
+  updatePriority,
+  deletePriority,
+  reorderPriorities,
   createStatus,
   updateStatus,
   deleteStatus,
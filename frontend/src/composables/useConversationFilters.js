@@ -43,9 +43,139 @@ export function useConversationFilters () {
             type: FIELD_TYPE.SELECT,
             operators: FIELD_OPERATORS.SELECT,
             options: iStore.options
+        },
+        subject: {
+            label: 'Subject',
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        reference_number: {
+            label: 'Reference number',
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        created_at: {
+            label: 'Created',
+            type: FIELD_TYPE.DATE,
+            operators: FIELD_OPERATORS.DATE
+        },
+        last_message_at: {
+            label: 'Last message',
+            type: FIELD_TYPE.DATE,
+            operators: FIELD_OPERATORS.DATE
         }
     }))
 
+    // Condition fields shared by all automation rule types.
+    const conditionFields = {
+        tags: {
+            label: 'Tags',
+            type: FIELD_TYPE.TAG,
+            operators: FIELD_OPERATORS.LIST
+        },
+        custom_attribute: {
+            label: 'Custom attribute',
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT,
+            hasKey: true
+        },
+        contact_name: {
+            label: 'Contact name',
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        contact_domain: {
+            label: 'Contact email domain',
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        contact_organization: {
+            label: 'Contact organization',
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT
+        },
+        contact_custom_attribute: {
+            label: 'Contact custom attribute',
+            type: FIELD_TYPE.TEXT,
+            operators: FIELD_OPERATORS.TEXT,
+            hasKey: true
+        },
+        message_count: {
+            label: 'Number of messages',
+            type: FIELD_TYPE.NUMBER,
+            operators: FIELD_OPERATORS.NUMBER
+        },
+        hours_since_last_customer_reply: {
+            label: 'Hours since last customer reply',
+            type: FIELD_TYPE.NUMBER,
+            operators: FIELD_OPERATORS.NUMBER
+        },
+        hours_since_last_agent_reply: {
+            label: 'Hours since last agent reply',
+            type: FIELD_TYPE.NUMBER,
+            operators: FIELD_OPERATORS.NUMBER
+        },
+        sla_status: {
+            label: 'SLA status',
+            type: FIELD_TYPE.SELECT,
+            operators: FIELD_OPERATORS.SELECT,
+            options: [
+                { label: 'Pending', value: 'pending' },
+                { label: 'Breached', value: 'breached' },
+                { label: 'Met', value: 'met' },
+                { label: 'Partially met', value: 'partially_met' }
+            ]
+        },
+        minutes_to_sla_breach: {
+            label: 'Minutes to SLA breach',
+            type: FIELD_TYPE.NUMBER,
+            operators: FIELD_OPERATORS.NUMBER
+        },
+        cc: {
+            label: 'CC recipients',
+            type: FIELD_TYPE.TAG,
+            operators: FIELD_OPERATORS.LIST
+        },
+        last_message_has_attachments: {
+            label: 'Last message has attachments',
+            type: FIELD_TYPE.SELECT,
+            operators: FIELD_OPERATORS.BOOLEAN,
+            options: [
+                { label: 'Yes', value: 'true' },
+                { label: 'No', value: 'false' }
+            ]
+        },
+        created_at: {
+            label: 'Created',
+            type: FIELD_TYPE.DATE,
+            operators: FIELD_OPERATORS.DATE
+        },
+        resolved_at: {
+            label: 'Resolved',
+            type: FIELD_TYPE.DATE,
+            operators: FIELD_OPERATORS.DATE
+        },
+        last_customer_reply_at: {
+            label: 'Last customer reply',
+            type: FIELD_TYPE.DATE,
+            operators: FIELD_OPERATORS.DATE
+        },
+        last_agent_reply_at: {
+            label: 'Last agent reply',
+            type: FIELD_TYPE.DATE,
+            operators: FIELD_OPERATORS.DATE
+        },
+        last_message_sender: {
+            label: 'Last message sent by',
+            type: FIELD_TYPE.SELECT,
+            operators: FIELD_OPERATORS.BOOLEAN,
+            options: [
+                { label: 'Contact', value: 'contact' },
+                { label: 'Agent', value: 'agent' }
+            ]
+        }
+    }
+
     const newConversationFilters = computed(() => ({
         contact_email: {
             label: 'Email',
@@ -101,7 +231,8 @@ export function useConversationFilters () {
             type: FIELD_TYPE.SELECT,
             operators: FIELD_OPERATORS.SELECT,
             options: iStore.options
-        }
+        },
+        ...conditionFields
     }))
 
     const conversationFilters = computed(() => ({
@@ -144,9 +275,24 @@ export function useConversationFilters () {
             type: FIELD_TYPE.SELECT,
             operators: FIELD_OPERATORS.SELECT,
             options: iStore.options
-        }
+        },
+        ...conditionFields
     }))
 
+    const snoozeOptions = [
+        { label: '1 hour', value: '1h' },
+        { label: '4 hours', value: '4h' },
+        { label: '1 day', value: '24h' },
+        { label: '1 week', value: '168h' },
+        { label: 'Next business day', value: 'next_business_day' },
+        { label: 'Until customer replies', value: 'customer_reply' }
+    ]
+
+    const assigneeTypeOptions = [
+        { label: 'User', value: 'user' },
+        { label: 'Team', value: 'team' }
+    ]
+
     const conversationActions = computed(() => ({
         assign_team: {
             label: 'Assign to team',
@@ -179,6 +325,9 @@ export function useConversationFilters () {
         send_csat: {
             label: 'Send CSAT',
         },
+        send_transcript: {
+            label: 'Email transcript to contact',
+        },
         set_sla: {
             label: 'Set SLA',
             type: FIELD_TYPE.SELECT,
@@ -187,6 +336,49 @@ export function useConversationFilters () {
         set_tags: {
             label: 'Set tags',
             type: FIELD_TYPE.TAG
+        },
+        add_follower: {
+            label: 'Add follower',
+            type: FIELD_TYPE.SELECT,
+            options: uStore.options
+        },
+        create_task: {
+            label: 'Create task',
+            type: FIELD_TYPE.TASK,
+            options: uStore.options
+        },
+        add_tags: {
+            label: 'Add tags',
+            type: FIELD_TYPE.TAG
+        },
+        remove_tags: {
+            label: 'Remove tags',
+            type: FIELD_TYPE.TAG
+        },
+        snooze_for: {
+            label: 'Snooze',
+            type: FIELD_TYPE.SELECT,
+            options: snoozeOptions
+        },
+        close_conversation: {
+            label: 'Close conversation',
+        },
+        remove_assignee: {
+            label: 'Remove assignee',
+            type: FIELD_TYPE.SELECT,
+            options: assigneeTypeOptions
+        },
+        set_custom_attribute: {
+            label: 'Set custom attribute',
+            type: FIELD_TYPE.KEY_VALUE
+        },
+        send_email_to: {
+            label: 'Send email',
+            type: FIELD_TYPE.EMAIL
+        },
+        send_webhook: {
+            label: 'Send webhook',
+            type: FIELD_TYPE.WEBHOOK
         }
     }))
 
@@ -214,6 +406,40 @@ export function useConversationFilters () {
         set_tags: {
             label: 'Set tags',
             type: FIELD_TYPE.TAG
+        },
+        add_follower: {
+            label: 'Add follower',
+            type: FIELD_TYPE.SELECT,
+            options: uStore.options
+        },
+        add_tags: {
+            label: 'Add tags',
+            type: FIELD_TYPE.TAG
+        },
+        remove_tags: {
+            label: 'Remove tags',
+            type: FIELD_TYPE.TAG
+        },
+        snooze_for: {
+            label: 'Snooze',
+            type: FIELD_TYPE.SELECT,
+            options: snoozeOptions
+        },
+        close_conversation: {
+            label: 'Close conversation',
+        },
+        remove_assignee: {
+            label: 'Remove assignee',
+            type: FIELD_TYPE.SELECT,
+            options: assigneeTypeOptions
+        },
+        set_custom_attribute: {
+            label: 'Set custom attribute',
+            type: FIELD_TYPE.KEY_VALUE
+        },
+        send_email_to: {
+            label: 'Send email',
+            type: FIELD_TYPE.EMAIL
         }
     }))
 
@@ -3,6 +3,9 @@ export const EMITTER_EVENTS = {
     SHOW_TOAST: 'show-toast',
     SHOW_SOONER: 'show-sooner',
     NEW_MESSAGE: 'new-message',
+    NEW_SIDE_THREAD_MESSAGE: 'new-side-thread-message',
+    SIDE_THREAD_MESSAGE_PROP_UPDATE: 'side-thread-message-prop-update',
+    TASK_DUE: 'task-due',
     SET_NESTED_COMMAND: 'set-nested-command',
     CONVERSATION_SIDEBAR_TOGGLE: 'conversation-sidebar-toggle'
 }
\ No newline at end of file
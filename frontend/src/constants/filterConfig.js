@@ -3,7 +3,12 @@ export const FIELD_TYPE = {
     TAG: 'tag',
     TEXT: 'text',
     NUMBER: 'number',
-    RICHTEXT: 'richtext'
+    RICHTEXT: 'richtext',
+    TASK: 'task',
+    WEBHOOK: 'webhook',
+    KEY_VALUE: 'key_value',
+    EMAIL: 'email',
+    DATE: 'date'
 }
 
 export const OPERATOR = {
@@ -14,7 +19,14 @@ export const OPERATOR = {
     CONTAINS: 'contains',
     NOT_CONTAINS: 'not contains',
     GREATER_THAN: 'greater than',
-    LESS_THAN: 'less than'
+    LESS_THAN: 'less than',
+    BETWEEN: 'between',
+    STARTS_WITH: 'starts with',
+    ENDS_WITH: 'ends with',
+    IN_LIST: 'in list',
+    MATCHES_REGEX: 'matches regex',
+    WITHIN_LAST_HOURS: 'within last hours',
+    NOT_WITHIN_LAST_HOURS: 'not within last hours'
 }
 
 export const FIELD_OPERATORS = {
@@ -25,7 +37,25 @@ export const FIELD_OPERATORS = {
         OPERATOR.SET,
         OPERATOR.NOT_SET,
         OPERATOR.CONTAINS,
-        OPERATOR.NOT_CONTAINS
+        OPERATOR.NOT_CONTAINS,
+        OPERATOR.STARTS_WITH,
+        OPERATOR.ENDS_WITH,
+        OPERATOR.IN_LIST,
+        OPERATOR.MATCHES_REGEX
     ],
-    NUMBER: [OPERATOR.GREATER_THAN, OPERATOR.LESS_THAN]
+    NUMBER: [
+        OPERATOR.EQUALS,
+        OPERATOR.NOT_EQUALS,
+        OPERATOR.GREATER_THAN,
+        OPERATOR.LESS_THAN,
+        OPERATOR.BETWEEN
+    ],
+    DATE: [
+        OPERATOR.WITHIN_LAST_HOURS,
+        OPERATOR.NOT_WITHIN_LAST_HOURS,
+        OPERATOR.SET,
+        OPERATOR.NOT_SET
+    ],
+    LIST: [OPERATOR.CONTAINS, OPERATOR.NOT_CONTAINS, OPERATOR.SET, OPERATOR.NOT_SET],
+    BOOLEAN: [OPERATOR.EQUALS]
 }
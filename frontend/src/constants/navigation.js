@@ -44,6 +44,11 @@ export const adminNavItems = [
                 title: 'Statuses',
                 href: '/admin/conversations/statuses',
                 permission: 'status:manage'
+            },
+            {
+                title: 'Priorities',
+                href: '/admin/conversations/priorities',
+                permission: 'priority:manage'
             }
         ]
     },
@@ -84,6 +89,11 @@ export const adminNavItems = [
                 title: 'Automations',
                 href: '/admin/automations',
                 permission: 'automations:manage'
+            },
+            {
+                title: 'Webhooks',
+                href: '/admin/webhooks',
+                permission: 'webhooks:manage'
             }
         ]
     },
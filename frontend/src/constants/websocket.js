@@ -2,4 +2,13 @@ export const WS_EVENT = {
     NEW_MESSAGE: 'new_message',
     MESSAGE_PROP_UPDATE: 'message_prop_update',
     CONVERSATION_PROP_UPDATE: 'conversation_prop_update',
+    CONVERSATION_PRESENCE: 'conversation_presence',
+    NOTIFICATION: 'notification',
+    BULK_JOB_PROGRESS: 'bulk_job_progress',
+}
+
+export const WS_CLIENT_EVENT = {
+    CONVERSATION_VIEW: 'conversation_view',
+    CONVERSATION_LEAVE: 'conversation_leave',
+    TYPING: 'typing',
 }
\ No newline at end of file
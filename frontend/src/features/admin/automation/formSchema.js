@@ -13,6 +13,7 @@ export const formSchema = z
             required_error: 'Rule type is required.',
         }),
         events: z.array(z.string()).optional(),
+        schedule: z.string().optional(),
     })
     .superRefine((data, ctx) => {
         if (data.type === 'conversation_update' && (!data.events || data.events.length === 0)) {
@@ -12,6 +12,15 @@ export const columns = [
       return h('div', { class: 'text-center font-medium' }, row.getValue('name'))
     }
   },
+  {
+    accessorKey: 'category',
+    header: function () {
+      return h('div', { class: 'text-center' }, 'Category')
+    },
+    cell: function ({ row }) {
+      return h('div', { class: 'text-center capitalize' }, row.getValue('category'))
+    }
+  },
   {
     accessorKey: 'created_at',
     header: function () {
@@ -7,5 +7,6 @@ export const formSchema = z.object({
     })
     .min(1, {
       message: 'Status must be at least 1 character.'
-    })
+    }),
+  category: z.enum(['open', 'pending', 'solved', 'closed']).default('open')
 })
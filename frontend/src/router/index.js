@@ -346,6 +346,12 @@ const routes = [
               }
             ]
           },
+          {
+            path: 'webhooks',
+            component: () => import('@/views/admin/webhooks/WebhooksView.vue'),
+            name: 'webhooks',
+            meta: { title: 'Webhooks' }
+          },
           {
             path: 'templates',
             component: () => import('@/views/admin/templates/Templates.vue'),
@@ -408,6 +414,11 @@ const routes = [
                 component: () => import('@/views/admin/status/StatusView.vue'),
                 meta: { title: 'Statuses' }
               },
+              {
+                path: 'priorities',
+                component: () => import('@/views/admin/priority/PriorityView.vue'),
+                meta: { title: 'Priorities' }
+              },
               {
                 path: 'macros',
                 component: () => import('@/views/admin/macros/Macros.vue'),
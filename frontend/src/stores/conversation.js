@@ -1,6 +1,6 @@
 import { defineStore } from 'pinia'
 import { computed, reactive, ref, nextTick } from 'vue'
-import { CONVERSATION_LIST_TYPE, CONVERSATION_DEFAULT_STATUSES } from '@/constants/conversation'
+import { CONVERSATION_LIST_TYPE } from '@/constants/conversation'
 import { handleHTTPError } from '@/utils/http'
 import { useEmitter } from '@/composables/useEmitter'
 import { EMITTER_EVENTS } from '@/constants/emitterEvents'
@@ -20,9 +20,11 @@ export const useConversationStore = defineStore('conversation', () => {
   const statusOptions = computed(() => {
     return statuses.value.map(s => ({ label: s.name, value: s.id }))
   })
-  // Status options excluding 'Snoozed'
+  // The status conversations are snoozed with.
+  const snoozeStatus = computed(() => statuses.value.find(s => s.is_snooze))
+  // Status options excluding the snooze status
   const statusOptionsNoSnooze = computed(() =>
-    statuses.value.filter(s => s.name !== 'Snoozed').map(s => ({
+    statuses.value.filter(s => !s.is_snooze).map(s => ({
       label: s.name,
       value: s.id
     }))
@@ -55,7 +57,7 @@ export const useConversationStore = defineStore('conversation', () => {
       order: 'asc'
     },
     priority_first: {
-      field: 'priority_id',
+      field: 'priority_rank',
       order: 'desc'
     }
   }
@@ -88,6 +90,7 @@ export const useConversationStore = defineStore('conversation', () => {
   const conversation = reactive({
     data: null,
     participants: {},
+    viewers: [],
     mediaFiles: [],
     macro: {},
     loading: false,
@@ -100,6 +103,9 @@ export const useConversationStore = defineStore('conversation', () => {
     page: 1,
   })
 
+  // Bulk action jobs started by the user, keyed by job id.
+  const bulkJobs = reactive({})
+
   let seenConversationUUIDs = new Map()
   let reRenderInterval = setInterval(() => {
     conversations.data = [...conversations.data]
@@ -482,7 +488,7 @@ export const useConversationStore = defineStore('conversation', () => {
 
   async function snoozeConversation (snoozeDuration) {
     try {
-      await api.updateConversationStatus(conversation.data.uuid, { status: CONVERSATION_DEFAULT_STATUSES.SNOOZED, snoozed_until: snoozeDuration })
+      await api.updateConversationStatus(conversation.data.uuid, { status: snoozeStatus.value?.name, snoozed_until: snoozeDuration })
     } catch (error) {
       emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
         title: 'Error',
@@ -583,6 +589,25 @@ export const useConversationStore = defineStore('conversation', () => {
     }
   }
 
+  /**
+   * Notify the side threads of the open conversation about a new side thread message.
+   *
+   * @param {object} message - Message object with conversation_uuid and side_thread_id fields
+   */
+  function updateSideThreadMessage (message) {
+    if (message.conversation_uuid !== conversation?.data?.uuid) return
+    emitter.emit(EMITTER_EVENTS.NEW_SIDE_THREAD_MESSAGE, message)
+  }
+
+  function handleNotification (notification) {
+    if (notification.event !== 'task_due') return
+    emitter.emit(EMITTER_EVENTS.SHOW_TOAST, {
+      title: `Task due #${notification.reference_number}`,
+      description: notification.content
+    })
+    emitter.emit(EMITTER_EVENTS.TASK_DUE, notification)
+  }
+
   function addNewConversation (conversation) {
     if (!conversationUUIDExists(conversation.uuid)) {
       // Fetch list of conversations again.
@@ -597,9 +622,19 @@ export const useConversationStore = defineStore('conversation', () => {
    */
   function updateMessageProp (message) {
     const exists = messages.data.hasMessage(message.conversation_uuid, message.uuid)
-    if (exists) {
-      messages.data.updateMessageField(message.conversation_uuid, message.uuid, message.prop, message.value)
+    if (!exists) {
+      // Side thread messages are not in the conversation messages, e.g. their status changes once sent.
+      if (message.conversation_uuid === conversation?.data?.uuid) {
+        emitter.emit(EMITTER_EVENTS.SIDE_THREAD_MESSAGE_PROP_UPDATE, message)
+      }
+      return
+    }
+    // Cancelled scheduled messages are deleted.
+    if (message.prop === 'deleted') {
+      messages.data.removeMessage(message.conversation_uuid, message.uuid)
+      return
     }
+    messages.data.updateMessageField(message.conversation_uuid, message.uuid, message.prop, message.value)
   }
 
   function updateConversationProp (update) {
@@ -612,10 +647,24 @@ export const useConversationStore = defineStore('conversation', () => {
     }
   }
 
+  function updateConversationViewers (presence) {
+    if (conversation.data?.uuid === presence.conversation_uuid) {
+      conversation.viewers = presence.viewers
+    }
+  }
+
+  function updateBulkJob (job) {
+    bulkJobs[job.id] = job
+    if (job.status === 'completed') {
+      reFetchConversationsList(false)
+    }
+  }
+
   function resetCurrentConversation () {
     Object.assign(conversation, {
       data: null,
       participants: {},
+      viewers: [],
       mediaFiles: [],
       macro: {},
       loading: false,
@@ -631,6 +680,7 @@ export const useConversationStore = defineStore('conversation', () => {
 
 
   return {
+    bulkJobs,
     conversations,
     conversation,
     messages,
@@ -645,6 +695,8 @@ export const useConversationStore = defineStore('conversation', () => {
     clearListReRenderInterval,
     conversationUUIDExists,
     updateConversationProp,
+    updateConversationViewers,
+    updateBulkJob,
     addNewConversation,
     getContactFullName,
     fetchParticipants,
@@ -653,6 +705,8 @@ export const useConversationStore = defineStore('conversation', () => {
     updateMessageProp,
     updateAssigneeLastSeen,
     updateConversationMessage,
+    updateSideThreadMessage,
+    handleNotification,
     snoozeConversation,
     fetchConversation,
     fetchConversationsList,
@@ -679,6 +733,7 @@ export const useConversationStore = defineStore('conversation', () => {
     priorities,
     priorityOptions,
     statusOptionsNoSnooze,
+    snoozeStatus,
     statusOptions
   }
 })
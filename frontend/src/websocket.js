@@ -57,11 +57,20 @@ export class WebSocketClient {
       const handlers = {
         // On new message, update the message in the conversation list and in the currently opened conversation.
         [WS_EVENT.NEW_MESSAGE]: () => {
+          // Side thread messages are shown in the side thread and not in the conversation.
+          if (data.data.side_thread_id) {
+            this.convStore.updateSideThreadMessage(data.data)
+            return
+          }
           this.convStore.updateConversationList(data.data)
           this.convStore.updateConversationMessage(data.data)
         },
         [WS_EVENT.MESSAGE_PROP_UPDATE]: () => this.convStore.updateMessageProp(data.data),
-        [WS_EVENT.CONVERSATION_PROP_UPDATE]: () => this.convStore.updateConversationProp(data.data)
+        [WS_EVENT.CONVERSATION_PROP_UPDATE]: () => this.convStore.updateConversationProp(data.data),
+        [WS_EVENT.CONVERSATION_PRESENCE]: () => this.convStore.updateConversationViewers(data.data),
+        // Notifications are also delivered by email, only due tasks are shown in the UI.
+        [WS_EVENT.NOTIFICATION]: () => this.convStore.handleNotification(data.data),
+        [WS_EVENT.BULK_JOB_PROGRESS]: () => this.convStore.updateBulkJob(data.data)
       }
 
       const handler = handlers[data.type]
@@ -17,8 +17,14 @@ import (
 
 // Enforcer is a wrapper around Casbin enforcer.
 type Enforcer struct {
-	enforcer *casbin.SyncedEnforcer
-	lo       *logf.Logger
+	enforcer     *casbin.SyncedEnforcer
+	lo           *logf.Logger
+	mentionStore MentionStore
+}
+
+// MentionStore checks if a user was mentioned in a conversation and still has access through it.
+type MentionStore interface {
+	HasActiveMention(conversationID, userID int) (bool, error)
 }
 
 const casbinModel = `
@@ -49,6 +55,11 @@ func NewEnforcer(lo *logf.Logger) (*Enforcer, error) {
 	return &Enforcer{enforcer: e, lo: lo}, nil
 }
 
+// SetMentionStore sets the store used to grant temporary conversation access to mentioned users.
+func (e *Enforcer) SetMentionStore(store MentionStore) {
+	e.mentionStore = store
+}
+
 // LoadPermissions syncs user permissions with Casbin enforcer by removing existing
 // policies and adding current permissions as new policies
 func (e *Enforcer) LoadPermissions(user umodels.User) error {
@@ -94,6 +105,7 @@ func (e *Enforcer) Enforce(user umodels.User, obj, act string) (bool, error) {
 // 2. User has the "read_assigned" permission and is the assigned user.
 // 3. User has the "read_team_inbox" permission and is part of the assigned team, with the conversation unassigned to any specific user.
 // 4. User has the "read_unassigned" permission and the conversation is unassigned to any user or team.
+// 5. User, or one of their teams, was mentioned in the conversation and the mention access has not expired.
 // Returns true if access is granted, false otherwise. In case of an error while checking permissions, returns false and the error.
 func (e *Enforcer) EnforceConversationAccess(user umodels.User, conversation cmodels.Conversation) (bool, error) {
 	checkPermission := func(action string) (bool, error) {
@@ -138,9 +150,27 @@ func (e *Enforcer) EnforceConversationAccess(user umodels.User, conversation cmo
 			return allowed, err
 		}
 	}
+
 	return false, nil
 }
 
+// EnforceConversationReadAccess determines if a user can view a conversation. Besides the access granted by
+// EnforceConversationAccess, users mentioned in the conversation get temporary read only access.
+func (e *Enforcer) EnforceConversationReadAccess(user umodels.User, conversation cmodels.Conversation) (bool, error) {
+	allowed, err := e.EnforceConversationAccess(user, conversation)
+	if err != nil || allowed || e.mentionStore == nil {
+		return allowed, err
+	}
+	if allowed, err := e.Enforce(user, "conversations", "read"); err != nil || !allowed {
+		if err != nil {
+			e.lo.Error("error enforcing permission", "user_id", user.ID, "conversation_id", conversation.ID, "error", err)
+			return false, envelope.NewError(envelope.GeneralError, "Error checking permissions", nil)
+		}
+		return false, nil
+	}
+	return e.mentionStore.HasActiveMention(conversation.ID, user.ID)
+}
+
 // EnforceMediaAccess checks for read access on linked model to media.
 func (e *Enforcer) EnforceMediaAccess(user umodels.User, model string) (bool, error) {
 	switch model {
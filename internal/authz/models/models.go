@@ -12,6 +12,7 @@ const (
 	PermConversationsUpdatePriority     = "conversations:update_priority"
 	PermConversationsUpdateStatus       = "conversations:update_status"
 	PermConversationsUpdateTags         = "conversations:update_tags"
+	PermConversationsUpdateAttributes   = "conversations:update_custom_attributes"
 	PermConversationWrite               = "conversations:write"
 	PermMessagesRead                    = "messages:read"
 	PermMessagesWrite                   = "messages:write"
@@ -22,6 +23,9 @@ const (
 	// Status
 	PermStatusManage = "status:manage"
 
+	// Priority
+	PermPriorityManage = "priority:manage"
+
 	// Tags
 	PermTagsManage = "tags:manage"
 
@@ -66,6 +70,12 @@ const (
 
 	// AI
 	PermAIManage = "ai:manage"
+
+	// Retention
+	PermRetentionManage = "retention:manage"
+
+	// Webhooks
+	PermWebhooksManage = "webhooks:manage"
 )
 
 var validPermissions = map[string]struct{}{
@@ -79,11 +89,13 @@ var validPermissions = map[string]struct{}{
 	PermConversationsUpdatePriority:     {},
 	PermConversationsUpdateStatus:       {},
 	PermConversationsUpdateTags:         {},
+	PermConversationsUpdateAttributes:   {},
 	PermConversationWrite:               {},
 	PermMessagesRead:                    {},
 	PermMessagesWrite:                   {},
 	PermViewManage:                      {},
 	PermStatusManage:                    {},
+	PermPriorityManage:                  {},
 	PermTagsManage:                      {},
 	PermMacrosManage:                    {},
 	PermUsersManage:                     {},
@@ -99,6 +111,8 @@ var validPermissions = map[string]struct{}{
 	PermNotificationSettingsManage:      {},
 	PermOIDCManage:                      {},
 	PermAIManage:                        {},
+	PermRetentionManage:                 {},
+	PermWebhooksManage:                  {},
 }
 
 // IsValidPermission returns true if it's a valid permission.
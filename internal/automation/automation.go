@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"embed"
 	"encoding/json"
+	"regexp"
 	"slices"
 	"sync"
 	"time"
@@ -15,7 +16,9 @@ import (
 	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"github.com/zerodha/logf"
@@ -26,6 +29,24 @@ var (
 	efs embed.FS
 	// MaxQueueSize defines the maximum size of the task queues.
 	MaxQueueSize = 5000
+	// DefaultExecutionLogRetention is how long rule execution logs are kept when no retention is configured.
+	DefaultExecutionLogRetention = 30 * 24 * time.Hour
+	// DefaultMaxRuleDepth is the default number of rules that can trigger each other through their actions.
+	DefaultMaxRuleDepth = 5
+	// DefaultMaxRuleExecutions is the default number of times a rule can run on a conversation in the limit window.
+	DefaultMaxRuleExecutions = 10
+	// DefaultMaxConversationExecutions is the default number of rule executions on a conversation in the limit window.
+	DefaultMaxConversationExecutions = 50
+	// DefaultExecutionLimitWindow is the default window the execution limits are counted over.
+	DefaultExecutionLimitWindow = time.Hour
+)
+
+const (
+	maxExecutionsPage = 100
+	// timeTriggerBatchSize is the number of conversations a time trigger rule is evaluated on per query.
+	timeTriggerBatchSize = 500
+	// maxCachedRegexes caps the compiled regex cache, it is reset once full.
+	maxCachedRegexes = 1000
 )
 
 // TaskType represents the type of conversation task.
@@ -42,14 +63,25 @@ type ConversationTask struct {
 	taskType         TaskType
 	eventType        string
 	conversationUUID string
+	// ruleIDs are the time trigger rules due to run.
+	ruleIDs []int
+	// chain holds the IDs of the rules whose actions caused the event, oldest first.
+	chain []int
 }
 
 type Engine struct {
 	rules             []models.Rule
 	rulesMu           sync.RWMutex
 	q                 queries
+	db                *sqlx.DB
 	lo                *logf.Logger
 	conversationStore conversationStore
+	webhookStore      webhookStore
+	notifier          notificationSender
+	logRetention      time.Duration
+	limits            limits
+	regexCache        map[string]*regexp.Regexp
+	regexMu           sync.Mutex
 	taskQueue         chan ConversationTask
 	closed            bool
 	closedMu          sync.RWMutex
@@ -59,24 +91,53 @@ type Engine struct {
 type Opts struct {
 	DB *sqlx.DB
 	Lo *logf.Logger
+	// ExecutionLogRetention is how long rule execution logs are kept.
+	ExecutionLogRetention time.Duration
+	// MaxRuleDepth is the number of rules that can trigger each other through their actions.
+	MaxRuleDepth int
+	// MaxRuleExecutions is the number of times a rule can run on a conversation in ExecutionLimitWindow.
+	MaxRuleExecutions int
+	// MaxConversationExecutions is the number of rule executions on a conversation in ExecutionLimitWindow.
+	MaxConversationExecutions int
+	// ExecutionLimitWindow is the window the execution limits are counted over.
+	ExecutionLimitWindow time.Duration
 }
 
 type conversationStore interface {
 	ApplyAction(action models.RuleAction, conversation cmodels.Conversation, user umodels.User) error
+	ApplyRuleAction(action models.RuleAction, conversation cmodels.Conversation, ruleName string) error
 	GetConversation(teamID int, uuid string) (cmodels.Conversation, error)
-	GetConversationsCreatedAfter(time.Time) ([]cmodels.Conversation, error)
+	GetRecentConversations(limit int) ([]cmodels.Conversation, error)
+}
+
+type webhookStore interface {
+	Enqueue(req wmodels.Request) error
+}
+
+type notificationSender interface {
+	Send(message notifier.Message) error
 }
 
 type queries struct {
-	GetAll                  *sqlx.Stmt `query:"get-all"`
-	GetRule                 *sqlx.Stmt `query:"get-rule"`
-	InsertRule              *sqlx.Stmt `query:"insert-rule"`
-	UpdateRule              *sqlx.Stmt `query:"update-rule"`
-	DeleteRule              *sqlx.Stmt `query:"delete-rule"`
-	ToggleRule              *sqlx.Stmt `query:"toggle-rule"`
-	GetEnabledRules         *sqlx.Stmt `query:"get-enabled-rules"`
-	UpdateRuleWeight        *sqlx.Stmt `query:"update-rule-weight"`
-	UpdateRuleExecutionMode *sqlx.Stmt `query:"update-rule-execution-mode"`
+	GetAll                   *sqlx.Stmt `query:"get-all"`
+	GetRule                  *sqlx.Stmt `query:"get-rule"`
+	InsertRule               *sqlx.Stmt `query:"insert-rule"`
+	UpdateRule               *sqlx.Stmt `query:"update-rule"`
+	DeleteRule               *sqlx.Stmt `query:"delete-rule"`
+	ToggleRule               *sqlx.Stmt `query:"toggle-rule"`
+	GetEnabledRules          *sqlx.Stmt `query:"get-enabled-rules"`
+	UpdateRuleWeight         *sqlx.Stmt `query:"update-rule-weight"`
+	UpdateRuleExecutionMode  *sqlx.Stmt `query:"update-rule-execution-mode"`
+	InsertExecution          *sqlx.Stmt `query:"insert-execution"`
+	GetExecutions            *sqlx.Stmt `query:"get-executions"`
+	DeleteOldExecutions      *sqlx.Stmt `query:"delete-old-executions"`
+	GetConversationFacts     *sqlx.Stmt `query:"get-conversation-facts"`
+	UpdateRuleLastRun        *sqlx.Stmt `query:"update-rule-last-run"`
+	UpsertRuleTrigger        *sqlx.Stmt `query:"upsert-rule-trigger"`
+	DisableRule              *sqlx.Stmt `query:"disable-rule"`
+	GetExecutionCounts       *sqlx.Stmt `query:"get-execution-counts"`
+	GetAutomationManagers    *sqlx.Stmt `query:"get-automation-managers"`
+	GetTimeTriggerCandidates string     `query:"get-time-trigger-candidates"`
 }
 
 // New initializes a new Engine.
@@ -84,10 +145,34 @@ func New(opt Opts) (*Engine, error) {
 	var (
 		q queries
 		e = &Engine{
-			lo:        opt.Lo,
-			taskQueue: make(chan ConversationTask, MaxQueueSize),
+			db:           opt.DB,
+			lo:           opt.Lo,
+			logRetention: opt.ExecutionLogRetention,
+			limits: limits{
+				maxDepth:                  opt.MaxRuleDepth,
+				maxRuleExecutions:         opt.MaxRuleExecutions,
+				maxConversationExecutions: opt.MaxConversationExecutions,
+				window:                    opt.ExecutionLimitWindow,
+			},
+			regexCache: make(map[string]*regexp.Regexp),
+			taskQueue:  make(chan ConversationTask, MaxQueueSize),
 		}
 	)
+	if e.logRetention <= 0 {
+		e.logRetention = DefaultExecutionLogRetention
+	}
+	if e.limits.maxDepth <= 0 {
+		e.limits.maxDepth = DefaultMaxRuleDepth
+	}
+	if e.limits.maxRuleExecutions <= 0 {
+		e.limits.maxRuleExecutions = DefaultMaxRuleExecutions
+	}
+	if e.limits.maxConversationExecutions <= 0 {
+		e.limits.maxConversationExecutions = DefaultMaxConversationExecutions
+	}
+	if e.limits.window <= 0 {
+		e.limits.window = DefaultExecutionLimitWindow
+	}
 	if err := dbutil.ScanSQLFile("queries.sql", &q, opt.DB, efs); err != nil {
 		return nil, err
 	}
@@ -101,6 +186,38 @@ func (e *Engine) SetConversationStore(store conversationStore) {
 	e.conversationStore = store
 }
 
+// SetWebhookStore sets the webhook store used by the send webhook action.
+func (e *Engine) SetWebhookStore(store webhookStore) {
+	e.webhookStore = store
+}
+
+// SetNotifier sets the notifier used to tell admins a rule was disabled.
+func (e *Engine) SetNotifier(n notificationSender) {
+	e.notifier = n
+}
+
+// compileRegex returns the compiled regex for a condition pattern, compiled patterns are cached as rules
+// are evaluated on every conversation event.
+func (e *Engine) compileRegex(pattern string, caseSensitive bool) (*regexp.Regexp, error) {
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	e.regexMu.Lock()
+	defer e.regexMu.Unlock()
+	if re, ok := e.regexCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.regexCache) >= maxCachedRegexes {
+		e.regexCache = make(map[string]*regexp.Regexp)
+	}
+	e.regexCache[pattern] = re
+	return re, nil
+}
+
 // ReloadRules reloads automation rules from DB.
 func (e *Engine) ReloadRules() {
 	e.rulesMu.Lock()
@@ -117,9 +234,12 @@ func (e *Engine) Run(ctx context.Context, workerCount int) {
 		go e.worker(ctx)
 	}
 
-	// Hourly ticker for timed triggers.
+	// Time trigger rules are checked every minute against their schedules.
+	scheduleTicker := time.NewTicker(1 * time.Minute)
+	// Hourly ticker for cleaning up execution logs.
 	ticker := time.NewTicker(1 * time.Hour)
 	defer func() {
+		scheduleTicker.Stop()
 		ticker.Stop()
 	}()
 
@@ -127,9 +247,13 @@ func (e *Engine) Run(ctx context.Context, workerCount int) {
 		select {
 		case <-ctx.Done():
 			return
+		case now := <-scheduleTicker.C:
+			if ruleIDs := e.dueTimeTriggerRules(now); len(ruleIDs) > 0 {
+				e.lo.Info("queuing time triggers", "rule_ids", ruleIDs)
+				e.taskQueue <- ConversationTask{taskType: TimeTrigger, ruleIDs: ruleIDs}
+			}
 		case <-ticker.C:
-			e.lo.Info("queuing time triggers")
-			e.taskQueue <- ConversationTask{taskType: TimeTrigger}
+			e.deleteOldExecutions()
 		}
 	}
 }
@@ -149,9 +273,9 @@ func (e *Engine) worker(ctx context.Context) {
 			case NewConversation:
 				e.handleNewConversation(task.conversationUUID)
 			case UpdateConversation:
-				e.handleUpdateConversation(task.conversationUUID, task.eventType)
+				e.handleUpdateConversation(task.conversationUUID, task.eventType, task.chain)
 			case TimeTrigger:
-				e.handleTimeTrigger()
+				e.handleTimeTrigger(task.ruleIDs)
 			}
 		}
 	}
@@ -177,6 +301,9 @@ func (e *Engine) GetAllRules(typ []byte) ([]models.RuleRecord, error) {
 		e.lo.Error("error fetching rules", "error", err)
 		return rules, envelope.NewError(envelope.GeneralError, "Error fetching automation rules.", nil)
 	}
+	for i := range rules {
+		rules[i].Rules = maskWebhookSecrets(rules[i].Rules)
+	}
 	return rules, nil
 }
 
@@ -190,6 +317,7 @@ func (e *Engine) GetRule(id int) (models.RuleRecord, error) {
 		e.lo.Error("error fetching rule", "error", err)
 		return rule, envelope.NewError(envelope.GeneralError, "Error fetching automation rule.", nil)
 	}
+	rule.Rules = maskWebhookSecrets(rule.Rules)
 	return rule, nil
 }
 
@@ -204,9 +332,22 @@ func (e *Engine) ToggleRule(id int) error {
 	return nil
 }
 
-// UpdateRule updates an existing rule.
+// UpdateRule updates an existing rule, masked webhook secrets keep their stored value.
 func (e *Engine) UpdateRule(id int, rule models.RuleRecord) error {
-	if _, err := e.q.UpdateRule.Exec(id, rule.Name, rule.Description, rule.Type, pq.Array(rule.Events), rule.Rules, rule.Enabled); err != nil {
+	var stored models.RuleRecord
+	if err := e.q.GetRule.Get(&stored, id); err != nil {
+		if err == sql.ErrNoRows {
+			return envelope.NewError(envelope.InputError, "Rule not found.", nil)
+		}
+		e.lo.Error("error fetching rule", "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error updating automation rule.", nil)
+	}
+	rules, err := keepWebhookSecrets(rule.Rules, stored.Rules)
+	if err != nil {
+		return envelope.NewError(envelope.InputError, "Invalid rules", nil)
+	}
+	rule.Rules = rules
+	if _, err := e.q.UpdateRule.Exec(id, rule.Name, rule.Description, rule.Type, pq.Array(rule.Events), rule.Rules, rule.Enabled, rule.Schedule); err != nil {
 		e.lo.Error("error updating rule", "error", err)
 		return envelope.NewError(envelope.GeneralError, "Error updating automation rule.", nil)
 	}
@@ -217,7 +358,7 @@ func (e *Engine) UpdateRule(id int, rule models.RuleRecord) error {
 
 // CreateRule creates a new rule.
 func (e *Engine) CreateRule(rule models.RuleRecord) error {
-	if _, err := e.q.InsertRule.Exec(rule.Name, rule.Description, rule.Type, pq.Array(rule.Events), rule.Rules); err != nil {
+	if _, err := e.q.InsertRule.Exec(rule.Name, rule.Description, rule.Type, pq.Array(rule.Events), rule.Rules, rule.Schedule); err != nil {
 		e.lo.Error("error creating rule", "error", err)
 		return envelope.NewError(envelope.GeneralError, "Error creating automation rule.", nil)
 	}
@@ -281,6 +422,12 @@ func (e *Engine) EvaluateNewConversationRules(conversationUUID string) {
 
 // EvaluateConversationUpdateRules enqueues an updated conversation for rule evaluation.
 func (e *Engine) EvaluateConversationUpdateRules(conversationUUID string, eventType string) {
+	e.enqueueConversationUpdate(conversationUUID, eventType, nil)
+}
+
+// enqueueConversationUpdate enqueues an updated conversation for rule evaluation, chain holds the IDs of the rules
+// whose actions caused the update.
+func (e *Engine) enqueueConversationUpdate(conversationUUID, eventType string, chain []int) {
 	if eventType == "" {
 		e.lo.Error("error evaluating conversation update rules: eventType is empty")
 		return
@@ -295,6 +442,7 @@ func (e *Engine) EvaluateConversationUpdateRules(conversationUUID string, eventT
 		taskType:         UpdateConversation,
 		conversationUUID: conversationUUID,
 		eventType:        eventType,
+		chain:            chain,
 	}:
 	default:
 		// Queue is full.
@@ -315,11 +463,11 @@ func (e *Engine) handleNewConversation(conversationUUID string) {
 		e.lo.Warn("no rules to evaluate for new conversation", "uuid", conversationUUID)
 		return
 	}
-	e.evalConversationRules(rules, conversation)
+	e.evalConversationRules(rules, conversation, models.EventConversationCreated, nil)
 }
 
 // handleUpdateConversation handles update conversation events with specific eventType.
-func (e *Engine) handleUpdateConversation(conversationUUID, eventType string) {
+func (e *Engine) handleUpdateConversation(conversationUUID, eventType string, chain []int) {
 	e.lo.Debug("handling update conversation", "uuid", conversationUUID, "event_type", eventType)
 	conversation, err := e.conversationStore.GetConversation(0, conversationUUID)
 	if err != nil {
@@ -331,33 +479,7 @@ func (e *Engine) handleUpdateConversation(conversationUUID, eventType string) {
 		e.lo.Warn("no rules to evaluate for conversation update", "uuid", conversationUUID, "event_type", eventType)
 		return
 	}
-	e.evalConversationRules(rules, conversation)
-}
-
-// handleTimeTrigger handles time trigger events.
-func (e *Engine) handleTimeTrigger() {
-	e.lo.Debug("handling time triggers")
-	thirtyDaysAgo := time.Now().Add(-30 * 24 * time.Hour)
-	conversations, err := e.conversationStore.GetConversationsCreatedAfter(thirtyDaysAgo)
-	if err != nil {
-		e.lo.Error("error fetching conversations for time trigger", "error", err)
-		return
-	}
-	rules := e.filterRulesByType(models.RuleTypeTimeTrigger, "")
-	if len(rules) == 0 {
-		e.lo.Warn("no rules to evaluate for time trigger")
-		return
-	}
-	e.lo.Debug("fetched conversations for evaluating time triggers", "conversations_count", len(conversations), "rules_count", len(rules))
-	for _, c := range conversations {
-		// Fetch entire conversation.
-		conversation, err := e.conversationStore.GetConversation(0, c.UUID)
-		if err != nil {
-			e.lo.Error("error fetching conversation for time trigger", "uuid", c.UUID, "error", err)
-			continue
-		}
-		e.evalConversationRules(rules, conversation)
-	}
+	e.evalConversationRules(rules, conversation, eventType, chain)
 }
 
 // queryRules fetches automation rules from the database.
@@ -380,9 +502,14 @@ func (e *Engine) queryRules() []models.Rule {
 		}
 		// Set values from DB.
 		for i := range rulesBatch {
+			rulesBatch[i].ID = rule.ID
+			rulesBatch[i].Name = rule.Name
+			rulesBatch[i].Version = rule.Version
 			rulesBatch[i].Type = rule.Type
 			rulesBatch[i].Events = rule.Events
 			rulesBatch[i].ExecutionMode = rule.ExecutionMode
+			rulesBatch[i].Schedule = rule.Schedule
+			rulesBatch[i].LastRunAt = rule.LastRunAt
 		}
 		filteredRules = append(filteredRules, rulesBatch...)
 	}
@@ -0,0 +1,65 @@
+package automation
+
+import (
+	"encoding/json"
+
+	"github.com/abhinavxd/libredesk/internal/automation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+const (
+	// DefaultDryRunSampleSize is the number of recent conversations a rule is tested against when no conversation is given.
+	DefaultDryRunSampleSize = 20
+	// MaxDryRunSampleSize caps the number of conversations a single dry-run evaluates.
+	MaxDryRunSampleSize = 100
+)
+
+// DryRun evaluates unsaved rules against a conversation, or against the most recent conversations when
+// conversationUUID is empty, and returns the result of every condition along with the actions that would fire.
+// No actions are applied.
+func (e *Engine) DryRun(rules json.RawMessage, conversationUUID string, sampleSize int) ([]models.DryRunResult, error) {
+	if err := ValidateRules(rules); err != nil {
+		return nil, err
+	}
+	var batch []models.Rule
+	if err := json.Unmarshal(rules, &batch); err != nil || len(batch) == 0 {
+		return nil, envelope.NewError(envelope.InputError, "Invalid rules", nil)
+	}
+
+	var uuids []string
+	if conversationUUID != "" {
+		uuids = append(uuids, conversationUUID)
+	} else {
+		if sampleSize <= 0 {
+			sampleSize = DefaultDryRunSampleSize
+		}
+		sampleSize = min(sampleSize, MaxDryRunSampleSize)
+		recent, err := e.conversationStore.GetRecentConversations(sampleSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range recent {
+			uuids = append(uuids, c.UUID)
+		}
+	}
+
+	var results = make([]models.DryRunResult, 0, len(uuids))
+	for _, uuid := range uuids {
+		conversation, err := e.conversationStore.GetConversation(0, uuid)
+		if err != nil {
+			return nil, err
+		}
+		result := models.DryRunResult{
+			ConversationUUID: conversation.UUID,
+			ReferenceNumber:  conversation.ReferenceNumber,
+			Subject:          conversation.Subject.String,
+			Rules:            make([]models.RuleEvaluation, 0, len(batch)),
+		}
+		facts := e.getConversationFacts(conversation.ID)
+		for _, rule := range batch {
+			result.Rules = append(result.Rules, e.evaluate(rule, conversation, facts))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
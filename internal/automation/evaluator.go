@@ -1,111 +1,171 @@
 package automation
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/abhinavxd/libredesk/internal/automation/models"
 	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
-	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/volatiletech/null/v9"
 )
 
 // evalConversationRules evaluates a list of rules against a given conversation.
-// If all the groups of a rule pass their evaluations based on the defined logical operations,
-// the corresponding actions are executed.
-func (e *Engine) evalConversationRules(rules []models.Rule, conversation cmodels.Conversation) {
+// If the condition tree of a rule passes its evaluation based on the defined logical operations,
+// the corresponding actions are executed, eventType is the event that triggered the evaluation and chain holds
+// the IDs of the rules whose actions caused it.
+func (e *Engine) evalConversationRules(rules []models.Rule, conversation cmodels.Conversation, eventType string, chain []int) {
+	facts := e.getConversationFacts(conversation.ID)
 	for _, rule := range rules {
 		e.lo.Debug("evaluating rule for conversation", "rule", rule, "conversation_id", conversation.ID)
 
-		// At max there can be only 2 groups.
-		if len(rule.Groups) > 2 {
-			e.lo.Warn("WARNING: more than 2 groups found for rules skipping evaluation")
-			continue
-		}
-
-		var groupEvalResults []bool
-		for idx, group := range rule.Groups {
-			if len(group.Rules) == 0 {
-				e.lo.Debug("no rules found in group, skipping rule group evaluation", "group_num", idx+1, "conversation_uuid", conversation.UUID)
+		evaluation := e.evaluate(rule, conversation, facts)
+		if evaluation.Matched {
+			if !e.withinLimits(rule, conversation, chain) {
 				continue
 			}
-			result := e.evaluateGroup(group.Rules, group.LogicalOp, conversation)
-			e.lo.Debug("group rule evaluation complete", "logical_op", group.LogicalOp, "result", result, "conversation_uuid", conversation.UUID)
-			groupEvalResults = append(groupEvalResults, result)
-		}
-
-		if evaluateFinalResult(groupEvalResults, rule.GroupOperator) {
 			e.lo.Debug("rule evaluation successful executing actions", "conversation_uuid", conversation.UUID)
-			for _, action := range rule.Actions {
-				if err := e.conversationStore.ApplyAction(action, conversation, umodels.User{}); err != nil {
-					e.lo.Error("error applying action on conversation", "action", action, "conversation_uuid", conversation.UUID, "error", err)
-				}
-			}
+			e.applyActions(rule, conversation, eventType, chain)
 			if rule.ExecutionMode == models.ExecutionModeFirstMatch {
 				e.lo.Debug("first match rule execution mode, breaking out of rule evaluation", "conversation_uuid", conversation.UUID)
 				break
 			}
 		} else {
-			e.lo.Debug("rule evaluation failed, skipping actions", "group_eval_results", groupEvalResults, "conversation_uuid", conversation.UUID)
+			e.lo.Debug("rule evaluation failed, skipping actions", "conditions", evaluation.Conditions, "conversation_uuid", conversation.UUID)
 		}
 	}
 }
 
-// evaluateFinalResult computes the final result of multiple group evaluations
-// based on the specified logical operator (AND/OR).
-func evaluateFinalResult(results []bool, operator string) bool {
-	if operator == models.OperatorAnd {
-		for _, result := range results {
-			if !result {
-				return false
+// applyActions applies the actions of a matched rule on a conversation and records the execution.
+// Actions that changed the conversation are evaluated as conversation update events, carrying the chain of rules that caused them.
+func (e *Engine) applyActions(rule models.Rule, conversation cmodels.Conversation, eventType string, chain []int) {
+	var (
+		results = make([]models.ActionResult, 0, len(rule.Actions))
+		events  []string
+	)
+	for _, action := range rule.Actions {
+		var (
+			err     error
+			changes = actionChangesState(action, conversation)
+		)
+		// Webhooks carry the triggering event so they are queued by the engine.
+		if action.Type == models.ActionSendWebhook {
+			if err = e.sendWebhook(action, conversation.UUID, eventType); err != nil {
+				e.lo.Error("error sending webhook", "conversation_uuid", conversation.UUID, "error", err)
 			}
+		} else if err = e.conversationStore.ApplyRuleAction(action, conversation, rule.Name); err != nil {
+			e.lo.Error("error applying action on conversation", "action", action, "conversation_uuid", conversation.UUID, "error", err)
 		}
-		return true
-	}
-	if operator == models.OperatorOR {
-		for _, result := range results {
-			if result {
-				return true
-			}
+		result := models.ActionResult{Type: action.Type, Value: action.Value}
+		if action.Type == models.ActionSendWebhook && len(action.Value) > 1 && action.Value[1] != "" {
+			// Keep the webhook secret out of the execution log.
+			result.Value = slices.Clone(action.Value)
+			result.Value[1] = strings.Repeat(stringutil.PasswordDummy, 10)
 		}
-		return false
+		if err != nil {
+			result.Error = err.Error()
+		} else if event, ok := actionEvent(action); ok && changes && !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+		results = append(results, result)
+	}
+	e.recordExecution(rule, conversation.ID, eventType, results)
+
+	next := append(slices.Clip(chain), rule.ID)
+	for _, event := range events {
+		e.enqueueConversationUpdate(conversation.UUID, event, next)
 	}
-	return false
 }
 
-// evaluateGroup evaluates a set of rules within a group against a given conversation
-// based on the specified logical operator (AND/OR).
-func (e *Engine) evaluateGroup(rules []models.RuleDetail, operator string, conversation cmodels.Conversation) bool {
-	switch operator {
+// getConversationFacts fetches the conversation values used by conditions that are not part of the conversation record.
+// Errors are logged and empty facts returned so the remaining conditions are still evaluated.
+func (e *Engine) getConversationFacts(conversationID int) models.ConversationFacts {
+	var facts models.ConversationFacts
+	if err := e.q.GetConversationFacts.Get(&facts, conversationID); err != nil {
+		e.lo.Error("error fetching conversation facts for rule evaluation", "conversation_id", conversationID, "error", err)
+	}
+	return facts
+}
+
+// evaluate evaluates the condition tree of a rule against a conversation and returns the result of every condition.
+// It has no side effects, actions are only listed when the rule matches.
+func (e *Engine) evaluate(rule models.Rule, conversation cmodels.Conversation, facts models.ConversationFacts) models.RuleEvaluation {
+	evaluation := models.RuleEvaluation{
+		Conditions: e.evaluateNode(rule.ConditionTree(), conversation, facts),
+		Actions:    []models.RuleAction{},
+	}
+	evaluation.Matched = evaluation.Conditions.Result
+	if evaluation.Matched {
+		evaluation.Actions = rule.Actions
+	}
+	return evaluation
+}
+
+// evaluateNode evaluates a node of the condition tree against a given conversation based on the
+// node's logical operator (AND/OR/NOT). Every child is evaluated so the result of each condition
+// is known, not only the ones needed to decide the node.
+func (e *Engine) evaluateNode(node models.ConditionNode, conversation cmodels.Conversation, facts models.ConversationFacts) models.NodeResult {
+	if node.Condition != nil {
+		condition := e.evaluateRule(*node.Condition, conversation, facts)
+		return models.NodeResult{Condition: &condition, Result: condition.Result}
+	}
+
+	var result = models.NodeResult{
+		LogicalOp: node.LogicalOp,
+		Children:  make([]models.NodeResult, 0, len(node.Children)),
+	}
+	for _, child := range node.Children {
+		result.Children = append(result.Children, e.evaluateNode(child, conversation, facts))
+	}
+
+	switch node.LogicalOp {
 	case models.OperatorAnd:
-		// All conditions within the group must be true
-		for _, rule := range rules {
-			if !e.evaluateRule(rule, conversation) {
-				return false
+		// All children must be true
+		result.Result = true
+		for _, c := range result.Children {
+			if !c.Result {
+				result.Result = false
+				break
 			}
 		}
-		return true
 	case models.OperatorOR:
-		// At least one condition within the group must be true
-		for _, rule := range rules {
-			if e.evaluateRule(rule, conversation) {
-				return true
+		// At least one child must be true
+		for _, c := range result.Children {
+			if c.Result {
+				result.Result = true
+				break
 			}
 		}
-		return false
+	case models.OperatorNot:
+		result.Result = len(result.Children) == 1 && !result.Children[0].Result
 	default:
-		e.lo.Error("invalid group operator", "operator", operator)
+		e.lo.Error("invalid group operator", "operator", node.LogicalOp)
 	}
-	return false
+	e.lo.Debug("condition group evaluation complete", "logical_op", node.LogicalOp, "result", result.Result, "conversation_uuid", conversation.UUID)
+	return result
 }
 
 // evaluateRule determines if a conversation matches the specified rule's conditions.
-func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conversation) bool {
+func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conversation, facts models.ConversationFacts) models.ConditionResult {
 	var (
 		valueToCompare string
 		ruleValues     []string
 		conditionMet   bool
+		// listValues is set for fields holding multiple values, like tags, which are matched item by item.
+		listValues []string
+		isList     bool
+		// timeValue is set for date fields, which are compared relative to now.
+		timeValue null.Time
+		result     = models.ConditionResult{
+			Field:    rule.Field,
+			Key:      rule.Key,
+			Operator: rule.Operator,
+			Value:    rule.Value,
+		}
 	)
 
 	// Extract the value from the conversation based on the rule's field
@@ -136,10 +196,71 @@ func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conve
 		}
 	case models.ConversationInbox:
 		valueToCompare = strconv.Itoa(conversation.InboxID)
+	case models.ConversationTags:
+		isList = true
+		_ = json.Unmarshal(conversation.Tags.JSON, &listValues)
+	case models.ConversationCC:
+		isList = true
+		_ = json.Unmarshal(conversation.CC, &listValues)
+	case models.ConversationCustomAttribute:
+		valueToCompare = attributeValue(facts.CustomAttributes, rule.Key)
+	case models.ContactCustomAttribute:
+		valueToCompare = attributeValue(facts.ContactCustomAttributes, rule.Key)
+	case models.ContactName:
+		valueToCompare = strings.TrimSpace(conversation.Contact.FullName())
+	case models.ContactDomain:
+		if _, domain, ok := strings.Cut(conversation.Contact.Email.String, "@"); ok {
+			valueToCompare = domain
+		}
+	case models.ContactOrganization:
+		valueToCompare = attributeValue(facts.ContactCustomAttributes, "organization")
+	case models.ConversationMessageCount:
+		valueToCompare = strconv.Itoa(facts.MessageCount)
+	case models.ConversationHoursSinceCustomerReply:
+		if facts.LastCustomerReplyAt.Valid {
+			valueToCompare = fmt.Sprintf("%.0f", time.Since(facts.LastCustomerReplyAt.Time).Hours())
+		}
+	case models.ConversationHoursSinceAgentReply:
+		if facts.LastAgentReplyAt.Valid {
+			valueToCompare = fmt.Sprintf("%.0f", time.Since(facts.LastAgentReplyAt.Time).Hours())
+		}
+	case models.ConversationSLAStatus:
+		valueToCompare = conversation.SLAStatus.String
+	case models.ConversationMinutesToSLABreach:
+		// Negative once the deadline has passed.
+		if facts.NextSLADeadlineAt.Valid {
+			valueToCompare = fmt.Sprintf("%.0f", time.Until(facts.NextSLADeadlineAt.Time).Minutes())
+		}
+	case models.ConversationLastMessageHasAttachments:
+		valueToCompare = strconv.FormatBool(facts.LastMessageHasAttachments)
+	case models.ConversationLastMessageSender:
+		valueToCompare = facts.LastMessageSenderType.String
+	case models.ConversationCreatedAt:
+		timeValue = null.TimeFrom(conversation.CreatedAt)
+	case models.ConversationResolvedAt:
+		timeValue = conversation.ResolvedAt
+	case models.ConversationLastCustomerReplyAt:
+		timeValue = facts.LastCustomerReplyAt
+	case models.ConversationLastAgentReplyAt:
+		timeValue = facts.LastAgentReplyAt
 	default:
 		e.lo.Error("unrecognized rule field", "field", rule.Field)
-		return false
+		result.Error = "unrecognized field"
+		return result
 	}
+	if isList {
+		valueToCompare = strings.Join(listValues, ", ")
+	}
+	if timeValue.Valid {
+		valueToCompare = timeValue.Time.Format(time.RFC3339)
+	}
+	result.ExtractedValue = valueToCompare
+
+	// Regex patterns are matched against the original values, lower casing a pattern changes its meaning.
+	var (
+		rawPattern = rule.Value
+		rawValue   = valueToCompare
+	)
 
 	if !rule.CaseSensitiveMatch {
 		valueToCompare = strings.ToLower(valueToCompare)
@@ -157,6 +278,36 @@ func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conve
 		}
 	}
 
+	// List fields match whole items, contains and equals match when any of the values is one of the items.
+	if isList {
+		if !rule.CaseSensitiveMatch {
+			for i := range listValues {
+				listValues[i] = strings.ToLower(listValues[i])
+			}
+		}
+		hasAny := false
+		for _, v := range strings.Split(rule.Value, ",") {
+			if slices.Contains(listValues, strings.TrimSpace(v)) {
+				hasAny = true
+				break
+			}
+		}
+		switch rule.Operator {
+		case models.RuleOperatorContains, models.RuleOperatorEquals:
+			result.Result = hasAny
+		case models.RuleOperatorNotContains, models.RuleOperatorNotEqual:
+			result.Result = !hasAny
+		case models.RuleOperatorSet:
+			result.Result = len(listValues) > 0
+		case models.RuleOperatorNotSet:
+			result.Result = len(listValues) == 0
+		default:
+			e.lo.Error("error unsupported operator for list field", "field", rule.Field, "operator", rule.Operator)
+			result.Error = "unsupported operator"
+		}
+		return result
+	}
+
 	e.lo.Debug("evaluating rule", "rule_field", rule.Field, "rule_operator", rule.Operator,
 		"rule_value", rule.Value, "rule_values", ruleValues, "value_to_compare",
 		valueToCompare, "conversation_uuid", conversation.UUID)
@@ -205,14 +356,102 @@ func (e *Engine) evaluateRule(rule models.RuleDetail, conversation cmodels.Conve
 		conditionMet = len(valueToCompare) > 0
 	case models.RuleOperatorNotSet:
 		conditionMet = len(valueToCompare) == 0
-	case models.RuleOperatorGreaterThan:
-		value1, _ := strconv.Atoi(valueToCompare)
-		value2, _ := strconv.Atoi(rule.Value)
-		conditionMet = value1 > value2
+	case models.RuleOperatorGreaterThan, models.RuleOperatorLessThan, models.RuleOperatorBetween:
+		conditionMet, result.Error = compareNumbers(valueToCompare, rule.Operator, rule.Value)
+	case models.RuleOperatorStartsWith:
+		conditionMet = strings.HasPrefix(valueToCompare, rule.Value)
+	case models.RuleOperatorEndsWith:
+		conditionMet = strings.HasSuffix(valueToCompare, rule.Value)
+	case models.RuleOperatorInList:
+		for _, v := range strings.Split(rule.Value, ",") {
+			if strings.TrimSpace(v) == valueToCompare {
+				conditionMet = true
+				break
+			}
+		}
+	case models.RuleOperatorMatchesRegex:
+		re, err := e.compileRegex(rawPattern, rule.CaseSensitiveMatch)
+		if err != nil {
+			e.lo.Error("error compiling rule regex", "pattern", rawPattern, "error", err)
+			result.Error = "invalid regex"
+			return result
+		}
+		conditionMet = re.MatchString(rawValue)
+	case models.RuleOperatorWithinLast, models.RuleOperatorNotWithinLast:
+		hours, err := strconv.Atoi(strings.TrimSpace(rule.Value))
+		if err != nil || hours <= 0 {
+			result.Error = "value is not a number of hours"
+			return result
+		}
+		// Dates that are not set are neither within nor outside the window.
+		if timeValue.Valid {
+			within := time.Since(timeValue.Time) <= time.Duration(hours)*time.Hour
+			conditionMet = within == (rule.Operator == models.RuleOperatorWithinLast)
+		}
 	default:
 		e.lo.Error("error unrecognized rule logical operator", "operator", rule.Operator)
-		return false
+		result.Error = "unrecognized operator"
+		return result
 	}
 	e.lo.Debug("conversation automation rule status", "has_met", conditionMet, "conversation_uuid", conversation.UUID)
-	return conditionMet
+	result.Result = conditionMet
+	return result
+}
+
+// attributeValue returns the value of a key in a custom attributes JSON object as a string, empty if it is not set.
+func attributeValue(attributes json.RawMessage, key string) string {
+	var values map[string]any
+	if key == "" || json.Unmarshal(attributes, &values) != nil {
+		return ""
+	}
+	switch v := values[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareNumbers compares a numeric value against the rule value for the greater than, less than and between operators.
+// A value that is not set never matches, values that are not numbers return an error instead of being read as 0.
+func compareNumbers(value, operator, ruleValue string) (bool, string) {
+	if value == "" {
+		return false, ""
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false, "value is not a number"
+	}
+	bounds, err := parseNumbers(ruleValue)
+	if err != nil {
+		return false, "rule value is not a number"
+	}
+	switch operator {
+	case models.RuleOperatorGreaterThan:
+		return len(bounds) == 1 && v > bounds[0], ""
+	case models.RuleOperatorLessThan:
+		return len(bounds) == 1 && v < bounds[0], ""
+	case models.RuleOperatorBetween:
+		if len(bounds) != 2 {
+			return false, "between requires 2 values"
+		}
+		return v >= bounds[0] && v <= bounds[1], ""
+	}
+	return false, "unrecognized operator"
+}
+
+// parseNumbers parses a comma separated list of numbers.
+func parseNumbers(s string) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	out := make([]float64, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, nil
 }
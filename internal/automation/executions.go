@@ -0,0 +1,55 @@
+package automation
+
+import (
+	"encoding/json"
+
+	"github.com/abhinavxd/libredesk/internal/automation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+// recordExecution stores an execution log entry for a rule that matched a conversation.
+func (e *Engine) recordExecution(rule models.Rule, conversationID int, eventType string, results []models.ActionResult) {
+	var hasErrors bool
+	for _, r := range results {
+		if r.Error != "" {
+			hasErrors = true
+			break
+		}
+	}
+	actions, err := json.Marshal(results)
+	if err != nil {
+		e.lo.Error("error marshalling rule execution actions", "rule_id", rule.ID, "error", err)
+		return
+	}
+	if _, err := e.q.InsertExecution.Exec(rule.ID, rule.Name, rule.Version, conversationID, eventType, actions, hasErrors); err != nil {
+		e.lo.Error("error recording rule execution", "rule_id", rule.ID, "conversation_id", conversationID, "error", err)
+	}
+}
+
+// GetExecutions returns a page of rule execution logs, newest first, filtered by rule and conversation, 0 matches all.
+func (e *Engine) GetExecutions(ruleID, conversationID, page, pageSize int) ([]models.Execution, int, error) {
+	var executions = make([]models.Execution, 0)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxExecutionsPage {
+		pageSize = maxExecutionsPage
+	}
+	if err := e.q.GetExecutions.Select(&executions, ruleID, conversationID, pageSize, (page-1)*pageSize); err != nil {
+		e.lo.Error("error fetching rule executions", "error", err)
+		return executions, pageSize, envelope.NewError(envelope.GeneralError, "Error fetching rule executions", nil)
+	}
+	return executions, pageSize, nil
+}
+
+// deleteOldExecutions deletes execution logs older than the configured retention.
+func (e *Engine) deleteOldExecutions() {
+	res, err := e.q.DeleteOldExecutions.Exec(e.logRetention.Seconds())
+	if err != nil {
+		e.lo.Error("error deleting old rule executions", "error", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		e.lo.Info("deleted old rule executions", "count", n)
+	}
+}
@@ -0,0 +1,175 @@
+package automation
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/automation/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	smodels "github.com/abhinavxd/libredesk/internal/conversation/status/models"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+)
+
+// actionEvents maps actions to the conversation update event of the change they make.
+var actionEvents = map[string]string{
+	models.ActionAssignUser:        models.EventConversationUserAssigned,
+	models.ActionAssignTeam:        models.EventConversationTeamAssigned,
+	models.ActionSetStatus:         models.EventConversationStatusChange,
+	models.ActionSnoozeFor:         models.EventConversationStatusChange,
+	models.ActionCloseConversation: models.EventConversationStatusChange,
+	models.ActionSetPriority:       models.EventConversationPriorityChange,
+	models.ActionReply:             models.EventConversationMessageOutgoing,
+}
+
+// actionEvent returns the conversation update event of the change the action makes.
+func actionEvent(action models.RuleAction) (string, bool) {
+	if action.Type == models.ActionRemoveAssignee && len(action.Value) > 0 {
+		switch action.Value[0] {
+		case cmodels.AssigneeTypeUser:
+			return models.EventConversationUserAssigned, true
+		case cmodels.AssigneeTypeTeam:
+			return models.EventConversationTeamAssigned, true
+		}
+	}
+	event, ok := actionEvents[action.Type]
+	return event, ok
+}
+
+// actionChangesState reports whether applying the action changes the conversation, actions that set a value the
+// conversation already has emit no event.
+func actionChangesState(action models.RuleAction, conversation cmodels.Conversation) bool {
+	var value int
+	if len(action.Value) > 0 {
+		value, _ = strconv.Atoi(action.Value[0])
+	}
+	switch action.Type {
+	case models.ActionAssignUser:
+		return value != conversation.AssignedUserID.Int
+	case models.ActionAssignTeam:
+		return value != conversation.AssignedTeamID.Int
+	case models.ActionSetStatus:
+		return value != conversation.StatusID.Int
+	case models.ActionSetPriority:
+		return value != conversation.PriorityID.Int
+	case models.ActionSnoozeFor:
+		return !conversation.StatusIsSnooze
+	case models.ActionCloseConversation:
+		return conversation.StatusCategory.String != smodels.CategoryClosed
+	case models.ActionRemoveAssignee:
+		if len(action.Value) > 0 && action.Value[0] == cmodels.AssigneeTypeTeam {
+			return conversation.AssignedTeamID.Valid
+		}
+		return conversation.AssignedUserID.Valid
+	}
+	return true
+}
+
+// limits protect conversations from rules that keep triggering each other or themselves.
+type limits struct {
+	// maxDepth is the number of rules that can trigger each other through their actions.
+	maxDepth int
+	// maxRuleExecutions and maxConversationExecutions cap the executions of a rule on a conversation
+	// and of all rules on a conversation in the window.
+	maxRuleExecutions         int
+	maxConversationExecutions int
+	window                    time.Duration
+}
+
+// executionCounts holds the executions on a conversation in the limit window.
+type executionCounts struct {
+	Rule         int `db:"rule"`
+	Conversation int `db:"conversation"`
+}
+
+// withinLimits reports whether a matched rule can run on a conversation, chain holds the IDs of the rules whose
+// actions caused the event being evaluated. A rule that is already in the chain, exceeds the depth limit or is over
+// the execution limits is disabled and admins are notified.
+func (e *Engine) withinLimits(rule models.Rule, conversation cmodels.Conversation, chain []int) bool {
+	// The rule may have been disabled by another worker.
+	if !e.isRuleEnabled(rule.ID) {
+		return false
+	}
+
+	if slices.Contains(chain, rule.ID) {
+		e.tripLimit(rule, conversation, chain, "it was triggered again by a change made by its own actions")
+		return false
+	}
+
+	if len(chain) >= e.limits.maxDepth {
+		e.tripLimit(rule, conversation, chain, fmt.Sprintf("more than %d rules triggered each other through their actions", e.limits.maxDepth))
+		return false
+	}
+
+	var counts executionCounts
+	if err := e.q.GetExecutionCounts.Get(&counts, rule.ID, conversation.ID, time.Now().Add(-e.limits.window)); err != nil {
+		e.lo.Error("error fetching rule execution counts", "rule_id", rule.ID, "conversation_id", conversation.ID, "error", err)
+		return true
+	}
+	if counts.Rule >= e.limits.maxRuleExecutions {
+		e.tripLimit(rule, conversation, chain, fmt.Sprintf("it ran %d times on the conversation within %s", counts.Rule, e.limits.window))
+		return false
+	}
+	if counts.Conversation >= e.limits.maxConversationExecutions {
+		e.tripLimit(rule, conversation, chain, fmt.Sprintf("rules ran %d times on the conversation within %s", counts.Conversation, e.limits.window))
+		return false
+	}
+	return true
+}
+
+// tripLimit logs a tripped loop protection limit and disables the rule.
+func (e *Engine) tripLimit(rule models.Rule, conversation cmodels.Conversation, chain []int, reason string) {
+	e.lo.Warn("automation rule tripped loop protection, disabling rule", "rule_id", rule.ID, "conversation_uuid", conversation.UUID, "chain", chain, "reason", reason)
+	e.disableRule(rule, conversation, reason)
+}
+
+// isRuleEnabled reports whether the rule is among the loaded enabled rules.
+func (e *Engine) isRuleEnabled(id int) bool {
+	e.rulesMu.RLock()
+	defer e.rulesMu.RUnlock()
+	for _, r := range e.rules {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// disableRule disables a rule that tripped loop protection and notifies the agents that can manage automations.
+func (e *Engine) disableRule(rule models.Rule, conversation cmodels.Conversation, reason string) {
+	res, err := e.q.DisableRule.Exec(rule.ID, reason)
+	if err != nil {
+		e.lo.Error("error disabling automation rule", "rule_id", rule.ID, "error", err)
+		return
+	}
+	e.ReloadRules()
+
+	// Only the worker that disabled the rule notifies.
+	if n, _ := res.RowsAffected(); n == 0 || e.notifier == nil {
+		return
+	}
+	var userIDs []int
+	if err := e.q.GetAutomationManagers.Select(&userIDs); err != nil {
+		e.lo.Error("error fetching automation managers", "error", err)
+		return
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+	content := strings.Join([]string{
+		fmt.Sprintf("The automation rule %q was disabled because %s.", rule.Name, reason),
+		fmt.Sprintf("It was disabled while running on conversation #%s.", conversation.ReferenceNumber),
+		"Review the rule and its execution log, then enable it again.",
+	}, "\n\n")
+	if err := e.notifier.Send(notifier.Message{
+		UserIDs:     userIDs,
+		Subject:     fmt.Sprintf("Automation rule %q was disabled", rule.Name),
+		Content:     content,
+		ContentType: "plain",
+		Provider:    notifier.ProviderEmail,
+	}); err != nil {
+		e.lo.Error("error sending rule disabled notification", "rule_id", rule.ID, "error", err)
+	}
+}
@@ -6,29 +6,53 @@ import (
 
 	authzModels "github.com/abhinavxd/libredesk/internal/authz/models"
 	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
 )
 
 const (
-	ActionAssignTeam      = "assign_team"
-	ActionAssignUser      = "assign_user"
-	ActionSetStatus       = "set_status"
-	ActionSetPriority     = "set_priority"
-	ActionSendPrivateNote = "send_private_note"
-	ActionReply           = "send_reply"
-	ActionSetSLA          = "set_sla"
-	ActionSetTags         = "set_tags"
-	ActionSendCSAT        = "send_csat"
+	ActionAssignTeam         = "assign_team"
+	ActionAssignUser         = "assign_user"
+	ActionSetStatus          = "set_status"
+	ActionSetPriority        = "set_priority"
+	ActionSendPrivateNote    = "send_private_note"
+	ActionReply              = "send_reply"
+	ActionSetSLA             = "set_sla"
+	ActionSetTags            = "set_tags"
+	ActionSendCSAT           = "send_csat"
+	ActionAddFollower        = "add_follower"
+	ActionSendTranscript     = "send_transcript"
+	ActionCreateTask         = "create_task"
+	ActionSendWebhook        = "send_webhook"
+	ActionAddTags            = "add_tags"
+	ActionRemoveTags         = "remove_tags"
+	ActionSnoozeFor          = "snooze_for"
+	ActionRemoveAssignee     = "remove_assignee"
+	ActionSetCustomAttribute = "set_custom_attribute"
+	ActionSendEmailTo        = "send_email_to"
+	ActionCloseConversation  = "close_conversation"
+
+	// TranscriptRecipientContact is the send_transcript action value that sends the transcript to the contact.
+	TranscriptRecipientContact = "contact"
 
 	OperatorAnd = "AND"
 	OperatorOR  = "OR"
+	OperatorNot = "NOT"
 
-	RuleOperatorContains    = "contains"
-	RuleOperatorNotContains = "not contains"
-	RuleOperatorEquals      = "equals"
-	RuleOperatorNotEqual    = "not equals"
-	RuleOperatorSet         = "set"
-	RuleOperatorNotSet      = "not set"
-	RuleOperatorGreaterThan = "greater than"
+	RuleOperatorContains      = "contains"
+	RuleOperatorNotContains   = "not contains"
+	RuleOperatorEquals        = "equals"
+	RuleOperatorNotEqual      = "not equals"
+	RuleOperatorSet           = "set"
+	RuleOperatorNotSet        = "not set"
+	RuleOperatorGreaterThan   = "greater than"
+	RuleOperatorLessThan      = "less than"
+	RuleOperatorBetween       = "between"
+	RuleOperatorStartsWith    = "starts with"
+	RuleOperatorEndsWith      = "ends with"
+	RuleOperatorInList        = "in list"
+	RuleOperatorMatchesRegex  = "matches regex"
+	RuleOperatorWithinLast    = "within last hours"
+	RuleOperatorNotWithinLast = "not within last hours"
 
 	RuleTypeNewConversation    = "new_conversation"
 	RuleTypeConversationUpdate = "conversation_update"
@@ -45,12 +69,33 @@ const (
 	ConversationInbox              = "inbox"
 	ContactEmail                   = "contact_email"
 
+	ConversationTags                      = "tags"
+	ConversationCustomAttribute           = "custom_attribute"
+	ConversationMessageCount              = "message_count"
+	ConversationHoursSinceCustomerReply   = "hours_since_last_customer_reply"
+	ConversationHoursSinceAgentReply      = "hours_since_last_agent_reply"
+	ConversationSLAStatus                 = "sla_status"
+	ConversationMinutesToSLABreach        = "minutes_to_sla_breach"
+	ConversationCC                        = "cc"
+	ConversationLastMessageHasAttachments = "last_message_has_attachments"
+	ConversationLastMessageSender         = "last_message_sender"
+	ContactName                           = "contact_name"
+	ContactDomain                         = "contact_domain"
+	ContactOrganization                   = "contact_organization"
+	ContactCustomAttribute                = "contact_custom_attribute"
+	ConversationCreatedAt                 = "created_at"
+	ConversationResolvedAt                = "resolved_at"
+	ConversationLastCustomerReplyAt       = "last_customer_reply_at"
+	ConversationLastAgentReplyAt          = "last_agent_reply_at"
+
+	EventConversationCreated         = "conversation.created"
 	EventConversationUserAssigned    = "conversation.user.assigned"
 	EventConversationTeamAssigned    = "conversation.team.assigned"
 	EventConversationStatusChange    = "conversation.status.change"
 	EventConversationPriorityChange  = "conversation.priority.change"
 	EventConversationMessageOutgoing = "conversation.message.outgoing"
 	EventConversationMessageIncoming = "conversation.message.incoming"
+	EventConversationTimeTrigger     = "conversation.time_trigger"
 
 	ExecutionModeAll        = "all"
 	ExecutionModeFirstMatch = "first_match"
@@ -58,13 +103,32 @@ const (
 
 // ActionPermissions maps actions to permissions
 var ActionPermissions = map[string]string{
-	ActionAssignTeam:      authzModels.PermConversationsUpdateTeamAssignee,
-	ActionAssignUser:      authzModels.PermConversationsUpdateUserAssignee,
-	ActionSetStatus:       authzModels.PermConversationsUpdateStatus,
-	ActionSetPriority:     authzModels.PermConversationsUpdatePriority,
-	ActionSendPrivateNote: authzModels.PermMessagesWrite,
-	ActionReply:           authzModels.PermMessagesWrite,
-	ActionSetTags:         authzModels.PermConversationsUpdateTags,
+	ActionAssignTeam:        authzModels.PermConversationsUpdateTeamAssignee,
+	ActionAssignUser:        authzModels.PermConversationsUpdateUserAssignee,
+	ActionSetStatus:         authzModels.PermConversationsUpdateStatus,
+	ActionSetPriority:       authzModels.PermConversationsUpdatePriority,
+	ActionSendPrivateNote:   authzModels.PermMessagesWrite,
+	ActionReply:             authzModels.PermMessagesWrite,
+	ActionSetTags:           authzModels.PermConversationsUpdateTags,
+	ActionAddFollower:       authzModels.PermConversationsRead,
+	ActionSendTranscript:    authzModels.PermMessagesWrite,
+	ActionAddTags:           authzModels.PermConversationsUpdateTags,
+	ActionRemoveTags:        authzModels.PermConversationsUpdateTags,
+	ActionSnoozeFor:         authzModels.PermConversationsUpdateStatus,
+	ActionCloseConversation: authzModels.PermConversationsUpdateStatus,
+	// Removing the team assignee requires the team assignee permission, see ActionPermission.
+	ActionRemoveAssignee:     authzModels.PermConversationsUpdateUserAssignee,
+	ActionSetCustomAttribute: authzModels.PermConversationsUpdateAttributes,
+	ActionSendEmailTo:        authzModels.PermMessagesWrite,
+}
+
+// ActionPermission returns the permission required to apply an action.
+func ActionPermission(action RuleAction) (string, bool) {
+	if action.Type == ActionRemoveAssignee && len(action.Value) > 0 && action.Value[0] == "team" {
+		return authzModels.PermConversationsUpdateTeamAssignee, true
+	}
+	perm, ok := ActionPermissions[action.Type]
+	return perm, ok
 }
 
 // RuleRecord represents a rule record in the database
@@ -80,15 +144,58 @@ type RuleRecord struct {
 	Weight        int             `db:"weight" json:"weight"`
 	ExecutionMode string          `db:"execution_mode" json:"execution_mode"`
 	Rules         json.RawMessage `db:"rules" json:"rules"`
+	Version       int             `db:"version" json:"version"`
+	// Schedule is the cron expression time trigger rules run on.
+	Schedule  string    `db:"schedule" json:"schedule"`
+	LastRunAt null.Time `db:"last_run_at" json:"last_run_at"`
+	// DisabledReason is set when the rule was disabled by loop protection.
+	DisabledReason string `db:"disabled_reason" json:"disabled_reason"`
 }
 
 type Rule struct {
-	Type          string       `json:"type"`
-	ExecutionMode string       `json:"execution_mode"`
-	Events        []string     `json:"event"`
-	GroupOperator string       `json:"group_operator"`
-	Groups        []RuleGroup  `json:"groups"`
-	Actions       []RuleAction `json:"actions"`
+	// ID, Name and Version identify the rule record the rule was loaded from and are not part of the rule JSON.
+	ID      int    `json:"-"`
+	Name    string `json:"-"`
+	Version int    `json:"-"`
+	// Schedule and LastRunAt are set for time trigger rules from the rule record.
+	Schedule      string      `json:"-"`
+	LastRunAt     null.Time   `json:"-"`
+	Type          string      `json:"type"`
+	ExecutionMode string      `json:"execution_mode"`
+	Events        []string    `json:"event"`
+	GroupOperator string      `json:"group_operator"`
+	Groups        []RuleGroup `json:"groups"`
+	// Conditions is a condition tree of any depth, when set it is used instead of Groups and GroupOperator.
+	Conditions *ConditionNode `json:"conditions,omitempty"`
+	Actions    []RuleAction   `json:"actions"`
+}
+
+// ConditionTree returns the condition tree of the rule. Rules saved with groups are converted to a tree
+// joining the groups with the group operator, groups without conditions are left out.
+func (r Rule) ConditionTree() ConditionNode {
+	if r.Conditions != nil {
+		return *r.Conditions
+	}
+	root := ConditionNode{LogicalOp: r.GroupOperator, Children: make([]ConditionNode, 0, len(r.Groups))}
+	for _, group := range r.Groups {
+		if len(group.Rules) == 0 {
+			continue
+		}
+		node := ConditionNode{LogicalOp: group.LogicalOp, Children: make([]ConditionNode, 0, len(group.Rules))}
+		for i := range group.Rules {
+			node.Children = append(node.Children, ConditionNode{Condition: &group.Rules[i]})
+		}
+		root.Children = append(root.Children, node)
+	}
+	return root
+}
+
+// ConditionNode is a node of a rule's condition tree. A node is either a single condition or a group of
+// nodes joined by AND / OR, a NOT group negates its only child.
+type ConditionNode struct {
+	LogicalOp string          `json:"logical_op,omitempty"`
+	Children  []ConditionNode `json:"children,omitempty"`
+	Condition *RuleDetail     `json:"condition,omitempty"`
 }
 
 type RuleGroup struct {
@@ -97,7 +204,9 @@ type RuleGroup struct {
 }
 
 type RuleDetail struct {
-	Field              string `json:"field" db:"field"`
+	Field string `json:"field" db:"field"`
+	// Key is the attribute name for custom attribute fields.
+	Key                string `json:"key,omitempty" db:"key"`
 	Operator           string `json:"operator" db:"operator"`
 	Value              string `json:"value" db:"value"`
 	CaseSensitiveMatch bool   `json:"case_sensitive_match" db:"case_sensitive_match"`
@@ -108,3 +217,73 @@ type RuleAction struct {
 	Value        []string `json:"value" db:"value"`
 	DisplayValue []string `json:"display_value" db:"-"`
 }
+
+// ConditionResult is the outcome of evaluating a single condition against a conversation.
+type ConditionResult struct {
+	Field          string `json:"field"`
+	Key            string `json:"key,omitempty"`
+	Operator       string `json:"operator"`
+	Value          string `json:"value"`
+	ExtractedValue string `json:"extracted_value"`
+	Result         bool   `json:"result"`
+	Error          string `json:"error,omitempty"`
+}
+
+// NodeResult is the outcome of evaluating a node of the condition tree.
+type NodeResult struct {
+	LogicalOp string           `json:"logical_op,omitempty"`
+	Condition *ConditionResult `json:"condition,omitempty"`
+	Children  []NodeResult     `json:"children,omitempty"`
+	Result    bool             `json:"result"`
+}
+
+// RuleEvaluation is the outcome of evaluating a rule against a conversation.
+type RuleEvaluation struct {
+	Conditions NodeResult `json:"conditions"`
+	Matched    bool       `json:"matched"`
+	// Actions are the actions that fire when the rule matched.
+	Actions []RuleAction `json:"actions"`
+}
+
+// DryRunResult holds the rule evaluations for a single conversation.
+type DryRunResult struct {
+	ConversationUUID string           `json:"conversation_uuid"`
+	ReferenceNumber  string           `json:"reference_number"`
+	Subject          string           `json:"subject"`
+	Rules            []RuleEvaluation `json:"rules"`
+}
+
+// ActionResult is the outcome of applying an action of a matched rule.
+type ActionResult struct {
+	Type  string   `json:"type"`
+	Value []string `json:"value"`
+	Error string   `json:"error,omitempty"`
+}
+
+// Execution is an execution log entry recorded every time a rule matches a conversation.
+type Execution struct {
+	ID                          int             `db:"id" json:"id"`
+	CreatedAt                   time.Time       `db:"created_at" json:"created_at"`
+	RuleID                      null.Int        `db:"rule_id" json:"rule_id"`
+	RuleName                    string          `db:"rule_name" json:"rule_name"`
+	RuleVersion                 int             `db:"rule_version" json:"rule_version"`
+	ConversationID              int             `db:"conversation_id" json:"conversation_id"`
+	ConversationUUID            string          `db:"conversation_uuid" json:"conversation_uuid"`
+	ConversationReferenceNumber string          `db:"conversation_reference_number" json:"conversation_reference_number"`
+	Event                       string          `db:"event" json:"event"`
+	Actions                     json.RawMessage `db:"actions" json:"actions"`
+	HasErrors                   bool            `db:"has_errors" json:"has_errors"`
+	Total                       int             `db:"total" json:"-"`
+}
+
+// ConversationFacts holds the conversation values used by rule conditions that are not part of the conversation record.
+type ConversationFacts struct {
+	CustomAttributes          json.RawMessage `db:"custom_attributes"`
+	ContactCustomAttributes   json.RawMessage `db:"contact_custom_attributes"`
+	NextSLADeadlineAt         null.Time       `db:"next_sla_deadline_at"`
+	MessageCount              int             `db:"message_count"`
+	LastCustomerReplyAt       null.Time       `db:"last_customer_reply_at"`
+	LastAgentReplyAt          null.Time       `db:"last_agent_reply_at"`
+	LastMessageSenderType     null.String     `db:"last_message_sender_type"`
+	LastMessageHasAttachments bool            `db:"last_message_has_attachments"`
+}
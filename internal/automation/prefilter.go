@@ -0,0 +1,144 @@
+package automation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/automation/models"
+)
+
+// prefilterColumns maps the conversation ID fields to the SQL expression holding the value compared by the evaluator.
+var prefilterColumns = map[string]string{
+	models.ConversationStatus:       "c.status_id::TEXT",
+	models.ConversationPriority:     "COALESCE(c.priority_id, 0)::TEXT",
+	models.ConversationInbox:        "c.inbox_id::TEXT",
+	models.ConversationAssignedTeam: "COALESCE(c.assigned_team_id::TEXT, '')",
+	models.ConversationAssignedUser: "COALESCE(c.assigned_user_id::TEXT, '')",
+}
+
+// prefilterHours maps the hours since fields to their conversation column.
+var prefilterHours = map[string]string{
+	models.ConversationHoursSinceCreated:  "c.created_at",
+	models.ConversationHoursSinceResolved: "c.resolved_at",
+}
+
+// prefilterDates maps the date fields to their conversation column.
+var prefilterDates = map[string]string{
+	models.ConversationCreatedAt:  "c.created_at",
+	models.ConversationResolvedAt: "c.resolved_at",
+}
+
+// buildPrefilter turns the parts of a condition tree that can be checked on the conversations table into a SQL
+// condition used to select the conversations a time trigger rule is evaluated on. The condition matches every
+// conversation the tree can match and possibly more, the rule is still evaluated on each selected conversation.
+// Argument placeholders start after argOffset. It returns false when nothing in the tree can be pushed down.
+func buildPrefilter(node models.ConditionNode, argOffset int) (string, []interface{}, bool) {
+	var args []interface{}
+	clause, ok := prefilterNode(node, argOffset, &args)
+	return clause, args, ok
+}
+
+func prefilterNode(node models.ConditionNode, argOffset int, args *[]interface{}) (string, bool) {
+	if node.Condition != nil {
+		return prefilterCondition(*node.Condition, argOffset, args)
+	}
+
+	var clauses []string
+	switch node.LogicalOp {
+	case models.OperatorAnd:
+		// Leaving out a condition of an AND group only widens the selection.
+		for _, child := range node.Children {
+			if clause, ok := prefilterNode(child, argOffset, args); ok {
+				clauses = append(clauses, clause)
+			}
+		}
+		if len(clauses) == 0 {
+			return "", false
+		}
+		return "(" + strings.Join(clauses, " AND ") + ")", true
+	case models.OperatorOR:
+		// Every child of an OR group must be pushed down, otherwise the group can match anything.
+		n := len(*args)
+		for _, child := range node.Children {
+			clause, ok := prefilterNode(child, argOffset, args)
+			if !ok {
+				*args = (*args)[:n]
+				return "", false
+			}
+			clauses = append(clauses, clause)
+		}
+		if len(clauses) == 0 {
+			return "", false
+		}
+		return "(" + strings.Join(clauses, " OR ") + ")", true
+	}
+	// NOT groups are left to the evaluator.
+	return "", false
+}
+
+// prefilterCondition returns the SQL condition for a single rule condition, false if the condition cannot be
+// checked on the conversations table.
+func prefilterCondition(cond models.RuleDetail, argOffset int, args *[]interface{}) (string, bool) {
+	placeholder := func(v interface{}) string {
+		*args = append(*args, v)
+		return fmt.Sprintf("$%d", argOffset+len(*args))
+	}
+
+	if column, ok := prefilterColumns[cond.Field]; ok {
+		switch cond.Operator {
+		case models.RuleOperatorEquals:
+			return fmt.Sprintf("%s = %s", column, placeholder(cond.Value)), true
+		case models.RuleOperatorNotEqual:
+			return fmt.Sprintf("%s != %s", column, placeholder(cond.Value)), true
+		case models.RuleOperatorSet:
+			return fmt.Sprintf("%s != ''", column), true
+		case models.RuleOperatorNotSet:
+			return fmt.Sprintf("%s = ''", column), true
+		}
+		return "", false
+	}
+
+	if column, ok := prefilterHours[cond.Field]; ok {
+		switch cond.Operator {
+		case models.RuleOperatorGreaterThan, models.RuleOperatorLessThan:
+			hours, err := strconv.ParseFloat(strings.TrimSpace(cond.Value), 64)
+			if err != nil {
+				return "", false
+			}
+			// Widen by half an hour for the rounding.
+			op, secs := "<=", (hours-0.5)*3600
+			if cond.Operator == models.RuleOperatorLessThan {
+				op, secs = ">", (hours+0.5)*3600
+			}
+			return fmt.Sprintf("%s %s NOW() - make_interval(secs => %s)", column, op, placeholder(secs)), true
+		case models.RuleOperatorSet:
+			return column + " IS NOT NULL", true
+		case models.RuleOperatorNotSet:
+			return column + " IS NULL", true
+		}
+		return "", false
+	}
+
+	column, ok := prefilterDates[cond.Field]
+	if !ok {
+		return "", false
+	}
+	switch cond.Operator {
+	case models.RuleOperatorWithinLast, models.RuleOperatorNotWithinLast:
+		hours, err := strconv.Atoi(strings.TrimSpace(cond.Value))
+		if err != nil || hours <= 0 {
+			return "", false
+		}
+		op := ">="
+		if cond.Operator == models.RuleOperatorNotWithinLast {
+			op = "<"
+		}
+		return fmt.Sprintf("%s %s NOW() - make_interval(hours => %s)", column, op, placeholder(hours)), true
+	case models.RuleOperatorSet:
+		return column + " IS NOT NULL", true
+	case models.RuleOperatorNotSet:
+		return column + " IS NULL", true
+	}
+	return "", false
+}
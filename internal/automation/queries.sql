@@ -1,20 +1,25 @@
 -- name: get-enabled-rules
 select
+    id,
+    name,
+    version,
     type,
     events,
     rules,
-    execution_mode
+    execution_mode,
+    schedule,
+    last_run_at
 from automation_rules where enabled is TRUE ORDER BY weight ASC;
 
 -- name: get-all
-SELECT id, created_at, updated_at, enabled, name, description, type, events, rules, execution_mode from automation_rules where type = $1 ORDER BY weight ASC;
+SELECT id, created_at, updated_at, enabled, name, description, type, events, rules, execution_mode, version, schedule, last_run_at, disabled_reason from automation_rules where type = $1 ORDER BY weight ASC;
 
 -- name: get-rule
-SELECT id, created_at, updated_at, enabled, name, description, type, events, rules, execution_mode from automation_rules where id = $1;
+SELECT id, created_at, updated_at, enabled, name, description, type, events, rules, execution_mode, version, schedule, last_run_at, disabled_reason from automation_rules where id = $1;
 
 -- name: update-rule
-INSERT INTO automation_rules(id, name, description, type, events, rules, enabled)
-VALUES($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO automation_rules(id, name, description, type, events, rules, enabled, schedule)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8)
 ON CONFLICT (id)
 DO UPDATE SET
     name = EXCLUDED.name,
@@ -23,20 +28,28 @@ DO UPDATE SET
     events = EXCLUDED.events,
     rules = EXCLUDED.rules,
     enabled = EXCLUDED.enabled,
+    schedule = EXCLUDED.schedule,
+    disabled_reason = CASE WHEN EXCLUDED.enabled THEN '' ELSE automation_rules.disabled_reason END,
+    version = automation_rules.version + 1,
     updated_at = now()
 WHERE $1 > 0;
 
 -- name: insert-rule
-INSERT into automation_rules (name, description, type, events, rules) values ($1, $2, $3, $4, $5);
+INSERT into automation_rules (name, description, type, events, rules, schedule) values ($1, $2, $3, $4, $5, $6);
 
 -- name: delete-rule
 delete from automation_rules where id = $1;
 
 -- name: toggle-rule
 UPDATE automation_rules 
-SET enabled = NOT enabled, updated_at = NOW() 
+SET enabled = NOT enabled, disabled_reason = '', updated_at = NOW() 
 WHERE id = $1;
 
+-- name: disable-rule
+UPDATE automation_rules
+SET enabled = FALSE, disabled_reason = $2, updated_at = NOW()
+WHERE id = $1 AND enabled = TRUE;
+
 -- name: update-rule-weight
 UPDATE automation_rules
 SET weight = $2, updated_at = NOW()
@@ -45,4 +58,107 @@ WHERE id = $1;
 -- name: update-rule-execution-mode
 UPDATE automation_rules
 SET execution_mode = $2, updated_at = NOW()
-WHERE type = $1;
\ No newline at end of file
+WHERE type = $1;
+
+-- name: update-rule-last-run
+UPDATE automation_rules SET last_run_at = $2 WHERE id = $1;
+
+-- name: get-time-trigger-candidates
+-- Conversations a time trigger rule is evaluated on, in batches by ID. Conversations the rule already fired on are
+-- skipped until the rule is changed or the contact sends a new message. %s is replaced with the rule's SQL pre-filter.
+-- $1 rule ID, $2 rule version, $3 last conversation ID of the previous batch, $4 batch size.
+SELECT c.id, c.uuid
+FROM conversations c
+WHERE c.id > $3
+AND NOT EXISTS (
+    SELECT 1 FROM automation_rule_triggers t
+    WHERE t.rule_id = $1 AND t.conversation_id = c.id AND t.rule_version = $2
+    AND NOT EXISTS (
+        SELECT 1 FROM conversation_messages m
+        WHERE m.conversation_id = c.id AND m.type = 'incoming' AND m.created_at > t.triggered_at
+    )
+)
+%s
+ORDER BY c.id
+LIMIT $4;
+
+-- name: upsert-rule-trigger
+INSERT INTO automation_rule_triggers (rule_id, conversation_id, rule_version, triggered_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (rule_id, conversation_id)
+DO UPDATE SET rule_version = EXCLUDED.rule_version, triggered_at = EXCLUDED.triggered_at;
+
+-- name: insert-execution
+INSERT INTO automation_rule_executions (rule_id, rule_name, rule_version, conversation_id, event, actions, has_errors)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: get-executions
+-- $1 rule ID, $2 conversation ID, 0 matches all.
+SELECT
+    COUNT(*) OVER() AS total,
+    e.id,
+    e.created_at,
+    e.rule_id,
+    e.rule_name,
+    e.rule_version,
+    e.conversation_id,
+    c.uuid AS conversation_uuid,
+    c.reference_number AS conversation_reference_number,
+    e.event,
+    e.actions,
+    e.has_errors
+FROM automation_rule_executions e
+JOIN conversations c ON c.id = e.conversation_id
+WHERE ($1 = 0 OR e.rule_id = $1)
+AND ($2 = 0 OR e.conversation_id = $2)
+ORDER BY e.created_at DESC
+LIMIT $3 OFFSET $4;
+
+-- name: delete-old-executions
+DELETE FROM automation_rule_executions WHERE created_at < NOW() - make_interval(secs => $1);
+
+-- name: get-execution-counts
+-- Executions of a rule and of all rules on a conversation since $3.
+SELECT
+    COUNT(*) FILTER (WHERE rule_id = $1) AS rule,
+    COUNT(*) AS conversation
+FROM automation_rule_executions
+WHERE conversation_id = $2 AND created_at > $3;
+
+-- name: get-automation-managers
+-- Agents that can manage automations, notified when a rule is disabled.
+SELECT DISTINCT u.id
+FROM users u
+JOIN user_roles ur ON ur.user_id = u.id
+JOIN roles r ON r.id = ur.role_id
+WHERE u.type = 'agent' AND u.enabled AND u.deleted_at IS NULL AND u.email != 'System'
+AND 'automations:manage' = ANY(r.permissions);
+
+-- name: get-conversation-facts
+SELECT
+    c.custom_attributes,
+    ct.custom_attributes AS contact_custom_attributes,
+    c.next_sla_deadline_at,
+    (SELECT COUNT(*) FROM conversation_messages m
+        WHERE m.conversation_id = c.id AND m.type IN ('incoming', 'outgoing') AND m.private = false AND m.side_thread_id IS NULL) AS message_count,
+    (SELECT MAX(m.created_at) FROM conversation_messages m
+        WHERE m.conversation_id = c.id AND m.type = 'incoming' AND m.side_thread_id IS NULL) AS last_customer_reply_at,
+    (SELECT MAX(m.created_at) FROM conversation_messages m
+        WHERE m.conversation_id = c.id AND m.type = 'outgoing' AND m.private = false AND m.side_thread_id IS NULL) AS last_agent_reply_at,
+    lm.sender_type AS last_message_sender_type,
+    COALESCE(lm.has_attachments, false) AS last_message_has_attachments
+FROM conversations c
+JOIN users ct ON ct.id = c.contact_id
+LEFT JOIN LATERAL (
+    SELECT
+        m.sender_type,
+        EXISTS (
+            SELECT 1 FROM media
+            WHERE media.model_type = 'messages' AND media.model_id = m.id AND media.disposition = 'attachment'
+        ) AS has_attachments
+    FROM conversation_messages m
+    WHERE m.conversation_id = c.id AND m.type IN ('incoming', 'outgoing') AND m.side_thread_id IS NULL
+    ORDER BY m.created_at DESC
+    LIMIT 1
+) lm ON true
+WHERE c.id = $1;
@@ -0,0 +1,158 @@
+package automation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeTriggerSchedule is the schedule of time trigger rules saved without one, every hour.
+const DefaultTimeTriggerSchedule = "0 * * * *"
+
+// maxScheduleLookahead bounds the search for the next run of a schedule, expressions like `0 0 30 2 *` never match.
+const maxScheduleLookahead = 366 * 24 * time.Hour
+
+// cronField holds the allowed range of a cron expression field.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// schedule is a parsed five field cron expression: minute, hour, day of month, month and day of week.
+type schedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	// anyDay and anyWeekday are set when the field is `*`. As in cron, when both day fields are
+	// restricted a time matches if either of them matches.
+	anyDay, anyWeekday bool
+}
+
+// parseSchedule parses a five field cron expression. Each field supports `*`, numbers, ranges (`1-5`),
+// steps (`*/15`, `0-30/10`) and comma separated lists of these. Day of week is 0-6 starting on Sunday, 7 is also Sunday.
+func parseSchedule(expr string) (schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return schedule{}, fmt.Errorf("schedule must have %d fields: minute, hour, day of month, month and day of week", len(cronFields))
+	}
+	sets := make([]map[int]bool, len(parts))
+	for i, part := range parts {
+		field := cronFields[i]
+		if i == 4 {
+			// Accept 7 as Sunday.
+			field.max = 7
+		}
+		set, err := parseCronField(part, field)
+		if err != nil {
+			return schedule{}, err
+		}
+		sets[i] = set
+	}
+	if sets[4][7] {
+		sets[4][0] = true
+	}
+	return schedule{
+		minutes:    sets[0],
+		hours:      sets[1],
+		days:       sets[2],
+		months:     sets[3],
+		weekdays:   sets[4],
+		anyDay:     parts[2] == "*",
+		anyWeekday: parts[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a single field of a cron expression into the set of values it matches.
+func parseCronField(s string, field cronField) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, item := range strings.Split(s, ",") {
+		var (
+			rng  = item
+			step = 1
+		)
+		if r, st, ok := strings.Cut(item, "/"); ok {
+			n, err := strconv.Atoi(st)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step `%s` in %s", st, field.name)
+			}
+			rng, step = r, n
+		}
+
+		lo, hi := field.min, field.max
+		if rng != "*" {
+			var err error
+			if a, b, ok := strings.Cut(rng, "-"); ok {
+				if lo, err = strconv.Atoi(a); err != nil {
+					return nil, fmt.Errorf("invalid value `%s` in %s", a, field.name)
+				}
+				if hi, err = strconv.Atoi(b); err != nil {
+					return nil, fmt.Errorf("invalid value `%s` in %s", b, field.name)
+				}
+			} else {
+				if lo, err = strconv.Atoi(rng); err != nil {
+					return nil, fmt.Errorf("invalid value `%s` in %s", rng, field.name)
+				}
+				hi = lo
+				// `5/10` starts at 5 and runs to the end of the range.
+				if step > 1 {
+					hi = field.max
+				}
+			}
+		}
+		if lo < field.min || hi > field.max || lo > hi {
+			return nil, fmt.Errorf("%s must be between %d and %d", field.name, field.min, field.max)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// next returns the first time after t that matches the schedule, in t's location.
+// The zero time is returned if nothing matches within a year.
+func (s schedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.Add(maxScheduleLookahead)
+	for t.Before(end) {
+		if !s.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the day of t matches the day of month and day of week fields.
+func (s schedule) matchesDay(t time.Time) bool {
+	day, weekday := s.days[t.Day()], s.weekdays[int(t.Weekday())]
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
@@ -0,0 +1,123 @@
+package automation
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/automation/models"
+	"github.com/volatiletech/null/v9"
+)
+
+// timeTriggerCandidate is a conversation selected for evaluating a time trigger rule.
+type timeTriggerCandidate struct {
+	ID   int    `db:"id"`
+	UUID string `db:"uuid"`
+}
+
+// dueTimeTriggerRules returns the IDs of the time trigger rules whose schedule is due at now and marks them as run.
+// Rules that have never run are due at the first scheduled time after they are loaded.
+func (e *Engine) dueTimeTriggerRules(now time.Time) []int {
+	var ruleIDs []int
+	e.rulesMu.Lock()
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if rule.Type != models.RuleTypeTimeTrigger {
+			continue
+		}
+		if !rule.LastRunAt.Valid {
+			rule.LastRunAt = null.TimeFrom(now)
+			continue
+		}
+		expr := rule.Schedule
+		if expr == "" {
+			expr = DefaultTimeTriggerSchedule
+		}
+		sched, err := parseSchedule(expr)
+		if err != nil {
+			e.lo.Error("error parsing time trigger rule schedule", "rule_id", rule.ID, "schedule", expr, "error", err)
+			continue
+		}
+		next := sched.next(rule.LastRunAt.Time)
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+		rule.LastRunAt = null.TimeFrom(now)
+		if !slices.Contains(ruleIDs, rule.ID) {
+			ruleIDs = append(ruleIDs, rule.ID)
+		}
+	}
+	e.rulesMu.Unlock()
+
+	// Persist the run so missed runs are caught up after a restart.
+	for _, id := range ruleIDs {
+		if _, err := e.q.UpdateRuleLastRun.Exec(id, now); err != nil {
+			e.lo.Error("error updating time trigger rule last run", "rule_id", id, "error", err)
+		}
+	}
+	return ruleIDs
+}
+
+// handleTimeTrigger evaluates the given time trigger rules in order of their weight.
+func (e *Engine) handleTimeTrigger(ruleIDs []int) {
+	e.lo.Debug("handling time triggers", "rule_ids", ruleIDs)
+	rules := e.filterRulesByType(models.RuleTypeTimeTrigger, "")
+	// Conversations matched in this run, skipped by the following rules in first match execution mode.
+	matched := make(map[int]bool)
+	for _, rule := range rules {
+		if slices.Contains(ruleIDs, rule.ID) {
+			e.evalTimeTriggerRule(rule, matched)
+		}
+	}
+}
+
+// evalTimeTriggerRule evaluates a time trigger rule on the conversations selected by its SQL pre-filter, in batches.
+// Conversations the rule matches are recorded so the rule does not fire on them again until the rule is changed
+// or the contact sends a new message.
+func (e *Engine) evalTimeTriggerRule(rule models.Rule, matched map[int]bool) {
+	var (
+		query      = fmt.Sprintf(e.q.GetTimeTriggerCandidates, "")
+		filterArgs []interface{}
+		lastID     = 0
+		evaluated  = 0
+	)
+	// The first 4 arguments are the rule ID, rule version, last conversation ID and batch size.
+	if filter, args, ok := buildPrefilter(rule.ConditionTree(), 4); ok {
+		query = fmt.Sprintf(e.q.GetTimeTriggerCandidates, "AND "+filter)
+		filterArgs = args
+	}
+
+	for {
+		var candidates []timeTriggerCandidate
+		args := append([]interface{}{rule.ID, rule.Version, lastID, timeTriggerBatchSize}, filterArgs...)
+		if err := e.db.Select(&candidates, query, args...); err != nil {
+			e.lo.Error("error fetching conversations for time trigger", "rule_id", rule.ID, "error", err)
+			return
+		}
+		for _, c := range candidates {
+			lastID = c.ID
+			if rule.ExecutionMode == models.ExecutionModeFirstMatch && matched[c.ID] {
+				continue
+			}
+			conversation, err := e.conversationStore.GetConversation(0, c.UUID)
+			if err != nil {
+				e.lo.Error("error fetching conversation for time trigger", "uuid", c.UUID, "error", err)
+				continue
+			}
+			evaluated++
+			evaluation := e.evaluate(rule, conversation, e.getConversationFacts(conversation.ID))
+			if !evaluation.Matched || !e.withinLimits(rule, conversation, nil) {
+				continue
+			}
+			e.applyActions(rule, conversation, models.EventConversationTimeTrigger, nil)
+			if _, err := e.q.UpsertRuleTrigger.Exec(rule.ID, conversation.ID, rule.Version); err != nil {
+				e.lo.Error("error recording time trigger", "rule_id", rule.ID, "conversation_id", conversation.ID, "error", err)
+			}
+			matched[c.ID] = true
+		}
+		if len(candidates) < timeTriggerBatchSize {
+			break
+		}
+	}
+	e.lo.Debug("time trigger rule evaluated", "rule_id", rule.ID, "conversations_count", evaluated)
+}
@@ -0,0 +1,175 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/automation/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+)
+
+// Condition field types, they decide the operators a condition can use.
+const (
+	fieldTypeText    = "text"
+	fieldTypeID      = "id"
+	fieldTypeNumber  = "number"
+	fieldTypeList    = "list"
+	fieldTypeBoolean = "boolean"
+	fieldTypeDate    = "date"
+)
+
+var (
+	fieldTypes = map[string]string{
+		models.ContactEmail:                          fieldTypeText,
+		models.ConversationSubject:                   fieldTypeText,
+		models.ConversationContent:                   fieldTypeText,
+		models.ConversationStatus:                    fieldTypeID,
+		models.ConversationPriority:                  fieldTypeID,
+		models.ConversationAssignedTeam:              fieldTypeID,
+		models.ConversationAssignedUser:              fieldTypeID,
+		models.ConversationHoursSinceCreated:         fieldTypeNumber,
+		models.ConversationHoursSinceResolved:        fieldTypeNumber,
+		models.ConversationInbox:                     fieldTypeID,
+		models.ConversationTags:                      fieldTypeList,
+		models.ConversationCustomAttribute:           fieldTypeText,
+		models.ConversationMessageCount:              fieldTypeNumber,
+		models.ConversationHoursSinceCustomerReply:   fieldTypeNumber,
+		models.ConversationHoursSinceAgentReply:      fieldTypeNumber,
+		models.ConversationSLAStatus:                 fieldTypeID,
+		models.ConversationMinutesToSLABreach:        fieldTypeNumber,
+		models.ConversationCC:                        fieldTypeList,
+		models.ConversationLastMessageHasAttachments: fieldTypeBoolean,
+		models.ConversationLastMessageSender:         fieldTypeID,
+		models.ContactName:                           fieldTypeText,
+		models.ContactDomain:                         fieldTypeText,
+		models.ContactOrganization:                   fieldTypeText,
+		models.ContactCustomAttribute:                fieldTypeText,
+		models.ConversationCreatedAt:                 fieldTypeDate,
+		models.ConversationResolvedAt:                fieldTypeDate,
+		models.ConversationLastCustomerReplyAt:       fieldTypeDate,
+		models.ConversationLastAgentReplyAt:          fieldTypeDate,
+	}
+
+	typeOperators = map[string][]string{
+		fieldTypeText: {
+			models.RuleOperatorEquals, models.RuleOperatorNotEqual, models.RuleOperatorSet, models.RuleOperatorNotSet,
+			models.RuleOperatorContains, models.RuleOperatorNotContains, models.RuleOperatorStartsWith,
+			models.RuleOperatorEndsWith, models.RuleOperatorInList, models.RuleOperatorMatchesRegex,
+		},
+		fieldTypeID: {
+			models.RuleOperatorEquals, models.RuleOperatorNotEqual, models.RuleOperatorSet, models.RuleOperatorNotSet,
+			models.RuleOperatorInList,
+		},
+		fieldTypeNumber: {
+			models.RuleOperatorEquals, models.RuleOperatorNotEqual, models.RuleOperatorSet, models.RuleOperatorNotSet,
+			models.RuleOperatorGreaterThan, models.RuleOperatorLessThan, models.RuleOperatorBetween,
+		},
+		fieldTypeList: {
+			models.RuleOperatorEquals, models.RuleOperatorNotEqual, models.RuleOperatorSet, models.RuleOperatorNotSet,
+			models.RuleOperatorContains, models.RuleOperatorNotContains,
+		},
+		fieldTypeBoolean: {
+			models.RuleOperatorEquals, models.RuleOperatorNotEqual,
+		},
+		fieldTypeDate: {
+			models.RuleOperatorSet, models.RuleOperatorNotSet, models.RuleOperatorWithinLast, models.RuleOperatorNotWithinLast,
+		},
+	}
+)
+
+// ValidateRules validates the condition tree of a rule definition, checking the groups are well formed,
+// each operator is supported by its field and the value can be used by the operator.
+func ValidateRules(rules json.RawMessage) error {
+	var batch []models.Rule
+	if err := json.Unmarshal(rules, &batch); err != nil {
+		return envelope.NewError(envelope.InputError, "Invalid rules", nil)
+	}
+	for _, rule := range batch {
+		if err := validateNode(rule.ConditionTree()); err != nil {
+			return envelope.NewError(envelope.InputError, err.Error(), nil)
+		}
+	}
+	return nil
+}
+
+// ValidateSchedule validates the cron schedule of a rule, only time trigger rules can have a schedule.
+func ValidateSchedule(ruleType, expr string) error {
+	if expr == "" {
+		return nil
+	}
+	if ruleType != models.RuleTypeTimeTrigger {
+		return envelope.NewError(envelope.InputError, "Only time trigger rules can have a schedule.", nil)
+	}
+	sched, err := parseSchedule(expr)
+	if err != nil {
+		return envelope.NewError(envelope.InputError, fmt.Sprintf("Invalid schedule: %s.", err), nil)
+	}
+	if sched.next(time.Now()).IsZero() {
+		return envelope.NewError(envelope.InputError, "Invalid schedule: it never runs.", nil)
+	}
+	return nil
+}
+
+// validateNode validates a node of the condition tree and all its children.
+func validateNode(node models.ConditionNode) error {
+	if node.Condition != nil {
+		if len(node.Children) > 0 {
+			return fmt.Errorf("A condition cannot have child conditions")
+		}
+		return validateCondition(*node.Condition)
+	}
+	switch node.LogicalOp {
+	case models.OperatorAnd, models.OperatorOR:
+	case models.OperatorNot:
+		if len(node.Children) != 1 {
+			return fmt.Errorf("A NOT group must have exactly one child")
+		}
+	default:
+		return fmt.Errorf("Invalid group operator `%s`", node.LogicalOp)
+	}
+	for _, child := range node.Children {
+		if err := validateNode(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCondition validates a single condition.
+func validateCondition(c models.RuleDetail) error {
+	typ, ok := fieldTypes[c.Field]
+	if !ok {
+		return fmt.Errorf("Unknown condition field `%s`", c.Field)
+	}
+	if !slices.Contains(typeOperators[typ], c.Operator) {
+		return fmt.Errorf("Operator `%s` is not supported for field `%s`", c.Operator, c.Field)
+	}
+	if (c.Field == models.ConversationCustomAttribute || c.Field == models.ContactCustomAttribute) && c.Key == "" {
+		return fmt.Errorf("Attribute key is required for field `%s`", c.Field)
+	}
+
+	switch c.Operator {
+	case models.RuleOperatorGreaterThan, models.RuleOperatorLessThan:
+		if n, err := parseNumbers(c.Value); err != nil || len(n) != 1 {
+			return fmt.Errorf("`%s` requires a number for field `%s`", c.Operator, c.Field)
+		}
+	case models.RuleOperatorBetween:
+		if n, err := parseNumbers(c.Value); err != nil || len(n) != 2 || n[0] > n[1] {
+			return fmt.Errorf("`between` requires two comma separated numbers, lowest first, for field `%s`", c.Field)
+		}
+	case models.RuleOperatorWithinLast, models.RuleOperatorNotWithinLast:
+		if n, err := strconv.Atoi(strings.TrimSpace(c.Value)); err != nil || n <= 0 {
+			return fmt.Errorf("`%s` requires a number of hours for field `%s`", c.Operator, c.Field)
+		}
+	case models.RuleOperatorMatchesRegex:
+		if err := dbutil.ValidateRegex(c.Value); err != nil {
+			return fmt.Errorf("Invalid regex for field `%s`: %v", c.Field, err)
+		}
+	}
+	return nil
+}
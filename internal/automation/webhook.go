@@ -0,0 +1,177 @@
+package automation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/automation/models"
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
+)
+
+// webhookPayload is the default body of the send webhook action and the data passed to a body template.
+type webhookPayload struct {
+	Event        string               `json:"event"`
+	Timestamp    time.Time            `json:"timestamp"`
+	Conversation cmodels.Conversation `json:"conversation"`
+	Contact      umodels.User         `json:"contact"`
+}
+
+var webhookTmplFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// sendWebhook queues the send webhook action for delivery.
+// Action values are the URL, the optional HMAC secret, optional headers as "Name: Value" lines and an optional body template.
+func (e *Engine) sendWebhook(action models.RuleAction, conversationUUID, eventType string) error {
+	if e.webhookStore == nil {
+		return fmt.Errorf("webhook store not set")
+	}
+	var values [4]string
+	copy(values[:], action.Value)
+	var (
+		rawURL  = strings.TrimSpace(values[0])
+		secret  = values[1]
+		headers = parseWebhookHeaders(values[2])
+		body    = values[3]
+	)
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q", rawURL)
+	}
+
+	// Fetch the conversation again so changes made by the preceding actions of the rule are sent.
+	conversation, err := e.conversationStore.GetConversation(0, conversationUUID)
+	if err != nil {
+		return err
+	}
+	payload := webhookPayload{
+		Event:        eventType,
+		Timestamp:    time.Now(),
+		Conversation: conversation,
+		Contact:      conversation.Contact,
+	}
+
+	b, err := renderWebhookBody(body, payload)
+	if err != nil {
+		return err
+	}
+	return e.webhookStore.Enqueue(wmodels.Request{
+		Event:            eventType,
+		URL:              rawURL,
+		Headers:          headers,
+		Secret:           secret,
+		Payload:          b,
+		ConversationUUID: conversation.UUID,
+	})
+}
+
+// renderWebhookBody renders the body template with the payload, an empty template sends the payload as JSON.
+func renderWebhookBody(body string, payload webhookPayload) ([]byte, error) {
+	if strings.TrimSpace(body) == "" {
+		return json.Marshal(payload)
+	}
+	tmpl, err := template.New("webhook").Funcs(webhookTmplFuncs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing webhook body template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("executing webhook body template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseWebhookHeaders parses "Name: Value" lines, lines without a name are ignored.
+func parseWebhookHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if name = strings.TrimSpace(name); !ok || name == "" {
+			continue
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+// maskWebhookSecrets replaces the secrets of the send webhook actions in the rules JSON with dummy characters.
+func maskWebhookSecrets(rules json.RawMessage) json.RawMessage {
+	var batch []map[string]any
+	if err := json.Unmarshal(rules, &batch); err != nil {
+		return rules
+	}
+	actions := webhookActions(batch)
+	if len(actions) == 0 {
+		return rules
+	}
+	for _, values := range actions {
+		if secret, _ := values[1].(string); secret != "" {
+			values[1] = strings.Repeat(stringutil.PasswordDummy, 10)
+		}
+	}
+	b, err := json.Marshal(batch)
+	if err != nil {
+		return rules
+	}
+	return b
+}
+
+// keepWebhookSecrets replaces the masked secrets of the send webhook actions in the rules JSON with the
+// secrets of the stored rules, matched by the webhook URL.
+func keepWebhookSecrets(rules, stored json.RawMessage) (json.RawMessage, error) {
+	var batch, storedBatch []map[string]any
+	if err := json.Unmarshal(rules, &batch); err != nil {
+		return rules, err
+	}
+	json.Unmarshal(stored, &storedBatch)
+
+	var (
+		actions = webhookActions(batch)
+		secrets = make(map[any]any)
+		masked  = false
+	)
+	for _, values := range webhookActions(storedBatch) {
+		secrets[values[0]] = values[1]
+	}
+	for _, values := range actions {
+		if secret, _ := values[1].(string); strings.Contains(secret, stringutil.PasswordDummy) {
+			values[1] = secrets[values[0]]
+			if values[1] == nil {
+				values[1] = ""
+			}
+			masked = true
+		}
+	}
+	if !masked {
+		return rules, nil
+	}
+	return json.Marshal(batch)
+}
+
+// webhookActions returns the values of the send webhook actions that have a secret value.
+func webhookActions(batch []map[string]any) [][]any {
+	var out [][]any
+	for _, rule := range batch {
+		actions, _ := rule["actions"].([]any)
+		for _, a := range actions {
+			action, _ := a.(map[string]any)
+			if action == nil || action["type"] != models.ActionSendWebhook {
+				continue
+			}
+			if values, _ := action["value"].([]any); len(values) > 1 {
+				out = append(out, values)
+			}
+		}
+	}
+	return out
+}
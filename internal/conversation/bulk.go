@@ -0,0 +1,146 @@
+package conversation
+
+import (
+	"errors"
+	"time"
+
+	amodels "github.com/abhinavxd/libredesk/internal/automation/models"
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	wsmodels "github.com/abhinavxd/libredesk/internal/ws/models"
+	"github.com/google/uuid"
+	"github.com/volatiletech/null/v9"
+)
+
+const (
+	// bulkJobRetention is how long finished bulk jobs are kept in memory for their results to be fetched.
+	bulkJobRetention = time.Hour
+)
+
+// StartBulkJob applies the actions to each of the conversations in the background and returns the created job.
+// canAccess is called for every conversation before any action is applied to it.
+// Progress is pushed to the user over websocket after each conversation is processed.
+func (m *Manager) StartBulkJob(user umodels.User, conversationUUIDs []string, actions []amodels.RuleAction, canAccess func(models.Conversation) bool) (models.BulkJob, error) {
+	if len(conversationUUIDs) == 0 {
+		return models.BulkJob{}, envelope.NewError(envelope.InputError, "No conversations selected", nil)
+	}
+	if len(conversationUUIDs) > models.MaxBulkConversations {
+		return models.BulkJob{}, envelope.NewError(envelope.InputError, "Too many conversations selected", nil)
+	}
+	if len(actions) == 0 {
+		return models.BulkJob{}, envelope.NewError(envelope.InputError, "No actions to apply", nil)
+	}
+
+	job := &models.BulkJob{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Status:    models.BulkJobStatusRunning,
+		Total:     len(conversationUUIDs),
+		Results:   make([]models.BulkJobResult, 0, len(conversationUUIDs)),
+		CreatedAt: time.Now(),
+	}
+
+	// Don't start new jobs once the manager is shutting down.
+	m.closedMu.Lock()
+	defer m.closedMu.Unlock()
+	if m.closed {
+		return models.BulkJob{}, envelope.NewError(envelope.GeneralError, "Server is shutting down", nil)
+	}
+
+	m.bulkJobsMu.Lock()
+	m.pruneBulkJobs()
+	m.bulkJobs[job.ID] = job
+	snapshot := m.snapshotBulkJob(job, false)
+	m.bulkJobsMu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.runBulkJob(job, user, conversationUUIDs, actions, canAccess)
+	}()
+
+	return snapshot, nil
+}
+
+// GetBulkJob returns a bulk job started by the user.
+func (m *Manager) GetBulkJob(id string, userID int) (models.BulkJob, error) {
+	m.bulkJobsMu.Lock()
+	defer m.bulkJobsMu.Unlock()
+	job, ok := m.bulkJobs[id]
+	if !ok || job.UserID != userID {
+		return models.BulkJob{}, envelope.NewError(envelope.NotFoundError, "Bulk job not found", nil)
+	}
+	return m.snapshotBulkJob(job, true), nil
+}
+
+// runBulkJob applies the actions to each conversation one by one and records the result.
+func (m *Manager) runBulkJob(job *models.BulkJob, user umodels.User, conversationUUIDs []string, actions []amodels.RuleAction, canAccess func(models.Conversation) bool) {
+	for _, conversationUUID := range conversationUUIDs {
+		result := models.BulkJobResult{ConversationUUID: conversationUUID}
+		if err := m.applyBulkActions(conversationUUID, user, actions, canAccess); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Success = true
+		}
+
+		m.bulkJobsMu.Lock()
+		job.Processed++
+		if result.Success {
+			job.Succeeded++
+		} else {
+			job.Failed++
+		}
+		job.Results = append(job.Results, result)
+		done := job.Processed == job.Total
+		if done {
+			job.Status = models.BulkJobStatusCompleted
+			job.CompletedAt = null.TimeFrom(time.Now())
+		}
+		// Send results only with the final update to keep progress messages small.
+		snapshot := m.snapshotBulkJob(job, done)
+		m.bulkJobsMu.Unlock()
+
+		m.broadcastToUsers([]int{user.ID}, wsmodels.Message{
+			Type: wsmodels.MessageTypeBulkJobProgress,
+			Data: snapshot,
+		})
+	}
+	m.lo.Info("bulk job completed", "id", job.ID, "user_id", user.ID, "succeeded", job.Succeeded, "failed", job.Failed)
+}
+
+// applyBulkActions applies all actions to a single conversation, stopping at the first failed action.
+func (m *Manager) applyBulkActions(conversationUUID string, user umodels.User, actions []amodels.RuleAction, canAccess func(models.Conversation) bool) error {
+	conversation, err := m.GetConversation(0, conversationUUID)
+	if err != nil {
+		return err
+	}
+	if !canAccess(conversation) {
+		return errors.New("permission denied")
+	}
+	for _, action := range actions {
+		if err := m.ApplyAction(action, conversation, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotBulkJob returns a copy of the job that is safe to use without holding the lock, caller must hold bulkJobsMu.
+func (m *Manager) snapshotBulkJob(job *models.BulkJob, withResults bool) models.BulkJob {
+	snapshot := *job
+	snapshot.Results = []models.BulkJobResult{}
+	if withResults {
+		snapshot.Results = append(snapshot.Results, job.Results...)
+	}
+	return snapshot
+}
+
+// pruneBulkJobs removes finished jobs older than the retention period, caller must hold bulkJobsMu.
+func (m *Manager) pruneBulkJobs() {
+	for id, job := range m.bulkJobs {
+		if job.CompletedAt.Valid && time.Since(job.CompletedAt.Time) > bulkJobRetention {
+			delete(m.bulkJobs, id)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/mail"
 	"slices"
 	"strconv"
 	"strings"
@@ -28,9 +29,11 @@ import (
 	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
 	notifier "github.com/abhinavxd/libredesk/internal/notification"
 	slaModels "github.com/abhinavxd/libredesk/internal/sla/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
 	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
 	"github.com/abhinavxd/libredesk/internal/template"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/abhinavxd/libredesk/internal/ws"
 	"github.com/jmoiron/sqlx"
 	"github.com/knadh/go-i18n"
@@ -43,7 +46,7 @@ var (
 	//go:embed queries.sql
 	efs                                  embed.FS
 	errConversationNotFound              = errors.New("conversation not found")
-	conversationsListAllowedFilterFields = []string{"status_id", "priority_id", "assigned_team_id", "assigned_user_id", "inbox_id"}
+	conversationsListAllowedFilterFields = []string{"status_id", "priority_id", "assigned_team_id", "assigned_user_id", "inbox_id", "subject", "reference_number", "created_at", "last_message_at"}
 	conversationStatusesFilterFields     = []string{"id", "name"}
 	csatReplyMessage                     = "Please rate your experience with us: <a href=\"%s\">Rate now</a>"
 )
@@ -64,6 +67,7 @@ type Manager struct {
 	slaStore                   slaStore
 	settingsStore              settingsStore
 	csatStore                  csatStore
+	webhookStore               webhookStore
 	notifier                   *notifier.Service
 	lo                         *logf.Logger
 	db                         *sqlx.DB
@@ -74,6 +78,10 @@ type Manager struct {
 	incomingMessageQueue       chan models.IncomingMessage
 	outgoingMessageQueue       chan models.Message
 	outgoingProcessingMessages sync.Map
+	mentionAccessDuration      time.Duration
+	undoSendWindow             time.Duration
+	bulkJobs                   map[string]*models.BulkJob
+	bulkJobsMu                 sync.Mutex
 	closed                     bool
 	closedMu                   sync.RWMutex
 	wg                         sync.WaitGroup
@@ -81,14 +89,21 @@ type Manager struct {
 
 type slaStore interface {
 	ApplySLA(startTime time.Time, conversationID, assignedTeamID, slaID int) (slaModels.SLAPolicy, error)
+	NextBusinessDayStart(start time.Time, assignedTeamID int) (time.Time, error)
+	PauseSLA(conversationID int) error
+	ResumeSLA(conversationID int) error
 }
 
 type statusStore interface {
 	Get(int) (smodels.Status, error)
+	GetByName(string) (smodels.Status, error)
+	GetByCategory(string) (smodels.Status, error)
+	GetSnooze() (smodels.Status, error)
 }
 
 type priorityStore interface {
 	Get(int) (pmodels.Priority, error)
+	GetByName(string) (pmodels.Priority, error)
 }
 
 type teamStore interface {
@@ -125,12 +140,20 @@ type csatStore interface {
 	MakePublicURL(appBaseURL, uuid string) string
 }
 
+type webhookStore interface {
+	TriggerConversationEvent(event string, conversationID int, conversationUUID string, data map[string]any)
+}
+
 // Opts holds the options for creating a new Manager.
 type Opts struct {
 	DB                       *sqlx.DB
 	Lo                       *logf.Logger
 	OutgoingMessageQueueSize int
 	IncomingMessageQueueSize int
+	// UndoSendWindow delays sending replies so agents can cancel or edit them, zero sends immediately.
+	UndoSendWindow time.Duration
+	// MentionAccessDuration is how long mentioned agents can read a conversation they otherwise have no access to.
+	MentionAccessDuration time.Duration
 }
 
 // New initializes a new conversation Manager.
@@ -177,17 +200,36 @@ func New(
 		incomingMessageQueue:       make(chan models.IncomingMessage, opts.IncomingMessageQueueSize),
 		outgoingMessageQueue:       make(chan models.Message, opts.OutgoingMessageQueueSize),
 		outgoingProcessingMessages: sync.Map{},
+		mentionAccessDuration:      opts.MentionAccessDuration,
+		undoSendWindow:             opts.UndoSendWindow,
+		bulkJobs:                   make(map[string]*models.BulkJob),
+	}
+	if c.mentionAccessDuration <= 0 {
+		c.mentionAccessDuration = defaultMentionAccessDuration
 	}
 
 	return c, nil
 }
 
+// SetWebhookStore sets the webhook store that conversation events are sent to.
+func (c *Manager) SetWebhookStore(store webhookStore) {
+	c.webhookStore = store
+}
+
+// triggerWebhook sends a conversation event to the subscribed webhooks.
+func (c *Manager) triggerWebhook(event string, conversationID int, conversationUUID string, data map[string]any) {
+	if c.webhookStore == nil {
+		return
+	}
+	c.webhookStore.TriggerConversationEvent(event, conversationID, conversationUUID, data)
+}
+
 type queries struct {
 	// Conversation queries.
 	GetToAddress                       *sqlx.Stmt `query:"get-to-address"`
 	GetConversationUUID                *sqlx.Stmt `query:"get-conversation-uuid"`
 	GetConversation                    *sqlx.Stmt `query:"get-conversation"`
-	GetConversationsCreatedAfter       *sqlx.Stmt `query:"get-conversations-created-after"`
+	GetRecentConversations             *sqlx.Stmt `query:"get-recent-conversations"`
 	GetUnassignedConversations         *sqlx.Stmt `query:"get-unassigned-conversations"`
 	GetConversations                   string     `query:"get-conversations"`
 	GetContactConversations            *sqlx.Stmt `query:"get-contact-conversations"`
@@ -198,9 +240,11 @@ type queries struct {
 	UpdateConversationAssignedUser     *sqlx.Stmt `query:"update-conversation-assigned-user"`
 	UpdateConversationAssignedTeam     *sqlx.Stmt `query:"update-conversation-assigned-team"`
 	RemoveConversationAssignee         *sqlx.Stmt `query:"remove-conversation-assignee"`
+	SetConversationCustomAttribute     *sqlx.Stmt `query:"set-conversation-custom-attribute"`
 	UpdateConversationPriority         *sqlx.Stmt `query:"update-conversation-priority"`
 	UpdateConversationStatus           *sqlx.Stmt `query:"update-conversation-status"`
 	UpdateConversationLastMessage      *sqlx.Stmt `query:"update-conversation-last-message"`
+	ClearConversationWaitingSince      *sqlx.Stmt `query:"clear-conversation-waiting-since"`
 	InsertConversationParticipant      *sqlx.Stmt `query:"insert-conversation-participant"`
 	InsertConversation                 *sqlx.Stmt `query:"insert-conversation"`
 	UpsertConversationTags             *sqlx.Stmt `query:"upsert-conversation-tags"`
@@ -210,6 +254,24 @@ type queries struct {
 	UnsnoozeAll                        *sqlx.Stmt `query:"unsnooze-all"`
 	DeleteConversation                 *sqlx.Stmt `query:"delete-conversation"`
 
+	// Follower queries.
+	InsertConversationFollower *sqlx.Stmt `query:"insert-conversation-follower"`
+	DeleteConversationFollower *sqlx.Stmt `query:"delete-conversation-follower"`
+	GetConversationFollowers   *sqlx.Stmt `query:"get-conversation-followers"`
+	GetConversationFollowerIDs *sqlx.Stmt `query:"get-conversation-follower-ids"`
+
+	// Mention queries.
+	GetMentionables           *sqlx.Stmt `query:"get-mentionables"`
+	InsertConversationMention *sqlx.Stmt `query:"insert-conversation-mention"`
+	GetMentionedUserIDs       *sqlx.Stmt `query:"get-mentioned-user-ids"`
+	HasActiveMention          *sqlx.Stmt `query:"has-active-mention"`
+	GetUserMentions           *sqlx.Stmt `query:"get-user-mentions"`
+
+	// Draft queries.
+	GetConversationDraft    *sqlx.Stmt `query:"get-conversation-draft"`
+	UpsertConversationDraft *sqlx.Stmt `query:"upsert-conversation-draft"`
+	DeleteConversationDraft *sqlx.Stmt `query:"delete-conversation-draft"`
+
 	// Dashboard queries.
 	GetDashboardCharts string `query:"get-dashboard-charts"`
 	GetDashboardCounts string `query:"get-dashboard-counts"`
@@ -217,6 +279,8 @@ type queries struct {
 	// Message queries.
 	GetMessage                         *sqlx.Stmt `query:"get-message"`
 	GetMessages                        string     `query:"get-messages"`
+	GetTranscriptMessages              *sqlx.Stmt `query:"get-transcript-messages"`
+	GetForwardMessages                 *sqlx.Stmt `query:"get-forward-messages"`
 	GetPendingMessages                 *sqlx.Stmt `query:"get-pending-messages"`
 	GetMessageSourceIDs                *sqlx.Stmt `query:"get-message-source-ids"`
 	GetConversationUUIDFromMessageUUID *sqlx.Stmt `query:"get-conversation-uuid-from-message-uuid"`
@@ -224,6 +288,28 @@ type queries struct {
 	UpdateMessageStatus                *sqlx.Stmt `query:"update-message-status"`
 	MessageExistsBySourceID            *sqlx.Stmt `query:"message-exists-by-source-id"`
 	GetConversationByMessageID         *sqlx.Stmt `query:"get-conversation-by-message-id"`
+	HasAgentReplySince                 *sqlx.Stmt `query:"has-agent-reply-since"`
+	UpdateScheduledMessage             *sqlx.Stmt `query:"update-scheduled-message"`
+	DeleteScheduledMessage             *sqlx.Stmt `query:"delete-scheduled-message"`
+
+	// Side thread queries.
+	InsertSideThread          *sqlx.Stmt `query:"insert-side-thread"`
+	GetSideThreads            *sqlx.Stmt `query:"get-side-threads"`
+	GetSideThread             *sqlx.Stmt `query:"get-side-thread"`
+	GetSideThreadMessages     *sqlx.Stmt `query:"get-side-thread-messages"`
+	GetSideThreadSourceIDs    *sqlx.Stmt `query:"get-side-thread-source-ids"`
+	GetSideThreadIDBySourceID *sqlx.Stmt `query:"get-side-thread-id-by-source-id"`
+	UpdateSideThreadUpdatedAt *sqlx.Stmt `query:"update-side-thread-updated-at"`
+
+	// Task queries.
+	GetTasks             *sqlx.Stmt `query:"get-tasks"`
+	GetTask              *sqlx.Stmt `query:"get-task"`
+	GetUserTasks         *sqlx.Stmt `query:"get-user-tasks"`
+	InsertTask           *sqlx.Stmt `query:"insert-task"`
+	UpdateTask           *sqlx.Stmt `query:"update-task"`
+	UpdateTaskDone       *sqlx.Stmt `query:"update-task-done"`
+	DeleteTask           *sqlx.Stmt `query:"delete-task"`
+	MarkDueTasksNotified *sqlx.Stmt `query:"mark-due-tasks-notified"`
 }
 
 // CreateConversation creates a new conversation and returns its ID and UUID.
@@ -233,7 +319,11 @@ func (c *Manager) CreateConversation(contactID, contactChannelID, inboxID int, l
 		uuid   string
 		prefix string
 	)
-	if err := c.q.InsertConversation.QueryRow(contactID, contactChannelID, models.StatusOpen, inboxID, lastMessage, lastMessageAt, subject, prefix, appendRefNumToSubject).Scan(&id, &uuid); err != nil {
+	status, err := c.statusStore.GetByCategory(smodels.CategoryOpen)
+	if err != nil {
+		return id, uuid, err
+	}
+	if err := c.q.InsertConversation.QueryRow(contactID, contactChannelID, status.ID, inboxID, lastMessage, lastMessageAt, subject, prefix, appendRefNumToSubject).Scan(&id, &uuid); err != nil {
 		c.lo.Error("error inserting new conversation into the DB", "error", err)
 		return id, uuid, err
 	}
@@ -263,12 +353,12 @@ func (c *Manager) GetContactConversations(contactID int) ([]models.Conversation,
 	return conversations, nil
 }
 
-// GetConversationsCreatedAfter retrieves conversations created after the specified time.
-func (c *Manager) GetConversationsCreatedAfter(time time.Time) ([]models.Conversation, error) {
+// GetRecentConversations retrieves the most recently created conversations.
+func (c *Manager) GetRecentConversations(limit int) ([]models.Conversation, error) {
 	var conversations = make([]models.Conversation, 0)
-	if err := c.q.GetConversationsCreatedAfter.Select(&conversations, time); err != nil {
-		c.lo.Error("error fetching conversation", "error", err)
-		return conversations, err
+	if err := c.q.GetRecentConversations.Select(&conversations, limit); err != nil {
+		c.lo.Error("error fetching recent conversations", "error", err)
+		return conversations, envelope.NewError(envelope.GeneralError, "Error fetching conversations", nil)
 	}
 	return conversations, nil
 }
@@ -369,22 +459,33 @@ func (c *Manager) GetConversations(userID int, teamIDs []int, listTypes []string
 	return conversations, nil
 }
 
-// ReOpenConversation reopens a conversation if it's snoozed, resolved or closed.
+// ReOpenConversation reopens a conversation if its status is not in the open category, e.g. snoozed, resolved or closed.
 func (c *Manager) ReOpenConversation(conversationUUID string, actor umodels.User) error {
-	rows, err := c.q.ReOpenConversation.Exec(conversationUUID)
+	status, err := c.statusStore.GetByCategory(smodels.CategoryOpen)
 	if err != nil {
+		return err
+	}
+	var ids []int
+	if err := c.q.ReOpenConversation.Select(&ids, conversationUUID, status.ID); err != nil {
 		c.lo.Error("error reopening conversation", "uuid", conversationUUID, "error", err)
 		return envelope.NewError(envelope.GeneralError, "Error reopening conversation", nil)
 	}
 
 	// Record the status change as an activity if the conversation was reopened.
-	count, _ := rows.RowsAffected()
-	if count > 0 {
+	if len(ids) > 0 {
+		// Resume the SLA clock if it was paused.
+		if err := c.slaStore.ResumeSLA(ids[0]); err != nil {
+			c.lo.Error("error resuming SLA", "uuid", conversationUUID, "error", err)
+		}
+
 		// Broadcast update using WS
-		c.BroadcastConversationUpdate(conversationUUID, "status", models.StatusOpen)
+		c.BroadcastConversationUpdate(conversationUUID, "status", status.Name)
+
+		// Trigger webhook for the status change.
+		c.triggerWebhook(wmodels.EventConversationStatusChange, ids[0], conversationUUID, nil)
 
 		// Record the status change as an activity.
-		if err := c.RecordStatusChange(models.StatusOpen, conversationUUID, actor); err != nil {
+		if err := c.RecordStatusChange(status.Name, conversationUUID, actor); err != nil {
 			return err
 		}
 	}
@@ -441,6 +542,8 @@ func (c *Manager) UpdateConversationUserAssignee(uuid string, assigneeID int, ac
 		c.lo.Error("error sending assigned conversation email", "error", err)
 	}
 
+	c.triggerWebhook(wmodels.EventConversationUserAssigned, conversation.ID, uuid, nil)
+
 	if err := c.RecordAssigneeUserChange(uuid, assigneeID, actor); err != nil {
 		return envelope.NewError(envelope.GeneralError, "Error recording assignee change", nil)
 	}
@@ -459,6 +562,7 @@ func (c *Manager) UpdateConversationTeamAssignee(uuid string, teamID int, actor
 	if err := c.UpdateAssignee(uuid, teamID, models.AssigneeTypeTeam); err != nil {
 		return envelope.NewError(envelope.GeneralError, "Error updating assignee", nil)
 	}
+	c.triggerWebhook(wmodels.EventConversationTeamAssigned, conversation.ID, uuid, nil)
 
 	// Assignment successful, any errors now are non-critical and can be ignored by returning nil.
 	if err := c.RecordAssigneeTeamChange(uuid, teamID, actor); err != nil {
@@ -516,14 +620,21 @@ func (c *Manager) UpdateAssignee(uuid string, assigneeID int, assigneeType strin
 
 // UpdateConversationPriority updates the priority of a conversation.
 func (c *Manager) UpdateConversationPriority(uuid string, priorityID int, priority string, actor umodels.User) error {
-	// Fetch the priority name if priority ID is provided.
+	// Fetch the priority by ID if provided, else by name.
+	var (
+		p   pmodels.Priority
+		err error
+	)
 	if priorityID > 0 {
-		p, err := c.priorityStore.Get(priorityID)
-		if err != nil {
-			return envelope.NewError(envelope.InputError, err.Error(), nil)
-		}
-		priority = p.Name
+		p, err = c.priorityStore.Get(priorityID)
+	} else {
+		p, err = c.priorityStore.GetByName(priority)
+	}
+	if err != nil {
+		return envelope.NewError(envelope.InputError, err.Error(), nil)
 	}
+	priority = p.Name
+
 	if _, err := c.q.UpdateConversationPriority.Exec(uuid, priority); err != nil {
 		c.lo.Error("error updating conversation priority", "error", err)
 		return envelope.NewError(envelope.GeneralError, "Error updating priority", nil)
@@ -532,41 +643,73 @@ func (c *Manager) UpdateConversationPriority(uuid string, priorityID int, priori
 		return envelope.NewError(envelope.GeneralError, "Error recording priority change", nil)
 	}
 	c.BroadcastConversationUpdate(uuid, "priority", priority)
+	c.BroadcastConversationUpdate(uuid, "priority_rank", p.Rank)
+	c.triggerWebhook(wmodels.EventConversationPriorityChange, 0, uuid, nil)
+
+	// Apply the SLA policy of the priority unless the conversation already has it.
+	if p.SLAPolicyID.Valid {
+		conversation, err := c.GetConversation(0, uuid)
+		if err != nil {
+			return err
+		}
+		if conversation.SLAPolicyID.Int != p.SLAPolicyID.Int {
+			if err := c.ApplySLA(conversation, p.SLAPolicyID.Int, actor); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
 // UpdateConversationStatus updates the status of a conversation.
-func (c *Manager) UpdateConversationStatus(uuid string, statusID int, status, snoozeDur string, actor umodels.User) error {
-	// Fetch the status name if status ID is provided.
+// snoozeUntil is a duration, an RFC3339 time, "next_business_day" or "customer_reply" and is only used when the status is snoozed.
+func (c *Manager) UpdateConversationStatus(uuid string, statusID int, status, snoozeUntil string, actor umodels.User) error {
+	// Fetch the status by ID if provided else by name, the category of the status drives the conversation timestamps and SLA.
+	var (
+		s   smodels.Status
+		err error
+	)
 	if statusID > 0 {
-		s, err := c.statusStore.Get(statusID)
-		if err != nil {
-			return envelope.NewError(envelope.InputError, err.Error(), nil)
-		}
-		status = s.Name
+		s, err = c.statusStore.Get(statusID)
+	} else {
+		s, err = c.statusStore.GetByName(status)
 	}
+	if err != nil {
+		return envelope.NewError(envelope.InputError, err.Error(), nil)
+	}
+	status = s.Name
 
-	if status == models.StatusSnoozed && snoozeDur == "" {
+	if s.IsSnooze && snoozeUntil == "" {
 		return envelope.NewError(envelope.InputError, "Snooze duration is required", nil)
 	}
 
-	// Parse the snooze duration if status is snoozed.
-	snoozeUntil := time.Time{}
-	if status == models.StatusSnoozed {
-		duration, err := time.ParseDuration(snoozeDur)
-		if err != nil {
-			c.lo.Error("error parsing snooze duration", "error", err)
-			return envelope.NewError(envelope.InputError, "Invalid snooze duration format", nil)
+	// Get the wake up time if status is snoozed, no time means snoozed until the customer replies.
+	wakeAt := null.Time{}
+	if s.IsSnooze {
+		var err error
+		if wakeAt, err = c.getSnoozeWakeTime(uuid, snoozeUntil); err != nil {
+			return err
 		}
-		snoozeUntil = time.Now().Add(duration)
 	}
 
 	// Update the conversation status.
-	if _, err := c.q.UpdateConversationStatus.Exec(uuid, status, snoozeUntil); err != nil {
+	if _, err := c.q.UpdateConversationStatus.Exec(uuid, s.ID, wakeAt, s.Category, s.IsSnooze); err != nil {
 		c.lo.Error("error updating conversation status", "error", err)
 		return envelope.NewError(envelope.GeneralError, "Error updating status", nil)
 	}
 
+	// Pause the SLA clock while the conversation is pending, e.g. waiting on the customer or a vendor, and resume it otherwise.
+	if conversation, err := c.GetConversation(0, uuid); err == nil {
+		if s.Category == smodels.CategoryPending {
+			err = c.slaStore.PauseSLA(conversation.ID)
+		} else {
+			err = c.slaStore.ResumeSLA(conversation.ID)
+		}
+		if err != nil {
+			c.lo.Error("error updating SLA clock", "uuid", uuid, "error", err)
+		}
+	}
+
 	// Record the status change as an activity.
 	if err := c.RecordStatusChange(status, uuid, actor); err != nil {
 		return envelope.NewError(envelope.GeneralError, "Error recording status change", nil)
@@ -574,6 +717,9 @@ func (c *Manager) UpdateConversationStatus(uuid string, statusID int, status, sn
 
 	// Broadcast updates using websocket.
 	c.BroadcastConversationUpdate(uuid, "status", status)
+	c.triggerWebhook(wmodels.EventConversationStatusChange, 0, uuid, nil)
+
+	c.notifyFollowers(uuid, FollowerEventStatusChange, fmt.Sprintf("%s changed the status to %s.", actor.FullName(), status), "", actor.ID)
 	return nil
 }
 
@@ -683,6 +829,67 @@ func (t *Manager) UpsertConversationTags(uuid string, tagNames []string, actor u
 	return nil
 }
 
+// AddConversationTags adds tags to a conversation, keeping its existing tags.
+func (t *Manager) AddConversationTags(uuid string, tagNames []string, actor umodels.User) error {
+	tags, err := t.getConversationTags(uuid)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tagNames {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return t.UpsertConversationTags(uuid, tags, actor)
+}
+
+// RemoveConversationTags removes tags from a conversation and records each removed tag as an activity.
+func (t *Manager) RemoveConversationTags(uuid string, tagNames []string, actor umodels.User) error {
+	tags, err := t.getConversationTags(uuid)
+	if err != nil {
+		return err
+	}
+	var (
+		remaining = []string{}
+		removed   = []string{}
+	)
+	for _, tag := range tags {
+		if slices.Contains(tagNames, tag) {
+			removed = append(removed, tag)
+			continue
+		}
+		remaining = append(remaining, tag)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if _, err := t.q.UpsertConversationTags.Exec(uuid, pq.Array(remaining)); err != nil {
+		t.lo.Error("error upserting conversation tags", "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error upserting tags", nil)
+	}
+	for _, tag := range removed {
+		if err := t.RecordTagRemove(uuid, tag, actor); err != nil {
+			return envelope.NewError(envelope.GeneralError, "Error recording tag change", nil)
+		}
+	}
+	return nil
+}
+
+// SetConversationCustomAttribute sets a custom attribute on a conversation, leaving its other attributes as is.
+func (t *Manager) SetConversationCustomAttribute(uuid, key, value string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return envelope.NewError(envelope.InputError, "Attribute key is required", nil)
+	}
+	if _, err := t.q.SetConversationCustomAttribute.Exec(uuid, key, value); err != nil {
+		t.lo.Error("error setting conversation custom attribute", "uuid", uuid, "key", key, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error setting custom attribute", nil)
+	}
+	t.BroadcastConversationUpdate(uuid, "custom_attributes", "")
+	return nil
+}
+
 // getConversationTags retrieves the tags associated with a conversation.
 func (t *Manager) getConversationTags(uuid string) ([]string, error) {
 	var tags []string
@@ -847,6 +1054,51 @@ func (m *Manager) SendAssignedConversationEmail(userIDs []int, conversation mode
 	return nil
 }
 
+// SendTemplateEmail renders a stored email template with the conversation and contact and sends it to the passed addresses.
+func (m *Manager) SendTemplateEmail(conversation models.Conversation, to []string, templateName string) error {
+	to = stringutil.RemoveEmpty(to)
+	if len(to) == 0 {
+		return envelope.NewError(envelope.InputError, "Email recipients are required", nil)
+	}
+	for i, addr := range to {
+		to[i] = strings.TrimSpace(addr)
+		if _, err := mail.ParseAddress(to[i]); err != nil {
+			return envelope.NewError(envelope.InputError, fmt.Sprintf("Invalid email address `%s`", to[i]), nil)
+		}
+	}
+
+	content, subject, err := m.template.RenderStoredEmailTemplate(templateName,
+		map[string]any{
+			"Conversation": map[string]any{
+				"ReferenceNumber": conversation.ReferenceNumber,
+				"Subject":         conversation.Subject.String,
+				"Priority":        conversation.Priority.String,
+				"Status":          conversation.Status.String,
+				"UUID":            conversation.UUID,
+			},
+			"Contact": map[string]any{
+				"FirstName": conversation.Contact.FirstName,
+				"LastName":  conversation.Contact.LastName,
+				"FullName":  conversation.Contact.FullName(),
+				"Email":     conversation.Contact.Email,
+			},
+		})
+	if err != nil {
+		m.lo.Error("error rendering template", "template", templateName, "conversation_uuid", conversation.UUID, "error", err)
+		return fmt.Errorf("rendering template: %w", err)
+	}
+	if err := m.notifier.Send(notifier.Message{
+		To:       to,
+		Subject:  subject,
+		Content:  content,
+		Provider: notifier.ProviderEmail,
+	}); err != nil {
+		m.lo.Error("error sending template email", "template", templateName, "conversation_uuid", conversation.UUID, "error", err)
+		return fmt.Errorf("sending template email: %w", err)
+	}
+	return nil
+}
+
 // UnassignOpen unassigns all open conversations belonging to a user.
 // i.e conversations without status `Closed` and `Resolved`.
 func (m *Manager) UnassignOpen(userID int) error {
@@ -875,8 +1127,8 @@ func (m *Manager) ApplySLA(conversation models.Conversation, policyID int, actor
 // ApplyAction applies an action to a conversation, this can be called from multiple packages across the app to perform actions on conversations.
 // all actions are executed on behalf of the provided user if the user is not provided, system user is used.
 func (m *Manager) ApplyAction(action amodels.RuleAction, conv models.Conversation, user umodels.User) error {
-	// CSAT action does not require a value.
-	if len(action.Value) == 0 && action.Type != amodels.ActionSendCSAT {
+	// CSAT, transcript and close actions do not require a value.
+	if len(action.Value) == 0 && action.Type != amodels.ActionSendCSAT && action.Type != amodels.ActionSendTranscript && action.Type != amodels.ActionCloseConversation {
 		return fmt.Errorf("empty value for action %s", action.Type)
 	}
 
@@ -919,17 +1171,109 @@ func (m *Manager) ApplyAction(action amodels.RuleAction, conv models.Conversatio
 		return m.UpsertConversationTags(conv.UUID, action.Value, user)
 	case amodels.ActionSendCSAT:
 		return m.SendCSATReply(user.ID, conv)
+	case amodels.ActionSendTranscript:
+		// Values are the recipient addresses, no value or the contact value sends it to the contact.
+		to := make([]string, 0, len(action.Value))
+		for _, v := range action.Value {
+			if v != amodels.TranscriptRecipientContact {
+				to = append(to, v)
+			}
+		}
+		return m.SendTranscript(conv.UUID, false, to)
+	case amodels.ActionAddFollower:
+		for _, v := range action.Value {
+			followerID, _ := strconv.Atoi(v)
+			if followerID == 0 {
+				continue
+			}
+			if err := m.AddConversationFollower(conv.UUID, followerID); err != nil {
+				return err
+			}
+		}
+		return nil
+	case amodels.ActionCreateTask:
+		return m.createTaskFromAction(action.Value, conv, user)
+	case amodels.ActionAddTags:
+		return m.AddConversationTags(conv.UUID, action.Value, user)
+	case amodels.ActionRemoveTags:
+		return m.RemoveConversationTags(conv.UUID, action.Value, user)
+	case amodels.ActionSnoozeFor:
+		// Value is a duration, "next_business_day" or "customer_reply".
+		status, err := m.statusStore.GetSnooze()
+		if err != nil {
+			return err
+		}
+		return m.UpdateConversationStatus(conv.UUID, status.ID, "", action.Value[0], user)
+	case amodels.ActionCloseConversation:
+		status, err := m.statusStore.GetByCategory(smodels.CategoryClosed)
+		if err != nil {
+			return err
+		}
+		return m.UpdateConversationStatus(conv.UUID, status.ID, "", "", user)
+	case amodels.ActionRemoveAssignee:
+		// Value is the assignee to remove, "user" or "team".
+		typ := action.Value[0]
+		if typ != models.AssigneeTypeUser && typ != models.AssigneeTypeTeam {
+			return fmt.Errorf("invalid assignee type %s for action %s", typ, action.Type)
+		}
+		return m.RemoveConversationAssignee(conv.UUID, typ, user)
+	case amodels.ActionSetCustomAttribute:
+		// Values are the attribute key and its value.
+		if len(action.Value) < 2 {
+			return fmt.Errorf("attribute key and value required for action %s", action.Type)
+		}
+		return m.SetConversationCustomAttribute(conv.UUID, action.Value[0], action.Value[1])
+	case amodels.ActionSendEmailTo:
+		// Values are the comma separated recipient addresses and the name of the stored email template.
+		if len(action.Value) < 2 {
+			return fmt.Errorf("recipients and template required for action %s", action.Type)
+		}
+		return m.SendTemplateEmail(conv, strings.Split(action.Value[0], ","), action.Value[1])
 	default:
 		return fmt.Errorf("unknown action: %s", action.Type)
 	}
 }
 
-// RemoveConversationAssignee removes the assignee from the conversation.
-func (m *Manager) RemoveConversationAssignee(uuid, typ string) error {
-	if _, err := m.q.RemoveConversationAssignee.Exec(uuid, typ); err != nil {
+// ApplyRuleAction applies an action of an automation rule on behalf of the system user, the rule name is
+// added to the actor name so activity messages show which rule made the change.
+func (m *Manager) ApplyRuleAction(action amodels.RuleAction, conv models.Conversation, ruleName string) error {
+	user, err := m.userStore.GetSystemUser()
+	if err != nil {
+		return fmt.Errorf("get system user: %w", err)
+	}
+	user.LastName = strings.TrimSpace(fmt.Sprintf("%s (rule %q)", user.LastName, ruleName))
+	return m.ApplyAction(action, conv, user)
+}
+
+// RemoveConversationAssignee removes the user or the team assignee, typ, from the conversation and records the change.
+func (m *Manager) RemoveConversationAssignee(uuid, typ string, actor umodels.User) error {
+	var prop, activity, event string
+	switch typ {
+	case models.AssigneeTypeUser:
+		prop, activity, event = "assigned_user_id", ActivityUnassignUser, wmodels.EventConversationUserAssigned
+	case models.AssigneeTypeTeam:
+		prop, activity, event = "assigned_team_id", ActivityUnassignTeam, wmodels.EventConversationTeamAssigned
+	default:
+		return envelope.NewError(envelope.InputError, "Invalid assignee type", nil)
+	}
+
+	res, err := m.q.RemoveConversationAssignee.Exec(uuid, typ)
+	if err != nil {
 		m.lo.Error("error removing conversation assignee", "error", err)
 		return envelope.NewError(envelope.GeneralError, "Error removing conversation assignee", nil)
 	}
+	// Nothing to record if the conversation had no assignee.
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	// Broadcast update to all subscribers.
+	m.BroadcastConversationUpdate(uuid, prop, nil)
+	m.triggerWebhook(event, 0, uuid, nil)
+
+	if err := m.InsertConversationActivity(activity, uuid, "", actor); err != nil {
+		return envelope.NewError(envelope.GeneralError, "Error recording assignee change", nil)
+	}
 	return nil
 }
 
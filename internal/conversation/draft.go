@@ -0,0 +1,46 @@
+package conversation
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/lib/pq"
+)
+
+// GetDraft returns the reply draft of a user in a conversation.
+func (m *Manager) GetDraft(conversationUUID string, userID int) (models.Draft, error) {
+	var draft models.Draft
+	if err := m.q.GetConversationDraft.Get(&draft, conversationUUID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return draft, envelope.NewError(envelope.NotFoundError, "Draft not found", nil)
+		}
+		m.lo.Error("error fetching conversation draft", "conversation_uuid", conversationUUID, "user_id", userID, "error", err)
+		return draft, envelope.NewError(envelope.GeneralError, "Error fetching draft", nil)
+	}
+	return draft, nil
+}
+
+// SaveDraft creates or replaces the reply draft of a user in a conversation.
+func (m *Manager) SaveDraft(conversationUUID string, userID int, content string, cc, bcc, mediaUUIDs []string) (models.Draft, error) {
+	var draft models.Draft
+	cc = stringutil.RemoveEmpty(cc)
+	bcc = stringutil.RemoveEmpty(bcc)
+	mediaUUIDs = stringutil.RemoveEmpty(mediaUUIDs)
+	if err := m.q.UpsertConversationDraft.Get(&draft, conversationUUID, userID, content, pq.StringArray(cc), pq.StringArray(bcc), pq.StringArray(mediaUUIDs)); err != nil {
+		m.lo.Error("error saving conversation draft", "conversation_uuid", conversationUUID, "user_id", userID, "error", err)
+		return draft, envelope.NewError(envelope.GeneralError, "Error saving draft", nil)
+	}
+	return draft, nil
+}
+
+// DeleteDraft deletes the reply draft of a user in a conversation.
+func (m *Manager) DeleteDraft(conversationUUID string, userID int) error {
+	if _, err := m.q.DeleteConversationDraft.Exec(conversationUUID, userID); err != nil {
+		m.lo.Error("error deleting conversation draft", "conversation_uuid", conversationUUID, "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error deleting draft", nil)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package conversation
+
+import (
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/template"
+	"github.com/lib/pq"
+)
+
+const (
+	// Events followers are notified about.
+	FollowerEventIncomingMessage = "incoming_message"
+	FollowerEventStatusChange    = "status_change"
+	FollowerEventPrivateNote     = "private_note"
+)
+
+// GetConversationFollowers returns the users following a conversation.
+func (m *Manager) GetConversationFollowers(uuid string) ([]models.ConversationFollower, error) {
+	var followers = make([]models.ConversationFollower, 0)
+	if err := m.q.GetConversationFollowers.Select(&followers, uuid); err != nil {
+		m.lo.Error("error fetching conversation followers", "uuid", uuid, "error", err)
+		return followers, envelope.NewError(envelope.GeneralError, "Error fetching followers", nil)
+	}
+	return followers, nil
+}
+
+// AddConversationFollower adds a user as a follower of a conversation, adding an existing follower is a no-op.
+func (m *Manager) AddConversationFollower(uuid string, userID int) error {
+	if _, err := m.q.InsertConversationFollower.Exec(userID, uuid); err != nil {
+		m.lo.Error("error adding conversation follower", "uuid", uuid, "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error adding follower", nil)
+	}
+	return nil
+}
+
+// RemoveConversationFollower removes a user from the followers of a conversation.
+func (m *Manager) RemoveConversationFollower(uuid string, userID int) error {
+	if _, err := m.q.DeleteConversationFollower.Exec(userID, uuid); err != nil {
+		m.lo.Error("error removing conversation follower", "uuid", uuid, "user_id", userID, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error removing follower", nil)
+	}
+	return nil
+}
+
+// notifyFollowers notifies the followers of a conversation about an event over websocket and email.
+// Excluded users, such as the agent who caused the event, are not notified.
+func (m *Manager) notifyFollowers(conversationUUID, event, summary, content string, excludeIDs ...int) {
+	// A nil slice is sent as NULL which would exclude every follower.
+	if excludeIDs == nil {
+		excludeIDs = []int{}
+	}
+	var followerIDs []int
+	if err := m.q.GetConversationFollowerIDs.Select(&followerIDs, conversationUUID, pq.Array(excludeIDs)); err != nil {
+		m.lo.Error("error fetching conversation follower ids", "uuid", conversationUUID, "error", err)
+		return
+	}
+	if len(followerIDs) == 0 {
+		return
+	}
+
+	conversation, err := m.GetConversation(0, conversationUUID)
+	if err != nil {
+		return
+	}
+	m.notifyAgents(followerIDs, conversation, event, summary, content, template.TmplConversationUpdated)
+}
@@ -0,0 +1,134 @@
+package conversation
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/volatiletech/null/v9"
+)
+
+// forwardMeta is saved in the meta of forward messages, cc is read by the pending messages query like for replies.
+type forwardMeta struct {
+	To                  []string `json:"to"`
+	CC                  []string `json:"cc,omitempty"`
+	ForwardedMessageIDs []int    `json:"forwarded_message_ids"`
+}
+
+// ForwardMessages forwards the passed messages of a conversation along with their attachments to external addresses.
+// The forward gets its own message ID, so replies from the recipients thread back into the conversation.
+func (m *Manager) ForwardMessages(senderID int, conversationUUID string, messageUUIDs, to, cc []string, note string) error {
+	to = stringutil.RemoveEmpty(to)
+	cc = stringutil.RemoveEmpty(cc)
+	if len(to) == 0 {
+		return envelope.NewError(envelope.InputError, "Empty `to` address", nil)
+	}
+	for _, addr := range append(slices.Clone(to), cc...) {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return envelope.NewError(envelope.InputError, fmt.Sprintf("Invalid email address `%s`", addr), nil)
+		}
+	}
+
+	messageUUIDs = stringutil.RemoveEmpty(messageUUIDs)
+	slices.Sort(messageUUIDs)
+	messageUUIDs = slices.Compact(messageUUIDs)
+	if len(messageUUIDs) == 0 {
+		return envelope.NewError(envelope.InputError, "Select messages to forward", nil)
+	}
+
+	conversation, err := m.GetConversation(0, conversationUUID)
+	if err != nil {
+		return err
+	}
+
+	var messages []models.ForwardMessage
+	if err := m.q.GetForwardMessages.Select(&messages, conversationUUID, messageUUIDs); err != nil {
+		m.lo.Error("error fetching messages to forward", "uuid", conversationUUID, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error fetching messages", nil)
+	}
+	if len(messages) != len(messageUUIDs) {
+		return envelope.NewError(envelope.InputError, "Only sent and received messages of the conversation can be forwarded", nil)
+	}
+
+	meta := forwardMeta{
+		To:                  to,
+		CC:                  cc,
+		ForwardedMessageIDs: make([]int, 0, len(messages)),
+	}
+	var content strings.Builder
+	content.WriteString(note)
+	for _, msg := range messages {
+		meta.ForwardedMessageIDs = append(meta.ForwardedMessageIDs, msg.ID)
+
+		from := html.EscapeString(msg.SenderName)
+		if msg.SenderEmail != "" {
+			from += " &lt;" + html.EscapeString(msg.SenderEmail) + "&gt;"
+		}
+		content.WriteString("<br><br>---------- Forwarded message ----------<br>")
+		content.WriteString("From: " + from + "<br>")
+		content.WriteString("Date: " + msg.CreatedAt.Format("Mon, Jan 2, 2006 at 3:04 PM MST") + "<br>")
+		content.WriteString("Subject: " + html.EscapeString(conversation.Subject.String) + "<br><br>")
+		content.WriteString(msg.Content)
+	}
+
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return envelope.NewError(envelope.GeneralError, "Error marshalling message meta", nil)
+	}
+
+	// Generate a new message ID, the forward starts a new email thread with the recipients.
+	inbox, err := m.inboxStore.GetDBRecord(conversation.InboxID)
+	if err != nil {
+		return err
+	}
+	sourceID, err := stringutil.GenerateEmailMessageID(conversationUUID, inbox.From)
+	if err != nil {
+		m.lo.Error("error generating source message id", "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error generating source message id", nil)
+	}
+
+	message := models.Message{
+		ConversationUUID: conversationUUID,
+		SenderID:         senderID,
+		Type:             MessageForward,
+		SenderType:       SenderTypeAgent,
+		Status:           MessageStatusPending,
+		Content:          content.String(),
+		ContentType:      ContentTypeHTML,
+		Private:          false,
+		Meta:             string(metaJSON),
+		SourceID:         null.StringFrom(sourceID),
+	}
+	return m.InsertMessage(&message)
+}
+
+// prepareForward sets the recipients, subject and forwarded attachments of a pending forward message.
+func (m *Manager) prepareForward(message *models.Message) error {
+	var meta forwardMeta
+	if err := json.Unmarshal([]byte(message.Meta), &meta); err != nil {
+		return fmt.Errorf("unmarshalling forward meta: %w", err)
+	}
+	if len(meta.To) == 0 {
+		return fmt.Errorf("forward has no recipients")
+	}
+	message.To = meta.To
+
+	if message.Subject != "" {
+		message.Subject = "Fwd: " + message.Subject
+	}
+
+	for _, id := range meta.ForwardedMessageIDs {
+		attachments, err := m.getMessageAttachments(id)
+		if err != nil {
+			return err
+		}
+		message.Attachments = append(message.Attachments, attachments...)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package conversation
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/template"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+)
+
+const (
+	MentionTypeAgent = "agent"
+	MentionTypeTeam  = "team"
+
+	// NotificationEventMention is the notification event sent to mentioned agents.
+	NotificationEventMention = "mention"
+
+	defaultMentionAccessDuration = 7 * 24 * time.Hour
+	maxMentionsPerPage           = 100
+)
+
+var (
+	// Mention nodes carry the mentioned ID, e.g. `<span data-type="mention" data-mention-type="agent" data-id="1">@John</span>`.
+	mentionTagRe  = regexp.MustCompile(`<span\s[^>]*data-type="mention"[^>]*>`)
+	mentionAttrRe = regexp.MustCompile(`data-(id|mention-type)="([^"]*)"`)
+)
+
+// mention is an agent or team mentioned in a private note.
+type mention struct {
+	Type string
+	ID   int
+}
+
+// mentionable is an agent or team that can be mentioned by typing `@` followed by its name.
+type mentionable struct {
+	Type string `db:"type"`
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+// parseMentions returns the unique agent and team mentions in the HTML content, from mention nodes
+// and from `@name` text matching the name of one of the passed mentionables.
+func parseMentions(content, text string, mentionables []mentionable) []mention {
+	var (
+		mentions []mention
+		seen     = make(map[mention]struct{})
+	)
+	add := func(mn mention) {
+		if _, ok := seen[mn]; ok {
+			return
+		}
+		seen[mn] = struct{}{}
+		mentions = append(mentions, mn)
+	}
+	for _, tag := range mentionTagRe.FindAllString(content, -1) {
+		var mn mention
+		for _, attr := range mentionAttrRe.FindAllStringSubmatch(tag, -1) {
+			switch attr[1] {
+			case "id":
+				mn.ID, _ = strconv.Atoi(attr[2])
+			case "mention-type":
+				mn.Type = attr[2]
+			}
+		}
+		if mn.ID <= 0 || (mn.Type != MentionTypeAgent && mn.Type != MentionTypeTeam) {
+			continue
+		}
+		add(mn)
+	}
+
+	// Match the longest names first so `@John Doe` is not also taken as a mention of `@John`.
+	sort.SliceStable(mentionables, func(i, j int) bool {
+		return len(mentionables[i].Name) > len(mentionables[j].Name)
+	})
+	text = strings.ToLower(text)
+	for _, mb := range mentionables {
+		name := strings.ToLower(strings.TrimSpace(mb.Name))
+		if name == "" {
+			continue
+		}
+		needle := "@" + name
+		for from := 0; ; {
+			i := strings.Index(text[from:], needle)
+			if i < 0 {
+				break
+			}
+			start, end := from+i, from+i+len(needle)
+			from = end
+			// The name must end at a word boundary.
+			if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				continue
+			}
+			add(mention{Type: mb.Type, ID: mb.ID})
+			// Blank out the match so shorter names do not match inside it.
+			text = text[:start] + strings.Repeat(" ", end-start) + text[end:]
+		}
+	}
+	return mentions
+}
+
+// processMentions stores the mentions in a private note and notifies the mentioned agents.
+// It returns the IDs of the notified agents.
+func (m *Manager) processMentions(message models.Message, sender umodels.User) []int {
+	var mentionables []mentionable
+	if strings.Contains(message.TextContent, "@") {
+		if err := m.q.GetMentionables.Select(&mentionables); err != nil {
+			m.lo.Error("error fetching mentionables", "message_uuid", message.UUID, "error", err)
+		}
+	}
+	mentions := parseMentions(message.Content, message.TextContent, mentionables)
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	for _, mn := range mentions {
+		var userID, teamID int
+		switch mn.Type {
+		case MentionTypeAgent:
+			if _, err := m.userStore.GetAgent(mn.ID); err != nil {
+				m.lo.Warn("mentioned agent not found", "user_id", mn.ID, "message_uuid", message.UUID)
+				continue
+			}
+			userID = mn.ID
+		case MentionTypeTeam:
+			if _, err := m.teamStore.Get(mn.ID); err != nil {
+				m.lo.Warn("mentioned team not found", "team_id", mn.ID, "message_uuid", message.UUID)
+				continue
+			}
+			teamID = mn.ID
+		}
+		if _, err := m.q.InsertConversationMention.Exec(message.ConversationUUID, message.ID, sender.ID, userID, teamID, int(m.mentionAccessDuration.Seconds())); err != nil {
+			m.lo.Error("error inserting conversation mention", "message_uuid", message.UUID, "type", mn.Type, "id", mn.ID, "error", err)
+		}
+	}
+
+	var userIDs []int
+	if err := m.q.GetMentionedUserIDs.Select(&userIDs, message.ID, sender.ID); err != nil {
+		m.lo.Error("error fetching mentioned user ids", "message_uuid", message.UUID, "error", err)
+		return nil
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	conversation, err := m.GetConversation(0, message.ConversationUUID)
+	if err != nil {
+		return nil
+	}
+	m.notifyAgents(userIDs, conversation, NotificationEventMention, fmt.Sprintf("%s mentioned you in a private note.", sender.FullName()), message.TextContent, template.TmplMentioned)
+	return userIDs
+}
+
+// HasActiveMention returns true if the user, or one of their teams, was mentioned in the conversation and the granted access has not expired.
+func (m *Manager) HasActiveMention(conversationID, userID int) (bool, error) {
+	var exists bool
+	if err := m.q.HasActiveMention.Get(&exists, conversationID, userID); err != nil {
+		m.lo.Error("error checking conversation mention", "conversation_id", conversationID, "user_id", userID, "error", err)
+		return false, envelope.NewError(envelope.GeneralError, "Error checking mentions", nil)
+	}
+	return exists, nil
+}
+
+// GetUserMentions returns the mentions of a user, directly or through their teams, latest first.
+func (m *Manager) GetUserMentions(userID, page, pageSize int) ([]models.Mention, int, error) {
+	var mentions = make([]models.Mention, 0)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxMentionsPerPage {
+		pageSize = maxMentionsPerPage
+	}
+	if err := m.q.GetUserMentions.Select(&mentions, userID, pageSize, (page-1)*pageSize); err != nil {
+		m.lo.Error("error fetching user mentions", "user_id", userID, "error", err)
+		return mentions, pageSize, envelope.NewError(envelope.GeneralError, "Error fetching mentions", nil)
+	}
+	return mentions, pageSize, nil
+}
@@ -21,6 +21,7 @@ import (
 	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
 	"github.com/abhinavxd/libredesk/internal/stringutil"
 	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/lib/pq"
 	"github.com/volatiletech/null/v9"
 )
@@ -29,21 +30,26 @@ const (
 	MessageIncoming = "incoming"
 	MessageOutgoing = "outgoing"
 	MessageActivity = "activity"
+	MessageForward  = "forward"
 
 	SenderTypeAgent   = "agent"
 	SenderTypeContact = "contact"
 
-	MessageStatusPending  = "pending"
-	MessageStatusSent     = "sent"
-	MessageStatusFailed   = "failed"
-	MessageStatusReceived = "received"
+	MessageStatusPending   = "pending"
+	MessageStatusSent      = "sent"
+	MessageStatusFailed    = "failed"
+	MessageStatusReceived  = "received"
+	MessageStatusScheduled = "scheduled"
 
 	ActivityStatusChange       = "status_change"
 	ActivityPriorityChange     = "priority_change"
 	ActivityAssignedUserChange = "assigned_user_change"
 	ActivityAssignedTeamChange = "assigned_team_change"
 	ActivitySelfAssign         = "self_assign"
+	ActivityUnassignUser       = "unassign_user"
+	ActivityUnassignTeam       = "unassign_team"
 	ActivityTagChange          = "tag_change"
+	ActivityTagRemove          = "tag_remove"
 	ActivitySLASet             = "sla_set"
 
 	ContentTypeText = "text"
@@ -180,6 +186,31 @@ func (m *Manager) sendOutgoingMessage(message models.Message) {
 
 	// Set from and to addresses
 	message.From = inbox.FromAddress()
+
+	// Forwards go to the external addresses picked by the agent as a new email thread, with the attachments of the forwarded messages.
+	if message.Type == MessageForward {
+		if handleError(m.prepareForward(&message), "error preparing forward") {
+			return
+		}
+		if handleError(inbox.Send(message), "error sending forward") {
+			return
+		}
+		m.UpdateMessageStatus(message.UUID, MessageStatusSent)
+		return
+	}
+
+	// Side thread messages go to the side thread recipients and are threaded with the side thread messages only.
+	if message.SideThreadID.Valid {
+		if handleError(m.prepareSideThreadMessage(&message), "error preparing side thread message") {
+			return
+		}
+		if handleError(inbox.Send(message), "error sending side thread message") {
+			return
+		}
+		m.UpdateMessageStatus(message.UUID, MessageStatusSent)
+		return
+	}
+
 	message.To, err = m.GetToAddress(message.ConversationID)
 	if handleError(err, "error fetching `to` address") {
 		return
@@ -211,6 +242,15 @@ func (m *Manager) sendOutgoingMessage(message models.Message) {
 	// Update status of the message.
 	m.UpdateMessageStatus(message.UUID, MessageStatusSent)
 
+	// Scheduled messages mark the conversation as replied to once they are actually sent.
+	if message.SendAt.Valid {
+		if _, err := m.q.ClearConversationWaitingSince.Exec(message.ConversationID); err != nil {
+			m.lo.Error("error clearing conversation waiting since", "conversation_id", message.ConversationID, "error", err)
+		}
+		m.UpdateConversationLastMessage(message.ConversationID, message.ConversationUUID, message.TextContent, message.SenderType, time.Now())
+	}
+	m.triggerWebhook(wmodels.EventMessageOutgoing, message.ConversationID, message.ConversationUUID, map[string]any{"message": webhookMessage(message)})
+
 	// Update first reply time if the sender is not the system user.
 	// All automated messages are sent by the system user.
 	if systemUser, err := m.userStore.GetSystemUser(); err == nil && message.SenderID != systemUser.ID {
@@ -335,11 +375,50 @@ func (m *Manager) SendPrivateNote(media []mmodels.Media, senderID int, conversat
 		Private:          true,
 		Media:            media,
 	}
-	return m.InsertMessage(&message)
+	if err := m.InsertMessage(&message); err != nil {
+		return err
+	}
+
+	// The draft has been sent.
+	m.DeleteDraft(conversationUUID, senderID)
+
+	// Notify mentioned agents and followers, the note is already saved so errors here are not returned.
+	sender, err := m.userStore.GetAgent(senderID)
+	if err != nil {
+		m.lo.Error("error fetching private note sender", "user_id", senderID, "error", err)
+		return nil
+	}
+	mentionedIDs := m.processMentions(message, sender)
+	m.notifyFollowers(conversationUUID, FollowerEventPrivateNote, fmt.Sprintf("%s added a private note.", sender.FullName()), message.TextContent, append(mentionedIDs, senderID)...)
+	return nil
 }
 
-// SendReply inserts a reply message in a conversation.
+// HasAgentReplySince returns true if an agent other than the passed user replied to the conversation after the given time.
+func (m *Manager) HasAgentReplySince(conversationUUID string, userID int, since time.Time) (bool, error) {
+	var exists bool
+	if err := m.q.HasAgentReplySince.Get(&exists, conversationUUID, userID, since); err != nil {
+		m.lo.Error("error checking agent replies", "conversation_uuid", conversationUUID, "error", err)
+		return false, envelope.NewError(envelope.GeneralError, "Error checking replies", nil)
+	}
+	return exists, nil
+}
+
+// SendReply inserts a reply message in a conversation, the reply is picked up for sending right away.
 func (m *Manager) SendReply(media []mmodels.Media, inboxID, senderID int, conversationUUID, content string, cc, bcc []string, meta map[string]interface{}) error {
+	return m.sendReply(media, inboxID, senderID, conversationUUID, content, cc, bcc, meta, time.Time{})
+}
+
+// SendReplyAt inserts a reply message in a conversation that is sent at the passed time.
+// A zero time holds the reply for the undo send window, if one is configured.
+func (m *Manager) SendReplyAt(media []mmodels.Media, inboxID, senderID int, conversationUUID, content string, cc, bcc []string, meta map[string]interface{}, sendAt time.Time) error {
+	if sendAt.IsZero() && m.undoSendWindow > 0 {
+		sendAt = time.Now().Add(m.undoSendWindow)
+	}
+	return m.sendReply(media, inboxID, senderID, conversationUUID, content, cc, bcc, meta, sendAt)
+}
+
+// sendReply inserts a reply message, scheduling it if a send time is passed.
+func (m *Manager) sendReply(media []mmodels.Media, inboxID, senderID int, conversationUUID, content string, cc, bcc []string, meta map[string]interface{}, sendAt time.Time) error {
 	// Save cc and bcc as JSON in meta.
 	cc = stringutil.RemoveEmpty(cc)
 	bcc = stringutil.RemoveEmpty(bcc)
@@ -379,7 +458,56 @@ func (m *Manager) SendReply(media []mmodels.Media, inboxID, senderID int, conver
 		Meta:             string(metaJSON),
 		SourceID:         null.StringFrom(sourceID),
 	}
-	return m.InsertMessage(&message)
+	if !sendAt.IsZero() {
+		message.Status = MessageStatusScheduled
+		message.SendAt = null.TimeFrom(sendAt)
+	}
+	if err := m.InsertMessage(&message); err != nil {
+		return err
+	}
+
+	// The draft has been sent.
+	m.DeleteDraft(conversationUUID, senderID)
+	return nil
+}
+
+// UpdateScheduledMessage updates the content and send time of a scheduled message that has not been sent yet.
+// A zero send time keeps the current one.
+func (m *Manager) UpdateScheduledMessage(conversationUUID, messageUUID string, senderID int, content string, sendAt time.Time) error {
+	var (
+		newSendAt   time.Time
+		textContent = stringutil.HTML2Text(content)
+		sendAtArg   = null.NewTime(sendAt, !sendAt.IsZero())
+	)
+	if err := m.q.UpdateScheduledMessage.Get(&newSendAt, messageUUID, conversationUUID, senderID, content, textContent, sendAtArg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return envelope.NewError(envelope.ConflictError, "Message is already sent or cannot be edited", nil)
+		}
+		m.lo.Error("error updating scheduled message", "uuid", messageUUID, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error updating message", nil)
+	}
+	m.BroadcastMessageUpdate(conversationUUID, messageUUID, "content", content)
+	m.BroadcastMessageUpdate(conversationUUID, messageUUID, "send_at", newSendAt)
+	return nil
+}
+
+// CancelScheduledMessage deletes a scheduled message that has not been sent yet and restores it as the sender's draft.
+func (m *Manager) CancelScheduledMessage(conversationUUID, messageUUID string, senderID int) (models.Draft, error) {
+	var msg struct {
+		Content    string         `db:"content"`
+		CC         pq.StringArray `db:"cc"`
+		BCC        pq.StringArray `db:"bcc"`
+		MediaUUIDs pq.StringArray `db:"media_uuids"`
+	}
+	if err := m.q.DeleteScheduledMessage.Get(&msg, messageUUID, conversationUUID, senderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Draft{}, envelope.NewError(envelope.ConflictError, "Message is already sent or cannot be cancelled", nil)
+		}
+		m.lo.Error("error cancelling scheduled message", "uuid", messageUUID, "error", err)
+		return models.Draft{}, envelope.NewError(envelope.GeneralError, "Error cancelling message", nil)
+	}
+	m.BroadcastMessageUpdate(conversationUUID, messageUUID, "deleted", true)
+	return m.SaveDraft(conversationUUID, senderID, msg.Content, msg.CC, msg.BCC, msg.MediaUUIDs)
 }
 
 // InsertMessage inserts a message and attaches the media to the message.
@@ -399,7 +527,7 @@ func (m *Manager) InsertMessage(message *models.Message) error {
 
 	// Insert Message.
 	if err := m.q.InsertMessage.QueryRow(message.Type, message.Status, message.ConversationID, message.ConversationUUID, message.Content, message.TextContent, message.SenderID, message.SenderType,
-		message.Private, message.ContentType, message.SourceID, message.Meta).Scan(&message.ID, &message.UUID, &message.CreatedAt); err != nil {
+		message.Private, message.ContentType, message.SourceID, message.Meta, message.SendAt, message.SideThreadID).Scan(&message.ID, &message.UUID, &message.CreatedAt); err != nil {
 		m.lo.Error("error inserting message in db", "error", err)
 		return envelope.NewError(envelope.GeneralError, "Error sending message", nil)
 	}
@@ -414,6 +542,21 @@ func (m *Manager) InsertMessage(message *models.Message) error {
 		return err
 	}
 
+	// Side thread messages are kept out of the conversation's last message.
+	if message.SideThreadID.Valid {
+		if _, err := m.q.UpdateSideThreadUpdatedAt.Exec(message.SideThreadID.Int); err != nil {
+			m.lo.Error("error updating side thread", "side_thread_id", message.SideThreadID.Int, "error", err)
+		}
+		m.BroadcastNewMessage(message)
+		return nil
+	}
+
+	// Forwards are not part of the conversation with the contact and scheduled messages become the last message once sent.
+	if message.Type == MessageForward || (message.SendAt.Valid && message.SendAt.Time.After(time.Now())) {
+		m.BroadcastNewMessage(message)
+		return nil
+	}
+
 	// Hide CSAT message content as it contains a public link to the survey.
 	lastMessage := message.TextContent
 	if message.HasCSAT() {
@@ -472,6 +615,11 @@ func (m *Manager) RecordTagChange(conversationUUID string, tag string, actor umo
 	return m.InsertConversationActivity(ActivityTagChange, conversationUUID, tag, actor)
 }
 
+// RecordTagRemove records an activity for a removed tag.
+func (m *Manager) RecordTagRemove(conversationUUID string, tag string, actor umodels.User) error {
+	return m.InsertConversationActivity(ActivityTagRemove, conversationUUID, tag, actor)
+}
+
 // InsertConversationActivity inserts an activity message.
 func (m *Manager) InsertConversationActivity(activityType, conversationUUID, newValue string, actor umodels.User) error {
 	content, err := m.getMessageActivityContent(activityType, newValue, actor.FullName())
@@ -518,12 +666,18 @@ func (m *Manager) getMessageActivityContent(activityType, newValue, actorName st
 		content = fmt.Sprintf("Assigned to %s team by %s", newValue, actorName)
 	case ActivitySelfAssign:
 		content = fmt.Sprintf("%s self-assigned this conversation", actorName)
+	case ActivityUnassignUser:
+		content = fmt.Sprintf("%s removed the assigned agent", actorName)
+	case ActivityUnassignTeam:
+		content = fmt.Sprintf("%s removed the assigned team", actorName)
 	case ActivityPriorityChange:
 		content = fmt.Sprintf("%s set priority to %s", actorName, newValue)
 	case ActivityStatusChange:
 		content = fmt.Sprintf("%s marked the conversation as %s", actorName, newValue)
 	case ActivityTagChange:
 		content = fmt.Sprintf("%s added tag %s", actorName, newValue)
+	case ActivityTagRemove:
+		content = fmt.Sprintf("%s removed tag %s", actorName, newValue)
 	case ActivitySLASet:
 		content = fmt.Sprintf("%s set %s SLA", actorName, newValue)
 	default:
@@ -559,6 +713,31 @@ func (m *Manager) processIncomingMessage(in models.IncomingMessage) error {
 		return err
 	}
 
+	// Replies to a side thread are stored in the side thread, they don't reopen the conversation or run automations.
+	if !isNewConversation {
+		sideThreadID, err := m.findSideThreadID(append([]string{in.Message.InReplyTo}, in.Message.References...))
+		if err != nil {
+			return err
+		}
+		if sideThreadID > 0 {
+			return m.processSideThreadMessage(in, sideThreadID)
+		}
+	}
+
+	// Replies from anyone other than the contact, e.g. the recipient of a forward, are tagged as from an external participant.
+	var conversation models.Conversation
+	if !isNewConversation {
+		conversation, err = m.GetConversation(in.Message.ConversationID, "")
+		if err != nil {
+			return fmt.Errorf("error fetching conversation: %w", err)
+		}
+		if conversation.ContactID != in.Contact.ID {
+			if err := setMessageMeta(&in.Message, "external_participant", true); err != nil {
+				m.lo.Error("error setting external participant in message meta", "error", err)
+			}
+		}
+	}
+
 	// Upload message attachments.
 	if err := m.uploadMessageAttachments(&in.Message); err != nil {
 		// Log error but continue processing.
@@ -570,13 +749,23 @@ func (m *Manager) processIncomingMessage(in models.IncomingMessage) error {
 		return err
 	}
 
+	// Notify followers of the new message.
+	m.notifyFollowers(in.Message.ConversationUUID, FollowerEventIncomingMessage, fmt.Sprintf("New message from %s.", in.Contact.FullName()), in.Message.TextContent)
+
+	// Send the events to webhooks.
+	webhookData := map[string]any{"message": webhookMessage(in.Message)}
+	if isNewConversation {
+		m.triggerWebhook(wmodels.EventConversationCreated, in.Message.ConversationID, in.Message.ConversationUUID, webhookData)
+	}
+	m.triggerWebhook(wmodels.EventMessageIncoming, in.Message.ConversationID, in.Message.ConversationUUID, webhookData)
+
 	// Evaluate automation rules for new conversation.
 	if isNewConversation {
 		m.automation.EvaluateNewConversationRules(in.Message.ConversationUUID)
 		return nil
 	}
 
-	// Reopen conversation if it's not Open.
+	// Reopen conversation if it's not Open, this also wakes up conversations snoozed until the customer replies.
 	systemUser, err := m.userStore.GetSystemUser()
 	if err != nil {
 		m.lo.Error("error fetching system user", "error", err)
@@ -586,12 +775,35 @@ func (m *Manager) processIncomingMessage(in models.IncomingMessage) error {
 		m.lo.Error("error reopening conversation", "error", err)
 		return fmt.Errorf("error reopening conversation: %w", err)
 	}
+	if conversation.StatusIsSnooze {
+		m.notifySnoozeEnded(conversation, fmt.Sprintf("%s replied to a snoozed conversation.", in.Contact.FullName()))
+	}
 
 	// Trigger automations on incoming message event.
 	m.automation.EvaluateConversationUpdateRules(in.Message.ConversationUUID, amodels.EventConversationMessageIncoming)
 	return nil
 }
 
+// setMessageMeta sets a key in the JSON meta of a message, keeping the existing keys.
+func setMessageMeta(message *models.Message, key string, value any) error {
+	meta := map[string]any{}
+	if message.Meta != "" {
+		if err := json.Unmarshal([]byte(message.Meta), &meta); err != nil {
+			return err
+		}
+	}
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	meta[key] = value
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	message.Meta = string(b)
+	return nil
+}
+
 // MessageExists checks if a message with the given messageID exists.
 func (m *Manager) MessageExists(messageID string) (bool, error) {
 	_, err := m.findConversationID([]string{messageID})
@@ -787,13 +999,26 @@ func (m *Manager) findConversationID(messageSourceIDs []string) (int, error) {
 
 // attachAttachmentsToMessage attaches attachment blobs to message.
 func (m *Manager) attachAttachmentsToMessage(message *models.Message) error {
+	attachments, err := m.getMessageAttachments(message.ID)
+	if err != nil {
+		return err
+	}
+
+	// Attach attachments.
+	message.Attachments = attachments
+
+	return nil
+}
+
+// getMessageAttachments fetches the media of a message along with the blobs.
+func (m *Manager) getMessageAttachments(messageID int) (attachment.Attachments, error) {
 	var attachments attachment.Attachments
 
 	// Get all media for this message.
-	medias, err := m.mediaStore.GetByModel(message.ID, mmodels.ModelMessages)
+	medias, err := m.mediaStore.GetByModel(messageID, mmodels.ModelMessages)
 	if err != nil {
 		m.lo.Error("error fetching message attachments", "error", err)
-		return err
+		return nil, err
 	}
 
 	// Fetch blobs.
@@ -801,7 +1026,7 @@ func (m *Manager) attachAttachmentsToMessage(message *models.Message) error {
 		blob, err := m.mediaStore.GetBlob(media.UUID)
 		if err != nil {
 			m.lo.Error("error fetching media blob", "error", err)
-			return err
+			return nil, err
 		}
 		attachment := attachment.Attachment{
 			Name:    media.Filename,
@@ -810,11 +1035,7 @@ func (m *Manager) attachAttachmentsToMessage(message *models.Message) error {
 		}
 		attachments = append(attachments, attachment)
 	}
-
-	// Attach attachments.
-	message.Attachments = attachments
-
-	return nil
+	return attachments, nil
 }
 
 // getOutgoingProcessingMessageIDs returns the IDs of outgoing messages currently being processed.
@@ -853,3 +1074,15 @@ func (m *Manager) uploadThumbnailForMedia(media mmodels.Media, content []byte) e
 	}
 	return nil
 }
+
+// webhookMessage returns a copy of the message for webhook payloads, without the attachment contents.
+func webhookMessage(message models.Message) models.Message {
+	attachments := make(attachment.Attachments, len(message.Attachments))
+	for i, a := range message.Attachments {
+		a.Content = nil
+		attachments[i] = a
+	}
+	message.Attachments = attachments
+	message.CensorCSATContent()
+	return message
+}
@@ -22,6 +22,9 @@ var (
 	AssigneeTypeTeam = "team"
 	AssigneeTypeUser = "user"
 
+	SnoozeUntilNextBusinessDay = "next_business_day"
+	SnoozeUntilCustomerReply   = "customer_reply"
+
 	AllConversations            = "all"
 	AssignedConversations       = "assigned"
 	UnassignedConversations     = "unassigned"
@@ -40,7 +43,10 @@ type Conversation struct {
 	ReferenceNumber       string          `db:"reference_number" json:"reference_number,omitempty"`
 	Priority              null.String     `db:"priority" json:"priority"`
 	PriorityID            null.Int        `db:"priority_id" json:"priority_id"`
+	PriorityRank          null.Int        `db:"priority_rank" json:"priority_rank"`
 	Status                null.String     `db:"status" json:"status"`
+	StatusCategory        null.String     `db:"status_category" json:"status_category"`
+	StatusIsSnooze        bool            `db:"status_is_snooze" json:"status_is_snooze"`
 	StatusID              null.Int        `db:"status_id" json:"status_id"`
 	FirstReplyAt          null.Time       `db:"first_reply_at" json:"first_reply_at"`
 	AssignedUserID        null.Int        `db:"assigned_user_id" json:"assigned_user_id"`
@@ -77,6 +83,71 @@ type ConversationParticipant struct {
 	AvatarURL null.String `db:"avatar_url" json:"avatar_url"`
 }
 
+type ConversationFollower struct {
+	ID        int         `db:"id" json:"id"`
+	FirstName string      `db:"first_name" json:"first_name"`
+	LastName  string      `db:"last_name" json:"last_name"`
+	AvatarURL null.String `db:"avatar_url" json:"avatar_url"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+}
+
+type Mention struct {
+	Total                       int         `db:"total" json:"-"`
+	ID                          int         `db:"id" json:"id"`
+	CreatedAt                   time.Time   `db:"created_at" json:"created_at"`
+	AccessExpiresAt             time.Time   `db:"access_expires_at" json:"access_expires_at"`
+	ConversationUUID            string      `db:"conversation_uuid" json:"conversation_uuid"`
+	ConversationReferenceNumber string      `db:"conversation_reference_number" json:"conversation_reference_number"`
+	ConversationSubject         null.String `db:"conversation_subject" json:"conversation_subject"`
+	MessageUUID                 string      `db:"message_uuid" json:"message_uuid"`
+	MessageTextContent          null.String `db:"message_text_content" json:"message_text_content"`
+	MentionedByID               int         `db:"mentioned_by_id" json:"mentioned_by_id"`
+	MentionedByName             string      `db:"mentioned_by_name" json:"mentioned_by_name"`
+	TeamID                      null.Int    `db:"team_id" json:"team_id"`
+	TeamName                    null.String `db:"team_name" json:"team_name"`
+}
+
+type Draft struct {
+	ID               int            `db:"id" json:"id"`
+	CreatedAt        time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time      `db:"updated_at" json:"updated_at"`
+	ConversationUUID string         `db:"conversation_uuid" json:"conversation_uuid"`
+	UserID           int            `db:"user_id" json:"user_id"`
+	Content          string         `db:"content" json:"content"`
+	CC               pq.StringArray `db:"cc" json:"cc"`
+	BCC              pq.StringArray `db:"bcc" json:"bcc"`
+	MediaUUIDs       pq.StringArray `db:"media_uuids" json:"media_uuids"`
+}
+
+const (
+	BulkJobStatusRunning   = "running"
+	BulkJobStatusCompleted = "completed"
+
+	// MaxBulkConversations is the maximum number of conversations a single bulk job can act on.
+	MaxBulkConversations = 500
+)
+
+// BulkJob is a set of actions applied to many conversations in the background.
+type BulkJob struct {
+	ID          string          `json:"id"`
+	UserID      int             `json:"user_id"`
+	Status      string          `json:"status"`
+	Total       int             `json:"total"`
+	Processed   int             `json:"processed"`
+	Succeeded   int             `json:"succeeded"`
+	Failed      int             `json:"failed"`
+	Results     []BulkJobResult `json:"results"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CompletedAt null.Time       `json:"completed_at"`
+}
+
+// BulkJobResult is the outcome of a bulk job for a single conversation.
+type BulkJobResult struct {
+	ConversationUUID string `json:"conversation_uuid"`
+	Success          bool   `json:"success"`
+	Error            string `json:"error"`
+}
+
 type ConversationCounts struct {
 	TotalAssigned         int `db:"total_assigned" json:"total_assigned"`
 	UnresolvedCount       int `db:"unresolved_count" json:"unresolved_count"`
@@ -107,6 +178,8 @@ type Message struct {
 	SenderType       string                 `db:"sender_type" json:"sender_type"`
 	InboxID          int                    `db:"inbox_id" json:"-"`
 	Meta             string                 `db:"meta" json:"meta"`
+	SendAt           null.Time              `db:"send_at" json:"send_at"`
+	SideThreadID     null.Int               `db:"side_thread_id" json:"side_thread_id"`
 	Attachments      attachment.Attachments `db:"attachments" json:"attachments"`
 	ConversationUUID string                 `db:"conversation_uuid" json:"-"`
 	From             string                 `db:"from"  json:"-"`
@@ -146,6 +219,56 @@ func (m *Message) HasCSAT() bool {
 	return isCsat
 }
 
+// TranscriptMessage is a message as shown in an exported conversation transcript.
+type TranscriptMessage struct {
+	CreatedAt   time.Time              `db:"created_at" json:"created_at"`
+	Type        string                 `db:"type" json:"type"`
+	Content     string                 `db:"content" json:"content"`
+	TextContent string                 `db:"text_content" json:"text_content"`
+	Private     bool                   `db:"private" json:"private"`
+	SenderType  string                 `db:"sender_type" json:"sender_type"`
+	SenderName  string                 `db:"sender_name" json:"sender_name"`
+	Attachments attachment.Attachments `db:"attachments" json:"attachments"`
+}
+
+// ForwardMessage is a message picked to be forwarded to an external address.
+type ForwardMessage struct {
+	ID          int       `db:"id"`
+	CreatedAt   time.Time `db:"created_at"`
+	Content     string    `db:"content"`
+	SenderName  string    `db:"sender_name"`
+	SenderEmail string    `db:"sender_email"`
+}
+
+// SideThread is a separate email thread with external parties started from within a conversation.
+type SideThread struct {
+	ID        int            `db:"id" json:"id"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+	UUID      string         `db:"uuid" json:"uuid"`
+	CreatedBy null.Int       `db:"created_by" json:"created_by"`
+	Subject   string         `db:"subject" json:"subject"`
+	To        pq.StringArray `db:"to" json:"to"`
+	CC        pq.StringArray `db:"cc" json:"cc"`
+	Messages  []Message      `db:"-" json:"messages"`
+}
+
+// Task is a follow up reminder on a conversation assigned to an agent.
+type Task struct {
+	ID               int         `db:"id" json:"id"`
+	CreatedAt        time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time   `db:"updated_at" json:"updated_at"`
+	ConversationUUID string      `db:"conversation_uuid" json:"conversation_uuid"`
+	ReferenceNumber  string      `db:"reference_number" json:"reference_number"`
+	Subject          null.String `db:"subject" json:"subject"`
+	CreatedBy        null.Int    `db:"created_by" json:"created_by"`
+	AssignedUserID   int         `db:"assigned_user_id" json:"assigned_user_id"`
+	AssignedUserName string      `db:"assigned_user_name" json:"assigned_user_name"`
+	Title            string      `db:"title" json:"title"`
+	DueAt            time.Time   `db:"due_at" json:"due_at"`
+	DoneAt           null.Time   `db:"done_at" json:"done_at"`
+}
+
 // IncomingMessage links a message with the contact information and inbox id.
 type IncomingMessage struct {
 	Message Message
@@ -157,6 +280,7 @@ type Status struct {
 	ID        int       `db:"id" json:"id"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	Name      string    `db:"name" json:"name"`
+	Category  string    `db:"category" json:"category"`
 }
 
 type Priority struct {
@@ -0,0 +1,85 @@
+package conversation
+
+import (
+	"fmt"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	wsmodels "github.com/abhinavxd/libredesk/internal/ws/models"
+)
+
+// maxNotificationContentLen is the maximum length of the message excerpt in agent notifications.
+const maxNotificationContentLen = 200
+
+// notifyAgents notifies agents about an event on a conversation over websocket, and by email using the passed stored template.
+func (m *Manager) notifyAgents(userIDs []int, conversation models.Conversation, event, summary, content, tmpl string) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	if len([]rune(content)) > maxNotificationContentLen {
+		content = string([]rune(content)[:maxNotificationContentLen]) + "..."
+	}
+
+	m.broadcastToUsers(userIDs, wsmodels.Message{
+		Type: wsmodels.MessageTypeNotification,
+		Data: map[string]interface{}{
+			"conversation_uuid": conversation.UUID,
+			"reference_number":  conversation.ReferenceNumber,
+			"subject":           conversation.Subject.String,
+			"event":             event,
+			"summary":           summary,
+			"content":           content,
+		},
+	})
+
+	for _, id := range userIDs {
+		if err := m.sendAgentNotificationEmail(id, conversation, summary, content, tmpl); err != nil {
+			m.lo.Error("error sending agent notification email", "user_id", id, "conversation_uuid", conversation.UUID, "template", tmpl, "error", err)
+		}
+	}
+}
+
+// sendAgentNotificationEmail renders the stored template and sends it to the agent.
+func (m *Manager) sendAgentNotificationEmail(userID int, conversation models.Conversation, summary, content, tmpl string) error {
+	agent, err := m.userStore.GetAgent(userID)
+	if err != nil {
+		return fmt.Errorf("fetching agent: %w", err)
+	}
+
+	body, subject, err := m.template.RenderStoredEmailTemplate(tmpl,
+		map[string]any{
+			"Conversation": map[string]any{
+				"ReferenceNumber": conversation.ReferenceNumber,
+				"Subject":         conversation.Subject.String,
+				"Priority":        conversation.Priority.String,
+				"UUID":            conversation.UUID,
+			},
+			"Contact": map[string]any{
+				"FirstName": conversation.Contact.FirstName,
+				"LastName":  conversation.Contact.LastName,
+				"FullName":  conversation.Contact.FullName(),
+				"Email":     conversation.Contact.Email,
+			},
+			"Recipient": map[string]any{
+				"FirstName": agent.FirstName,
+				"LastName":  agent.LastName,
+				"FullName":  agent.FullName(),
+				"Email":     agent.Email,
+			},
+			"Event": map[string]any{
+				"Summary": summary,
+				"Content": content,
+			},
+		})
+	if err != nil {
+		return fmt.Errorf("rendering template: %w", err)
+	}
+
+	return m.notifier.Send(notifier.Message{
+		UserIDs:  []int{userID},
+		Subject:  subject,
+		Content:  body,
+		Provider: notifier.ProviderEmail,
+	})
+}
@@ -1,9 +1,17 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
 
 type Priority struct {
-	ID        int       `db:"id" json:"id"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	Name      string    `db:"name" json:"name"`
+	ID          int       `db:"id" json:"id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	Name        string    `db:"name" json:"name"`
+	Color       string    `db:"color" json:"color"`
+	Rank        int       `db:"rank" json:"rank"`
+	SLAPolicyID null.Int  `db:"sla_policy_id" json:"sla_policy_id"`
 }
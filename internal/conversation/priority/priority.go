@@ -2,23 +2,33 @@
 package priority
 
 import (
+	"database/sql"
 	"embed"
+	"errors"
+	"regexp"
+	"strings"
 
 	"github.com/abhinavxd/libredesk/internal/conversation/priority/models"
 	"github.com/abhinavxd/libredesk/internal/dbutil"
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/zerodha/logf"
 )
 
 var (
 	//go:embed queries.sql
 	efs embed.FS
+
+	colorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
 )
 
+const maxNameLen = 140
+
 // Manager handles changes to priorities.
 type Manager struct {
 	q  queries
+	db *sqlx.DB
 	lo *logf.Logger
 }
 
@@ -30,8 +40,14 @@ type Opts struct {
 
 // queries contains prepared SQL queries.
 type queries struct {
-	GetAll *sqlx.Stmt `query:"get-all"`
-	Get    *sqlx.Stmt `query:"get"`
+	GetAll             *sqlx.Stmt `query:"get-all"`
+	Get                *sqlx.Stmt `query:"get"`
+	GetByName          *sqlx.Stmt `query:"get-by-name"`
+	Insert             *sqlx.Stmt `query:"insert"`
+	Update             *sqlx.Stmt `query:"update"`
+	Delete             *sqlx.Stmt `query:"delete"`
+	RemapConversations *sqlx.Stmt `query:"remap-conversations"`
+	Reorder            *sqlx.Stmt `query:"reorder"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -42,11 +58,12 @@ func New(opts Opts) (*Manager, error) {
 	}
 	return &Manager{
 		q:  q,
+		db: opts.DB,
 		lo: opts.Lo,
 	}, nil
 }
 
-// GetAll retrieves all priorities.
+// GetAll retrieves all priorities ordered by rank, least urgent first.
 func (m *Manager) GetAll() ([]models.Priority, error) {
 	var priorities = make([]models.Priority, 0)
 	if err := m.q.GetAll.Select(&priorities); err != nil {
@@ -60,8 +77,151 @@ func (m *Manager) GetAll() ([]models.Priority, error) {
 func (m *Manager) Get(id int) (models.Priority, error) {
 	var priority models.Priority
 	if err := m.q.Get.Get(&priority, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return priority, envelope.NewError(envelope.NotFoundError, "Priority not found", nil)
+		}
 		m.lo.Error("error fetching priority", "error", err)
 		return priority, envelope.NewError(envelope.GeneralError, "Error fetching priority", nil)
 	}
 	return priority, nil
 }
+
+// GetByName retrieves a priority by name.
+func (m *Manager) GetByName(name string) (models.Priority, error) {
+	var priority models.Priority
+	if err := m.q.GetByName.Get(&priority, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return priority, envelope.NewError(envelope.NotFoundError, "Priority not found", nil)
+		}
+		m.lo.Error("error fetching priority", "name", name, "error", err)
+		return priority, envelope.NewError(envelope.GeneralError, "Error fetching priority", nil)
+	}
+	return priority, nil
+}
+
+// Create creates a new priority ranked above the existing ones.
+func (m *Manager) Create(name, color string, slaPolicyID int) (models.Priority, error) {
+	name = strings.TrimSpace(name)
+	if err := validate(name, color); err != nil {
+		return models.Priority{}, err
+	}
+	var id int
+	if err := m.q.Insert.Get(&id, name, color, slaPolicyID); err != nil {
+		return models.Priority{}, m.handleWriteError(err, "creating")
+	}
+	return m.Get(id)
+}
+
+// Update updates the name, colour and SLA policy of a priority.
+func (m *Manager) Update(id int, name, color string, slaPolicyID int) (models.Priority, error) {
+	name = strings.TrimSpace(name)
+	if err := validate(name, color); err != nil {
+		return models.Priority{}, err
+	}
+	res, err := m.q.Update.Exec(id, name, color, slaPolicyID)
+	if err != nil {
+		return models.Priority{}, m.handleWriteError(err, "updating")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Priority{}, envelope.NewError(envelope.NotFoundError, "Priority not found", nil)
+	}
+	return m.Get(id)
+}
+
+// Delete deletes a priority by ID. Conversations with the priority are moved to the replacement priority if one is passed,
+// otherwise deleting a priority that is in use fails. It returns the UUIDs of the moved conversations.
+func (m *Manager) Delete(id, replaceWithID int) ([]string, error) {
+	if replaceWithID == id {
+		return nil, envelope.NewError(envelope.InputError, "Cannot replace a priority with itself", nil)
+	}
+	if replaceWithID > 0 {
+		if _, err := m.Get(replaceWithID); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := m.db.Beginx()
+	if err != nil {
+		m.lo.Error("error starting db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error deleting priority", nil)
+	}
+	defer tx.Rollback()
+
+	var uuids = make([]string, 0)
+	if replaceWithID > 0 {
+		if err := tx.Stmtx(m.q.RemapConversations).Select(&uuids, id, replaceWithID); err != nil {
+			m.lo.Error("error moving conversations to replacement priority", "id", id, "replace_with", replaceWithID, "error", err)
+			return nil, envelope.NewError(envelope.GeneralError, "Error deleting priority", nil)
+		}
+	}
+
+	res, err := tx.Stmtx(m.q.Delete).Exec(id)
+	if err != nil {
+		if dbutil.IsForeignKeyError(err) {
+			return nil, envelope.NewError(envelope.InputError, "Cannot delete priority as it is in use, pick a priority to move its conversations to.", nil)
+		}
+		m.lo.Error("error deleting priority", "id", id, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error deleting priority", nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, envelope.NewError(envelope.NotFoundError, "Priority not found", nil)
+	}
+
+	if err := tx.Commit(); err != nil {
+		m.lo.Error("error committing db txn", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error deleting priority", nil)
+	}
+	return uuids, nil
+}
+
+// Reorder ranks all priorities by their position in the passed IDs, least urgent first.
+func (m *Manager) Reorder(ids []int) error {
+	priorities, err := m.GetAll()
+	if err != nil {
+		return err
+	}
+	if len(ids) != len(priorities) {
+		return envelope.NewError(envelope.InputError, "All priorities should be passed to reorder", nil)
+	}
+	seen := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	for _, p := range priorities {
+		if _, ok := seen[p.ID]; !ok {
+			return envelope.NewError(envelope.InputError, "All priorities should be passed to reorder", nil)
+		}
+	}
+
+	if _, err := m.q.Reorder.Exec(pq.Array(ids)); err != nil {
+		m.lo.Error("error reordering priorities", "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error reordering priorities", nil)
+	}
+	return nil
+}
+
+// handleWriteError converts errors from creating or updating a priority into envelope errors.
+func (m *Manager) handleWriteError(err error, action string) error {
+	if dbutil.IsUniqueViolationError(err) {
+		return envelope.NewError(envelope.ConflictError, "Priority with the same name already exists", nil)
+	}
+	if dbutil.IsForeignKeyError(err) {
+		return envelope.NewError(envelope.InputError, "SLA policy not found", nil)
+	}
+	m.lo.Error("error "+action+" priority", "error", err)
+	return envelope.NewError(envelope.GeneralError, "Error "+action+" priority", nil)
+}
+
+// validate validates the name and colour of a priority.
+func validate(name, color string) error {
+	if name == "" {
+		return envelope.NewError(envelope.InputError, "Empty priority `name`", nil)
+	}
+	if len(name) > maxNameLen {
+		return envelope.NewError(envelope.InputError, "Priority name is too long", nil)
+	}
+	if color != "" && !colorRegex.MatchString(color) {
+		return envelope.NewError(envelope.InputError, "Invalid priority `color`, should be a hex colour like #f97316", nil)
+	}
+	return nil
+}
@@ -1,5 +1,33 @@
 -- name: get-all
-SELECT created_at, id, name from conversation_priorities;
+SELECT id, created_at, updated_at, name, color, "rank", sla_policy_id FROM conversation_priorities ORDER BY "rank", id;
 
 -- name: get
-SELECT created_at, id, name from conversation_priorities WHERE id = $1;
\ No newline at end of file
+SELECT id, created_at, updated_at, name, color, "rank", sla_policy_id FROM conversation_priorities WHERE id = $1;
+
+-- name: get-by-name
+SELECT id, created_at, updated_at, name, color, "rank", sla_policy_id FROM conversation_priorities WHERE name = $1;
+
+-- name: insert
+-- New priorities are ranked above the existing ones.
+INSERT INTO conversation_priorities (name, color, sla_policy_id, "rank")
+VALUES ($1, $2, NULLIF($3, 0), (SELECT COALESCE(MAX("rank"), 0) + 1 FROM conversation_priorities))
+RETURNING id;
+
+-- name: update
+UPDATE conversation_priorities
+SET name = $2, color = $3, sla_policy_id = NULLIF($4, 0), updated_at = NOW()
+WHERE id = $1;
+
+-- name: delete
+DELETE FROM conversation_priorities WHERE id = $1;
+
+-- name: remap-conversations
+UPDATE conversations SET priority_id = $2, updated_at = NOW() WHERE priority_id = $1
+RETURNING uuid;
+
+-- name: reorder
+-- Ranks priorities by their position in the passed IDs, the first is the least urgent.
+UPDATE conversation_priorities p
+SET "rank" = o.position, updated_at = NOW()
+FROM UNNEST($1::INT[]) WITH ORDINALITY AS o(id, position)
+WHERE p.id = o.id;
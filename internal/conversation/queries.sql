@@ -1,13 +1,13 @@
 -- name: unsnooze-all
+-- Reopens due snoozed conversations with the status $1.
 UPDATE conversations
-SET snoozed_until = NULL, status_id = (SELECT id FROM conversation_statuses WHERE name = 'Open')
-WHERE snoozed_until <= now();
+SET snoozed_until = NULL, status_id = $1, updated_at = now()
+WHERE snoozed_until <= now()
+AND status_id IN (SELECT id FROM conversation_statuses WHERE is_snooze)
+RETURNING uuid;
 
 -- name: insert-conversation
 WITH 
-status_id AS (
-   SELECT id FROM conversation_statuses WHERE name = $3
-),
 reference_number AS (
    SELECT generate_reference_number($8) AS reference_number
 )
@@ -16,7 +16,7 @@ INSERT INTO conversations
 VALUES(
    $1, 
    $2, 
-   (SELECT id FROM status_id), 
+   $3, 
    $4, 
    $5, 
    $6, 
@@ -63,7 +63,10 @@ SELECT
     ) t
     ) as unread_message_count,
     conversation_statuses.name as status,
+    conversation_statuses.category as status_category,
+    COALESCE(conversation_statuses.is_snooze, false) as status_is_snooze,
     conversation_priorities.name as priority,
+    conversation_priorities.rank as priority_rank,
     as_latest.first_response_deadline_at,
     as_latest.resolution_deadline_at,
     as_latest.status as sla_status
@@ -89,6 +92,7 @@ WITH last_reply AS (
    FROM conversation_messages 
    WHERE private = false
    AND type IN ('outgoing', 'incoming')
+   AND side_thread_id IS NULL
    AND (
        ($1 > 0 AND conversation_id = $1)
        OR ($2 != '' AND conversation_id = (SELECT id FROM conversations WHERE uuid = $2::uuid))
@@ -106,7 +110,10 @@ SELECT
    c.status_id,
    c.priority_id,
    p.name as priority,
+   p.rank as priority_rank,
    s.name as status,
+   s.category as status_category,
+   COALESCE(s.is_snooze, false) as status_is_snooze,
    c.uuid,
    c.reference_number,
    c.first_reply_at,
@@ -156,12 +163,13 @@ WHERE
    ($2 != '' AND c.uuid = $2::uuid)
 
 
--- name: get-conversations-created-after
+-- name: get-recent-conversations
 SELECT
     c.id,
     c.uuid
 FROM conversations c
-WHERE c.created_at > $1;
+ORDER BY c.created_at DESC
+LIMIT $1;
 
 -- name: get-contact-conversations
 SELECT
@@ -195,16 +203,17 @@ updated_at = now()
 WHERE uuid = $1;
 
 -- name: update-conversation-status
+-- The resolved and closed times are set by the category of the status, $4, the snooze time is kept only for the snooze status.
 UPDATE conversations
-SET status_id = (SELECT id FROM conversation_statuses WHERE name = $2),
-    resolved_at = COALESCE(resolved_at, CASE WHEN $2 IN ('Resolved', 'Closed') THEN NOW() END),
-    closed_at = COALESCE(closed_at, CASE WHEN $2 = 'Closed' THEN NOW() END),
-    snoozed_until = CASE WHEN $2 = 'Snoozed' THEN $3::timestamptz ELSE snoozed_until END,
+SET status_id = $2,
+    resolved_at = COALESCE(resolved_at, CASE WHEN $4 IN ('solved', 'closed') THEN NOW() END),
+    closed_at = COALESCE(closed_at, CASE WHEN $4 = 'closed' THEN NOW() END),
+    snoozed_until = CASE WHEN $5 THEN $3::timestamptz ELSE NULL END,
     updated_at = NOW()
 WHERE uuid = $1;
 
 -- name: get-user-active-conversations-count
-SELECT COUNT(*) FROM conversations WHERE status_id IN (SELECT id FROM conversation_statuses WHERE name NOT IN ('Resolved', 'Closed')) and assigned_user_id = $1;
+SELECT COUNT(*) FROM conversations WHERE status_id IN (SELECT id FROM conversation_statuses WHERE category NOT IN ('solved', 'closed')) and assigned_user_id = $1;
 
 -- name: update-conversation-priority
 UPDATE conversations 
@@ -255,14 +264,14 @@ SELECT json_build_object(
     'open', COUNT(*),
     'awaiting_response', COUNT(CASE WHEN c.waiting_since IS NOT NULL THEN 1 END),
     'unassigned', COUNT(CASE WHEN c.assigned_user_id IS NULL THEN 1 END),
-    'pending', COUNT(CASE WHEN c.first_reply_at IS NOT NULL THEN 1 END),
+    'pending', COUNT(CASE WHEN s.category = 'pending' THEN 1 END),
     'agents_online', (SELECT COUNT(*) FROM users WHERE availability_status = 'online' AND type = 'agent' AND deleted_at is null),
     'agents_away', (SELECT COUNT(*) FROM users WHERE availability_status in ('away', 'away_manual') AND type = 'agent' AND deleted_at is null),
     'agents_offline', (SELECT COUNT(*) FROM users WHERE availability_status = 'offline' AND type = 'agent' AND deleted_at is null)
 )
 FROM conversations c
 INNER JOIN conversation_statuses s ON c.status_id = s.id
-WHERE s.name not in ('Resolved', 'Closed') AND 1=1 %s;
+WHERE s.category not in ('solved', 'closed') AND 1=1 %s;
 
 -- name: get-dashboard-charts
 WITH new_conversations AS (
@@ -381,7 +390,7 @@ WHERE m.uuid = $1;
 UPDATE conversations
 SET assigned_user_id = NULL,
     updated_at = now()
-WHERE assigned_user_id = $1 AND status_id in (SELECT id FROM conversation_statuses WHERE name NOT IN ('Resolved', 'Closed'));
+WHERE assigned_user_id = $1 AND status_id in (SELECT id FROM conversation_statuses WHERE category NOT IN ('solved', 'closed'));
 
 
 -- MESSAGE queries.
@@ -392,6 +401,7 @@ FROM conversation_messages
 WHERE conversation_id = $1
 AND type in ('incoming', 'outgoing') and private = false
 and source_id > ''
+and side_thread_id IS NULL
 ORDER BY id DESC
 LIMIT $2;
 
@@ -407,6 +417,11 @@ SELECT
     m.conversation_id,
     m.content_type,
     m.source_id,
+    m.meta,
+    m.side_thread_id,
+    m.send_at,
+    m.text_content,
+    m.sender_type,
     ARRAY(SELECT jsonb_array_elements_text(m.meta->'cc')) AS cc,
     ARRAY(SELECT jsonb_array_elements_text(m.meta->'bcc')) AS bcc,
     c.inbox_id,
@@ -414,7 +429,7 @@ SELECT
     c.subject
 FROM conversation_messages m
 INNER JOIN conversations c ON c.id = m.conversation_id
-WHERE m.status = 'pending'
+WHERE (m.status = 'pending' OR (m.status = 'scheduled' AND m.send_at <= NOW()))
 AND NOT(m.id = ANY($1::INT[]))
 
 -- name: get-message
@@ -429,6 +444,8 @@ SELECT
     m.sender_type,
     m.sender_id,
     m.meta,
+    m.send_at,
+    m.side_thread_id,
     COALESCE(
         json_agg(
             json_build_object(
@@ -462,6 +479,7 @@ SELECT
    m.sender_id,
    m.sender_type,
    m.meta,
+   m.send_at,
    COALESCE(
      (SELECT json_agg(
        json_build_object(
@@ -479,8 +497,63 @@ FROM conversation_messages m
 WHERE m.conversation_id = (
    SELECT id FROM conversations WHERE uuid = $1 LIMIT 1
 )
+AND m.side_thread_id IS NULL
 ORDER BY m.created_at DESC %s
 
+-- name: get-transcript-messages
+-- Activity is always included, private notes and messages forwarded to third parties only when $2 is true.
+-- Scheduled and failed messages were never sent and are skipped.
+SELECT
+   m.created_at,
+   m.type,
+   m.content,
+   m.text_content,
+   m.private,
+   m.sender_type,
+   COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email, '') AS sender_name,
+   COALESCE(
+     (SELECT json_agg(
+       json_build_object(
+         'name', filename,
+         'content_type', content_type,
+         'uuid', uuid,
+         'size', size,
+         'content_id', content_id,
+         'disposition', disposition
+       ) ORDER BY filename
+     ) FROM media
+     WHERE model_type = 'messages' AND model_id = m.id),
+   '[]'::json) AS attachments
+FROM conversation_messages m
+LEFT JOIN users u ON u.id = m.sender_id
+WHERE m.conversation_id = (
+   SELECT id FROM conversations WHERE uuid = $1 LIMIT 1
+)
+AND m.status NOT IN ('scheduled', 'failed')
+AND m.side_thread_id IS NULL
+AND (m.type = 'activity' OR (NOT m.private AND m.type != 'forward') OR $2)
+ORDER BY m.created_at ASC;
+
+-- name: get-forward-messages
+-- Only messages exchanged with the contact can be forwarded, private notes never leave the helpdesk.
+SELECT
+   m.id,
+   m.created_at,
+   m.content,
+   COALESCE(NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), ''), u.email, '') AS sender_name,
+   COALESCE(u.email, '') AS sender_email
+FROM conversation_messages m
+LEFT JOIN users u ON u.id = m.sender_id
+WHERE m.conversation_id = (
+   SELECT id FROM conversations WHERE uuid = $1 LIMIT 1
+)
+AND m.uuid = ANY($2::uuid[])
+AND m.type IN ('incoming', 'outgoing')
+AND NOT m.private
+AND m.side_thread_id IS NULL
+AND m.status NOT IN ('scheduled', 'failed')
+ORDER BY m.created_at ASC;
+
 -- name: insert-message
 WITH conversation_id AS (
    SELECT id 
@@ -494,11 +567,11 @@ inserted_msg AS (
    INSERT INTO conversation_messages (
        "type", status, conversation_id, "content", 
        text_content, sender_id, sender_type, private,
-       content_type, source_id, meta
+       content_type, source_id, meta, send_at, side_thread_id
    )
    VALUES (
        $1, $2, (SELECT id FROM conversation_id),
-       $5, $6, $7, $8, $9, $10, $11, $12
+       $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
    )
    RETURNING id, uuid, created_at, conversation_id
 ),
@@ -509,7 +582,10 @@ updated_conversation AS (
        WHEN $8 = 'agent' THEN NULL
        ELSE waiting_since
    END
-   WHERE id = (SELECT id FROM conversation_id)
+   -- Side thread messages are not part of the conversation with the contact. Forwards go to third parties and
+   -- scheduled messages update the conversation once they are sent.
+   WHERE id = (SELECT id FROM conversation_id) AND $14::BIGINT IS NULL
+   AND $1 != 'forward' AND ($13::TIMESTAMPTZ IS NULL OR $13::TIMESTAMPTZ <= NOW())
 )
 SELECT id, uuid, created_at FROM inserted_msg;
 
@@ -518,6 +594,13 @@ SELECT conversation_id
 FROM conversation_messages
 WHERE source_id = ANY($1::text []);
 
+-- name: get-side-thread-id-by-source-id
+SELECT side_thread_id
+FROM conversation_messages
+WHERE source_id = ANY($1::text [])
+AND side_thread_id IS NOT NULL
+LIMIT 1;
+
 -- name: get-conversation-by-message-id
 SELECT
     c.id,
@@ -528,25 +611,324 @@ FROM conversation_messages m
 JOIN conversations c ON m.conversation_id = c.id
 WHERE m.id = $1;
 
+-- name: clear-conversation-waiting-since
+UPDATE conversations SET waiting_since = NULL WHERE id = $1;
+
 -- name: update-message-status
 update conversation_messages set status = $1, updated_at = now() where uuid = $2;
 
+-- name: set-conversation-custom-attribute
+UPDATE conversations
+SET custom_attributes = custom_attributes || jsonb_build_object($2::TEXT, $3::TEXT),
+    updated_at = now()
+WHERE uuid = $1;
+
 -- name: remove-conversation-assignee
+-- Conversations without the assignee are left as is.
 UPDATE conversations
 SET 
     assigned_user_id = CASE WHEN $2 = 'user' THEN NULL ELSE assigned_user_id END,
     assigned_team_id = CASE WHEN $2 = 'team' THEN NULL ELSE assigned_team_id END,
     updated_at = now()
-WHERE uuid = $1;
+WHERE uuid = $1
+AND (($2 = 'user' AND assigned_user_id IS NOT NULL) OR ($2 = 'team' AND assigned_team_id IS NOT NULL));
 
 -- name: re-open-conversation
--- Open conversation if it is not already open.
+-- Open conversation with the status $2 if its status is not in the open category, custom open statuses are left as is.
 UPDATE conversations
-SET status_id = (SELECT id FROM conversation_statuses WHERE name = 'Open'), snoozed_until = NULL,
+SET status_id = $2, snoozed_until = NULL,
     updated_at = now()
 WHERE uuid = $1 and status_id in (
-    SELECT id FROM conversation_statuses WHERE name NOT IN ('Open')
+    SELECT id FROM conversation_statuses WHERE category != 'open'
 )
+RETURNING id;
 
 -- name: delete-conversation
-DELETE FROM conversations WHERE uuid = $1;
\ No newline at end of file
+DELETE FROM conversations WHERE uuid = $1;
+
+-- name: insert-conversation-follower
+INSERT INTO conversation_followers (user_id, conversation_id)
+VALUES ($1, (SELECT id FROM conversations WHERE uuid = $2))
+ON CONFLICT (conversation_id, user_id) DO NOTHING;
+
+-- name: delete-conversation-follower
+DELETE FROM conversation_followers
+WHERE user_id = $1 AND conversation_id = (SELECT id FROM conversations WHERE uuid = $2);
+
+-- name: get-conversation-followers
+SELECT users.id, users.first_name, users.last_name, users.avatar_url, cf.created_at
+FROM conversation_followers cf
+INNER JOIN users ON users.id = cf.user_id
+WHERE cf.conversation_id = (SELECT id FROM conversations WHERE uuid = $1)
+AND users.deleted_at IS NULL
+ORDER BY cf.created_at;
+
+-- name: get-conversation-follower-ids
+-- Returns enabled agents following the conversation, excluding the passed users.
+SELECT cf.user_id
+FROM conversation_followers cf
+INNER JOIN users ON users.id = cf.user_id
+WHERE cf.conversation_id = (SELECT id FROM conversations WHERE uuid = $1)
+AND NOT (cf.user_id = ANY($2::INT[]))
+AND users.enabled = true AND users.deleted_at IS NULL;
+
+-- name: has-agent-reply-since
+SELECT EXISTS (
+    SELECT 1 FROM conversation_messages
+    WHERE conversation_id = (SELECT id FROM conversations WHERE uuid = $1)
+    AND type = 'outgoing' AND private = false AND sender_type = 'agent'
+    AND side_thread_id IS NULL
+    AND sender_id != $2
+    AND created_at > $3
+);
+
+-- name: get-mentionables
+-- Returns the enabled agents and the teams that can be mentioned by name.
+SELECT 'agent' AS type, id, TRIM(CONCAT(first_name, ' ', last_name)) AS name
+FROM users
+WHERE type = 'agent' AND enabled AND deleted_at IS NULL
+UNION ALL
+SELECT 'team' AS type, id, name
+FROM teams;
+
+-- name: insert-conversation-mention
+INSERT INTO conversation_mentions (conversation_id, message_id, mentioned_by_id, user_id, team_id, access_expires_at)
+VALUES ((SELECT id FROM conversations WHERE uuid = $1), $2, $3, NULLIF($4, 0), NULLIF($5, 0), NOW() + ($6 * INTERVAL '1 second'));
+
+-- name: get-mentioned-user-ids
+-- Returns enabled agents mentioned in a message directly or through a team, excluding the sender.
+SELECT DISTINCT u.id
+FROM conversation_mentions cm
+LEFT JOIN team_members tm ON tm.team_id = cm.team_id
+INNER JOIN users u ON u.id = COALESCE(cm.user_id, tm.user_id)
+WHERE cm.message_id = $1
+AND u.id != $2
+AND u.type = 'agent' AND u.enabled = true AND u.deleted_at IS NULL;
+
+-- name: has-active-mention
+SELECT EXISTS (
+    SELECT 1 FROM conversation_mentions cm
+    WHERE cm.conversation_id = $1
+    AND cm.access_expires_at > NOW()
+    AND (cm.user_id = $2 OR cm.team_id IN (SELECT team_id FROM team_members WHERE user_id = $2))
+);
+
+-- name: get-user-mentions
+SELECT COUNT(*) OVER() AS total,
+    cm.id,
+    cm.created_at,
+    cm.access_expires_at,
+    c.uuid AS conversation_uuid,
+    c.reference_number AS conversation_reference_number,
+    c.subject AS conversation_subject,
+    m.uuid AS message_uuid,
+    m.text_content AS message_text_content,
+    cm.mentioned_by_id,
+    CONCAT(mb.first_name, ' ', mb.last_name) AS mentioned_by_name,
+    cm.team_id,
+    t.name AS team_name
+FROM conversation_mentions cm
+INNER JOIN conversations c ON c.id = cm.conversation_id
+INNER JOIN conversation_messages m ON m.id = cm.message_id
+INNER JOIN users mb ON mb.id = cm.mentioned_by_id
+LEFT JOIN teams t ON t.id = cm.team_id
+WHERE cm.user_id = $1 OR cm.team_id IN (SELECT team_id FROM team_members WHERE user_id = $1)
+ORDER BY cm.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: get-conversation-draft
+SELECT d.id, d.created_at, d.updated_at, c.uuid AS conversation_uuid, d.user_id, d.content, d.cc, d.bcc, d.media_uuids
+FROM conversation_drafts d
+INNER JOIN conversations c ON c.id = d.conversation_id
+WHERE c.uuid = $1 AND d.user_id = $2;
+
+-- name: upsert-conversation-draft
+INSERT INTO conversation_drafts (conversation_id, user_id, content, cc, bcc, media_uuids)
+VALUES ((SELECT id FROM conversations WHERE uuid = $1), $2, $3, COALESCE($4::TEXT[], '{}'), COALESCE($5::TEXT[], '{}'), COALESCE($6::UUID[], '{}'))
+ON CONFLICT (conversation_id, user_id) DO UPDATE
+SET content = EXCLUDED.content, cc = EXCLUDED.cc, bcc = EXCLUDED.bcc, media_uuids = EXCLUDED.media_uuids, updated_at = NOW()
+RETURNING id, created_at, updated_at, $1::UUID AS conversation_uuid, user_id, content, cc, bcc, media_uuids;
+
+-- name: delete-conversation-draft
+DELETE FROM conversation_drafts
+WHERE user_id = $2 AND conversation_id = (SELECT id FROM conversations WHERE uuid = $1);
+
+-- name: update-scheduled-message
+-- Only messages that are not yet picked up for sending can be updated.
+UPDATE conversation_messages
+SET content = $4, text_content = $5, send_at = COALESCE($6, send_at), updated_at = NOW()
+WHERE uuid = $1 AND status = 'scheduled' AND send_at > NOW()
+AND conversation_id = (SELECT id FROM conversations WHERE uuid = $2)
+AND sender_id = $3
+RETURNING send_at;
+
+-- name: delete-scheduled-message
+-- Only messages that are not yet picked up for sending can be cancelled, the attachments are detached so they can be reused.
+WITH deleted AS (
+    DELETE FROM conversation_messages
+    WHERE uuid = $1 AND status = 'scheduled' AND send_at > NOW()
+    AND conversation_id = (SELECT id FROM conversations WHERE uuid = $2)
+    AND sender_id = $3
+    RETURNING id, sender_id, content, meta
+),
+detached AS (
+    UPDATE media SET model_id = NULL
+    WHERE model_type = 'messages' AND model_id = (SELECT id FROM deleted)
+    RETURNING uuid
+)
+SELECT d.content,
+    ARRAY(SELECT jsonb_array_elements_text(d.meta->'cc')) AS cc,
+    ARRAY(SELECT jsonb_array_elements_text(d.meta->'bcc')) AS bcc,
+    ARRAY(SELECT uuid::TEXT FROM detached) AS media_uuids
+FROM deleted d;
+
+-- name: insert-side-thread
+INSERT INTO conversation_side_threads (conversation_id, created_by, subject, "to", cc)
+VALUES ((SELECT id FROM conversations WHERE uuid = $1), $2, $3, $4, $5)
+RETURNING id;
+
+-- name: get-side-threads
+SELECT st.id, st.created_at, st.updated_at, st.uuid, st.created_by, st.subject, st."to", st.cc
+FROM conversation_side_threads st
+WHERE st.conversation_id = (SELECT id FROM conversations WHERE uuid = $1)
+ORDER BY st.created_at DESC;
+
+-- name: get-side-thread
+SELECT st.id, st.created_at, st.updated_at, st.uuid, st.created_by, st.subject, st."to", st.cc
+FROM conversation_side_threads st
+WHERE st.id = $1
+AND ($2 = '' OR st.conversation_id = (SELECT id FROM conversations WHERE uuid = $2::uuid));
+
+-- name: get-side-thread-messages
+SELECT
+   m.created_at,
+   m.updated_at,
+   m.status,
+   m.type,
+   m.content,
+   m.uuid,
+   m.private,
+   m.sender_id,
+   m.sender_type,
+   m.meta,
+   m.side_thread_id,
+   COALESCE(
+     (SELECT json_agg(
+       json_build_object(
+         'name', filename,
+         'content_type', content_type,
+         'uuid', uuid,
+         'size', size,
+         'content_id', content_id,
+         'disposition', disposition
+       ) ORDER BY filename
+     ) FROM media
+     WHERE model_type = 'messages' AND model_id = m.id),
+   '[]'::json) AS attachments
+FROM conversation_messages m
+WHERE m.side_thread_id = $1
+ORDER BY m.created_at ASC;
+
+-- name: get-side-thread-source-ids
+SELECT source_id
+FROM conversation_messages
+WHERE side_thread_id = $1
+AND source_id > ''
+ORDER BY id DESC
+LIMIT $2;
+
+-- name: update-side-thread-updated-at
+UPDATE conversation_side_threads SET updated_at = NOW() WHERE id = $1;
+
+-- name: get-tasks
+SELECT
+    t.id,
+    t.created_at,
+    t.updated_at,
+    c.uuid AS conversation_uuid,
+    c.reference_number,
+    c.subject,
+    t.created_by,
+    t.assigned_user_id,
+    CONCAT(u.first_name, ' ', u.last_name) AS assigned_user_name,
+    t.title,
+    t.due_at,
+    t.done_at
+FROM conversation_tasks t
+INNER JOIN conversations c ON c.id = t.conversation_id
+INNER JOIN users u ON u.id = t.assigned_user_id
+WHERE c.uuid = $1
+ORDER BY t.done_at IS NOT NULL, t.due_at;
+
+-- name: get-task
+SELECT
+    t.id,
+    t.created_at,
+    t.updated_at,
+    c.uuid AS conversation_uuid,
+    c.reference_number,
+    c.subject,
+    t.created_by,
+    t.assigned_user_id,
+    CONCAT(u.first_name, ' ', u.last_name) AS assigned_user_name,
+    t.title,
+    t.due_at,
+    t.done_at
+FROM conversation_tasks t
+INNER JOIN conversations c ON c.id = t.conversation_id
+INNER JOIN users u ON u.id = t.assigned_user_id
+WHERE t.id = $1;
+
+-- name: get-user-tasks
+-- Returns the tasks assigned to an agent, pending tasks only unless $2 is true.
+SELECT
+    t.id,
+    t.created_at,
+    t.updated_at,
+    c.uuid AS conversation_uuid,
+    c.reference_number,
+    c.subject,
+    t.created_by,
+    t.assigned_user_id,
+    CONCAT(u.first_name, ' ', u.last_name) AS assigned_user_name,
+    t.title,
+    t.due_at,
+    t.done_at
+FROM conversation_tasks t
+INNER JOIN conversations c ON c.id = t.conversation_id
+INNER JOIN users u ON u.id = t.assigned_user_id
+WHERE t.assigned_user_id = $1
+AND ($2 OR t.done_at IS NULL)
+ORDER BY t.done_at IS NOT NULL, t.due_at;
+
+-- name: insert-task
+INSERT INTO conversation_tasks (conversation_id, created_by, assigned_user_id, title, due_at)
+VALUES ((SELECT id FROM conversations WHERE uuid = $1), NULLIF($2, 0), $3, $4, $5)
+RETURNING id;
+
+-- name: update-task
+-- Moving the due time of a task notifies the assignee again when it's due.
+UPDATE conversation_tasks
+SET title = $3,
+    assigned_user_id = $4,
+    notified_at = CASE WHEN due_at != $5 THEN NULL ELSE notified_at END,
+    due_at = $5,
+    updated_at = NOW()
+WHERE id = $1 AND conversation_id = (SELECT id FROM conversations WHERE uuid = $2);
+
+-- name: update-task-done
+UPDATE conversation_tasks
+SET done_at = CASE WHEN $3 THEN COALESCE(done_at, NOW()) ELSE NULL END,
+    updated_at = NOW()
+WHERE id = $1 AND conversation_id = (SELECT id FROM conversations WHERE uuid = $2);
+
+-- name: delete-task
+DELETE FROM conversation_tasks
+WHERE id = $1 AND conversation_id = (SELECT id FROM conversations WHERE uuid = $2);
+
+-- name: mark-due-tasks-notified
+-- Marks pending tasks that are due as notified and returns them so each assignee is notified once.
+UPDATE conversation_tasks
+SET notified_at = NOW()
+WHERE done_at IS NULL AND notified_at IS NULL AND due_at <= NOW()
+RETURNING id;
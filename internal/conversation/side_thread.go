@@ -0,0 +1,209 @@
+package conversation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	mmodels "github.com/abhinavxd/libredesk/internal/media/models"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/template"
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
+
+// NotificationEventSideThreadReply is sent to the agent who started a side thread when an external party replies to it.
+const NotificationEventSideThreadReply = "side_thread_reply"
+
+// GetSideThreads returns the side threads of a conversation along with their messages.
+func (m *Manager) GetSideThreads(conversationUUID string) ([]models.SideThread, error) {
+	var threads = make([]models.SideThread, 0)
+	if err := m.q.GetSideThreads.Select(&threads, conversationUUID); err != nil {
+		m.lo.Error("error fetching side threads", "conversation_uuid", conversationUUID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error fetching side threads", nil)
+	}
+	for i := range threads {
+		messages, err := m.getSideThreadMessages(threads[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		threads[i].Messages = messages
+	}
+	return threads, nil
+}
+
+// GetSideThread returns a side thread of a conversation along with its messages.
+func (m *Manager) GetSideThread(conversationUUID string, id int) (models.SideThread, error) {
+	var thread models.SideThread
+	if err := m.q.GetSideThread.Get(&thread, id, conversationUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return thread, envelope.NewError(envelope.NotFoundError, "Side thread not found", nil)
+		}
+		m.lo.Error("error fetching side thread", "id", id, "error", err)
+		return thread, envelope.NewError(envelope.GeneralError, "Error fetching side thread", nil)
+	}
+	messages, err := m.getSideThreadMessages(thread.ID)
+	if err != nil {
+		return thread, err
+	}
+	thread.Messages = messages
+	return thread, nil
+}
+
+// CreateSideThread starts a side thread with external parties from within a conversation and sends its first message.
+func (m *Manager) CreateSideThread(media []mmodels.Media, senderID int, conversationUUID, subject string, to, cc []string, content string) (models.SideThread, error) {
+	to = stringutil.RemoveEmpty(to)
+	cc = stringutil.RemoveEmpty(cc)
+	if subject == "" {
+		return models.SideThread{}, envelope.NewError(envelope.InputError, "Empty `subject`", nil)
+	}
+	if content == "" {
+		return models.SideThread{}, envelope.NewError(envelope.InputError, "Empty `message`", nil)
+	}
+	if len(to) == 0 {
+		return models.SideThread{}, envelope.NewError(envelope.InputError, "Empty `to` address", nil)
+	}
+	for _, addr := range append(slices.Clone(to), cc...) {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return models.SideThread{}, envelope.NewError(envelope.InputError, fmt.Sprintf("Invalid email address `%s`", addr), nil)
+		}
+	}
+
+	var id int
+	if err := m.q.InsertSideThread.Get(&id, conversationUUID, senderID, subject, pq.Array(to), pq.Array(cc)); err != nil {
+		m.lo.Error("error inserting side thread", "conversation_uuid", conversationUUID, "error", err)
+		return models.SideThread{}, envelope.NewError(envelope.GeneralError, "Error creating side thread", nil)
+	}
+	if err := m.ReplyToSideThread(media, senderID, conversationUUID, id, content); err != nil {
+		return models.SideThread{}, err
+	}
+	return m.GetSideThread(conversationUUID, id)
+}
+
+// ReplyToSideThread inserts an agent message in a side thread, the message is picked up for sending right away.
+// content is plain text and is escaped before it is stored and sent as HTML.
+func (m *Manager) ReplyToSideThread(media []mmodels.Media, senderID int, conversationUUID string, id int, content string) error {
+	if strings.TrimSpace(content) == "" {
+		return envelope.NewError(envelope.InputError, "Empty `message`", nil)
+	}
+	content = stringutil.TextToHTML(content)
+
+	var thread models.SideThread
+	if err := m.q.GetSideThread.Get(&thread, id, conversationUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return envelope.NewError(envelope.NotFoundError, "Side thread not found", nil)
+		}
+		m.lo.Error("error fetching side thread", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error fetching side thread", nil)
+	}
+
+	conversation, err := m.GetConversation(0, conversationUUID)
+	if err != nil {
+		return err
+	}
+	inbox, err := m.inboxStore.GetDBRecord(conversation.InboxID)
+	if err != nil {
+		return err
+	}
+	sourceID, err := stringutil.GenerateEmailMessageID(conversationUUID, inbox.From)
+	if err != nil {
+		m.lo.Error("error generating source message id", "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error generating source message id", nil)
+	}
+
+	message := models.Message{
+		ConversationUUID: conversationUUID,
+		SenderID:         senderID,
+		Type:             MessageOutgoing,
+		SenderType:       SenderTypeAgent,
+		Status:           MessageStatusPending,
+		Content:          content,
+		ContentType:      ContentTypeHTML,
+		Private:          false,
+		Media:            media,
+		SourceID:         null.StringFrom(sourceID),
+		SideThreadID:     null.IntFrom(thread.ID),
+	}
+	return m.InsertMessage(&message)
+}
+
+// getSideThreadMessages returns the messages of a side thread, oldest first.
+func (m *Manager) getSideThreadMessages(id int) ([]models.Message, error) {
+	var messages = make([]models.Message, 0)
+	if err := m.q.GetSideThreadMessages.Select(&messages, id); err != nil {
+		m.lo.Error("error fetching side thread messages", "side_thread_id", id, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error fetching side thread messages", nil)
+	}
+	return messages, nil
+}
+
+// prepareSideThreadMessage sets the recipients, subject and threading headers of a pending side thread message.
+func (m *Manager) prepareSideThreadMessage(message *models.Message) error {
+	var thread models.SideThread
+	if err := m.q.GetSideThread.Get(&thread, message.SideThreadID.Int, ""); err != nil {
+		return fmt.Errorf("fetching side thread: %w", err)
+	}
+	message.To = thread.To
+	message.CC = thread.CC
+	message.BCC = nil
+	message.Subject = thread.Subject
+
+	// Include only the last 20 messages as references to avoid exceeding header size limits.
+	var references []string
+	if err := m.q.GetSideThreadSourceIDs.Select(&references, thread.ID, 20); err != nil {
+		m.lo.Error("error fetching side thread source IDs", "side_thread_id", thread.ID, "error", err)
+	}
+	stringutil.ReverseSlice(references)
+	message.References = stringutil.RemoveItemByValue(references, message.SourceID.String)
+	if len(message.References) > 0 {
+		message.InReplyTo = message.References[len(message.References)-1]
+	}
+	return nil
+}
+
+// findSideThreadID returns the side thread an incoming message replies to, 0 if it's not a side thread reply.
+func (m *Manager) findSideThreadID(sourceIDs []string) (int, error) {
+	sourceIDs = stringutil.RemoveEmpty(sourceIDs)
+	if len(sourceIDs) == 0 {
+		return 0, nil
+	}
+	var id int
+	if err := m.q.GetSideThreadIDBySourceID.Get(&id, pq.Array(sourceIDs)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		m.lo.Error("error fetching side thread by source id", "error", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// processSideThreadMessage stores an incoming reply in a side thread and notifies the agent who started it along with the followers.
+func (m *Manager) processSideThreadMessage(in models.IncomingMessage, sideThreadID int) error {
+	in.Message.SideThreadID = null.IntFrom(sideThreadID)
+
+	if err := m.uploadMessageAttachments(&in.Message); err != nil {
+		// Log error but continue processing.
+		m.lo.Error("error uploading message attachments", "message_source_id", in.Message.SourceID, "error", err)
+	}
+	if err := m.InsertMessage(&in.Message); err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("New side thread reply from %s.", in.Contact.FullName())
+	var thread models.SideThread
+	if err := m.q.GetSideThread.Get(&thread, sideThreadID, ""); err != nil {
+		m.lo.Error("error fetching side thread", "id", sideThreadID, "error", err)
+	} else if thread.CreatedBy.Valid {
+		if conversation, err := m.GetConversation(0, in.Message.ConversationUUID); err == nil {
+			m.notifyAgents([]int{thread.CreatedBy.Int}, conversation, NotificationEventSideThreadReply, summary, in.Message.TextContent, template.TmplConversationUpdated)
+		}
+	}
+	m.notifyFollowers(in.Message.ConversationUUID, FollowerEventIncomingMessage, summary, in.Message.TextContent, int(thread.CreatedBy.Int))
+	return nil
+}
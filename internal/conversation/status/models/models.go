@@ -9,8 +9,32 @@ var DefaultStatuses = []string{
 	"Closed",
 }
 
+// Status categories decide how a status behaves, e.g. a custom "Waiting on vendor" status in the pending category pauses SLAs like Snoozed.
+const (
+	CategoryOpen    = "open"
+	CategoryPending = "pending"
+	CategorySolved  = "solved"
+	CategoryClosed  = "closed"
+)
+
+// Categories is the list of valid status categories.
+var Categories = []string{
+	CategoryOpen,
+	CategoryPending,
+	CategorySolved,
+	CategoryClosed,
+}
+
 type Status struct {
 	ID        int       `db:"id" json:"id"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	Name      string    `db:"name" json:"name"`
+	Category  string    `db:"category" json:"category"`
+	// IsSnooze marks the status conversations are snoozed with.
+	IsSnooze bool `db:"is_snooze" json:"is_snooze"`
+}
+
+// IsDone returns true if the status category marks the conversation as done, i.e. solved or closed.
+func (s Status) IsDone() bool {
+	return s.Category == CategorySolved || s.Category == CategoryClosed
 }
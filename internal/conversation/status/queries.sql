@@ -1,21 +1,55 @@
 -- name: get-status
 select id, 
     created_at,
-    name
+    name,
+    category,
+    is_snooze
 from conversation_statuses
 where id = $1;
 
+-- name: get-status-by-name
+select id,
+    created_at,
+    name,
+    category,
+    is_snooze
+from conversation_statuses
+where name = $1;
+
+-- name: get-status-by-category
+-- Returns the oldest status of the category.
+select id,
+    created_at,
+    name,
+    category,
+    is_snooze
+from conversation_statuses
+where category = $1
+order by id
+limit 1;
+
+-- name: get-snooze-status
+select id,
+    created_at,
+    name,
+    category,
+    is_snooze
+from conversation_statuses
+where is_snooze;
+
 -- name: get-all-statuses
 select id, 
     created_at,
-    name
+    name,
+    category,
+    is_snooze
 from conversation_statuses;
 
 -- name: insert-status
-INSERT into conversation_statuses(name) values ($1);
+INSERT into conversation_statuses(name, category) values ($1, $2);
 
 -- name: delete-status
 DELETE from conversation_statuses where id = $1;
 
 -- name: update-status
-UPDATE conversation_statuses set name = $2 where id = $1;
\ No newline at end of file
+UPDATE conversation_statuses set name = $2, category = $3, updated_at = now() where id = $1;
@@ -2,7 +2,9 @@
 package status
 
 import (
+	"database/sql"
 	"embed"
+	"errors"
 	"slices"
 
 	"github.com/abhinavxd/libredesk/internal/conversation/status/models"
@@ -31,11 +33,14 @@ type Opts struct {
 
 // queries contains prepared SQL queries.
 type queries struct {
-	GetStatus      *sqlx.Stmt `query:"get-status"`
-	GetAllStatuses *sqlx.Stmt `query:"get-all-statuses"`
-	InsertStatus   *sqlx.Stmt `query:"insert-status"`
-	DeleteStatus   *sqlx.Stmt `query:"delete-status"`
-	UpdateStatus   *sqlx.Stmt `query:"update-status"`
+	GetStatus       *sqlx.Stmt `query:"get-status"`
+	GetStatusByName *sqlx.Stmt `query:"get-status-by-name"`
+	GetByCategory   *sqlx.Stmt `query:"get-status-by-category"`
+	GetSnoozeStatus *sqlx.Stmt `query:"get-snooze-status"`
+	GetAllStatuses  *sqlx.Stmt `query:"get-all-statuses"`
+	InsertStatus    *sqlx.Stmt `query:"insert-status"`
+	DeleteStatus    *sqlx.Stmt `query:"delete-status"`
+	UpdateStatus    *sqlx.Stmt `query:"update-status"`
 }
 
 // New creates and returns a new instance of the Manager.
@@ -60,9 +65,15 @@ func (m *Manager) GetAll() ([]models.Status, error) {
 	return statuses, nil
 }
 
-// Create creates a new status.
-func (m *Manager) Create(name string) error {
-	if _, err := m.q.InsertStatus.Exec(name); err != nil {
+// Create creates a new status, the category defaults to open.
+func (m *Manager) Create(name, category string) error {
+	if category == "" {
+		category = models.CategoryOpen
+	}
+	if !slices.Contains(models.Categories, category) {
+		return envelope.NewError(envelope.InputError, "Invalid status `category`", nil)
+	}
+	if _, err := m.q.InsertStatus.Exec(name, category); err != nil {
 		m.lo.Error("error inserting status", "error", err)
 		return envelope.NewError(envelope.GeneralError, "Error creating status", nil)
 	}
@@ -91,8 +102,15 @@ func (m *Manager) Delete(id int) error {
 	return nil
 }
 
-// Update updates a status by id.
-func (m *Manager) Update(id int, name string) error {
+// Update updates the name and category of a status by id.
+func (m *Manager) Update(id int, name, category string) error {
+	if category == "" {
+		category = models.CategoryOpen
+	}
+	if !slices.Contains(models.Categories, category) {
+		return envelope.NewError(envelope.InputError, "Invalid status `category`", nil)
+	}
+
 	// Disallow updating of default statuses.
 	status, err := m.Get(id)
 	if err != nil {
@@ -103,7 +121,7 @@ func (m *Manager) Update(id int, name string) error {
 		return envelope.NewError(envelope.InputError, "Cannot update default status", nil)
 	}
 
-	if _, err := m.q.UpdateStatus.Exec(id, name); err != nil {
+	if _, err := m.q.UpdateStatus.Exec(id, name, category); err != nil {
 		m.lo.Error("error updating status", "error", err)
 		return envelope.NewError(envelope.GeneralError, "Error updating status", nil)
 	}
@@ -119,3 +137,42 @@ func (m *Manager) Get(id int) (models.Status, error) {
 	}
 	return status, nil
 }
+
+// GetByName retrieves a status by name.
+func (m *Manager) GetByName(name string) (models.Status, error) {
+	var status models.Status
+	if err := m.q.GetStatusByName.Get(&status, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status, envelope.NewError(envelope.NotFoundError, "Status not found", nil)
+		}
+		m.lo.Error("error fetching status", "name", name, "error", err)
+		return status, envelope.NewError(envelope.GeneralError, "Error fetching status", nil)
+	}
+	return status, nil
+}
+
+// GetByCategory retrieves the oldest status of a category.
+func (m *Manager) GetByCategory(category string) (models.Status, error) {
+	var status models.Status
+	if err := m.q.GetByCategory.Get(&status, category); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status, envelope.NewError(envelope.NotFoundError, "Status not found", nil)
+		}
+		m.lo.Error("error fetching status", "category", category, "error", err)
+		return status, envelope.NewError(envelope.GeneralError, "Error fetching status", nil)
+	}
+	return status, nil
+}
+
+// GetSnooze retrieves the status conversations are snoozed with.
+func (m *Manager) GetSnooze() (models.Status, error) {
+	var status models.Status
+	if err := m.q.GetSnoozeStatus.Get(&status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status, envelope.NewError(envelope.NotFoundError, "Snooze status not found", nil)
+		}
+		m.lo.Error("error fetching snooze status", "error", err)
+		return status, envelope.NewError(envelope.GeneralError, "Error fetching status", nil)
+	}
+	return status, nil
+}
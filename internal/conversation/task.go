@@ -0,0 +1,203 @@
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/template"
+	umodels "github.com/abhinavxd/libredesk/internal/user/models"
+)
+
+const (
+	// NotificationEventTaskDue is sent to the assignee of a task when it's due.
+	NotificationEventTaskDue = "task_due"
+
+	// maxTaskReminderInterval keeps task reminders accurate to the minute.
+	maxTaskReminderInterval = time.Minute
+
+	maxTaskTitleLen = 1000
+)
+
+// GetTasks returns the tasks of a conversation, pending tasks first.
+func (m *Manager) GetTasks(conversationUUID string) ([]models.Task, error) {
+	var tasks = make([]models.Task, 0)
+	if err := m.q.GetTasks.Select(&tasks, conversationUUID); err != nil {
+		m.lo.Error("error fetching tasks", "conversation_uuid", conversationUUID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error fetching tasks", nil)
+	}
+	return tasks, nil
+}
+
+// GetTask returns a task by ID.
+func (m *Manager) GetTask(id int) (models.Task, error) {
+	var task models.Task
+	if err := m.q.GetTask.Get(&task, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return task, envelope.NewError(envelope.NotFoundError, "Task not found", nil)
+		}
+		m.lo.Error("error fetching task", "id", id, "error", err)
+		return task, envelope.NewError(envelope.GeneralError, "Error fetching task", nil)
+	}
+	return task, nil
+}
+
+// GetUserTasks returns the tasks assigned to an agent across conversations, done tasks are included only if includeDone is set.
+func (m *Manager) GetUserTasks(userID int, includeDone bool) ([]models.Task, error) {
+	var tasks = make([]models.Task, 0)
+	if err := m.q.GetUserTasks.Select(&tasks, userID, includeDone); err != nil {
+		m.lo.Error("error fetching user tasks", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error fetching tasks", nil)
+	}
+	return tasks, nil
+}
+
+// CreateTask adds a task to a conversation assigned to an agent.
+func (m *Manager) CreateTask(conversationUUID string, createdBy, assigneeID int, title string, dueAt time.Time) (models.Task, error) {
+	title = strings.TrimSpace(title)
+	if err := m.validateTask(assigneeID, title, dueAt); err != nil {
+		return models.Task{}, err
+	}
+
+	var id int
+	if err := m.q.InsertTask.Get(&id, conversationUUID, createdBy, assigneeID, title, dueAt); err != nil {
+		m.lo.Error("error inserting task", "conversation_uuid", conversationUUID, "error", err)
+		return models.Task{}, envelope.NewError(envelope.GeneralError, "Error creating task", nil)
+	}
+	return m.GetTask(id)
+}
+
+// UpdateTask updates the title, assignee and due time of a task.
+func (m *Manager) UpdateTask(id int, conversationUUID string, assigneeID int, title string, dueAt time.Time) (models.Task, error) {
+	title = strings.TrimSpace(title)
+	if err := m.validateTask(assigneeID, title, dueAt); err != nil {
+		return models.Task{}, err
+	}
+
+	res, err := m.q.UpdateTask.Exec(id, conversationUUID, title, assigneeID, dueAt)
+	if err != nil {
+		m.lo.Error("error updating task", "id", id, "error", err)
+		return models.Task{}, envelope.NewError(envelope.GeneralError, "Error updating task", nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Task{}, envelope.NewError(envelope.NotFoundError, "Task not found", nil)
+	}
+	return m.GetTask(id)
+}
+
+// SetTaskDone marks a task as done or pending.
+func (m *Manager) SetTaskDone(id int, conversationUUID string, done bool) (models.Task, error) {
+	res, err := m.q.UpdateTaskDone.Exec(id, conversationUUID, done)
+	if err != nil {
+		m.lo.Error("error updating task done state", "id", id, "error", err)
+		return models.Task{}, envelope.NewError(envelope.GeneralError, "Error updating task", nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Task{}, envelope.NewError(envelope.NotFoundError, "Task not found", nil)
+	}
+	return m.GetTask(id)
+}
+
+// DeleteTask deletes a task of a conversation.
+func (m *Manager) DeleteTask(id int, conversationUUID string) error {
+	res, err := m.q.DeleteTask.Exec(id, conversationUUID)
+	if err != nil {
+		m.lo.Error("error deleting task", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error deleting task", nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.NotFoundError, "Task not found", nil)
+	}
+	return nil
+}
+
+// RunTaskReminder notifies assignees of due tasks at the start of every interval, the interval is capped at a minute.
+func (m *Manager) RunTaskReminder(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || interval > maxTaskReminderInterval {
+		interval = maxTaskReminderInterval
+	}
+
+	timer := time.NewTimer(time.Until(time.Now().Truncate(interval).Add(interval)))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			m.notifyDueTasks(ctx)
+			timer.Reset(time.Until(time.Now().Truncate(interval).Add(interval)))
+		}
+	}
+}
+
+// notifyDueTasks notifies the assignees of pending tasks that are due, each task is notified once.
+func (m *Manager) notifyDueTasks(ctx context.Context) {
+	var ids []int
+	if err := m.q.MarkDueTasksNotified.SelectContext(ctx, &ids); err != nil {
+		m.lo.Error("error fetching due tasks", "error", err)
+		return
+	}
+	for _, id := range ids {
+		task, err := m.GetTask(id)
+		if err != nil {
+			continue
+		}
+		conversation, err := m.GetConversation(0, task.ConversationUUID)
+		if err != nil {
+			continue
+		}
+		m.notifyAgents([]int{task.AssignedUserID}, conversation, NotificationEventTaskDue, fmt.Sprintf("Task due: %s", task.Title), task.Title, template.TmplConversationUpdated)
+	}
+}
+
+// validateTask validates the assignee, title and due time of a task.
+func (m *Manager) validateTask(assigneeID int, title string, dueAt time.Time) error {
+	if title == "" {
+		return envelope.NewError(envelope.InputError, "Empty task `title`", nil)
+	}
+	if len(title) > maxTaskTitleLen {
+		return envelope.NewError(envelope.InputError, "Task title is too long", nil)
+	}
+	if dueAt.IsZero() {
+		return envelope.NewError(envelope.InputError, "Empty task `due_at`", nil)
+	}
+	if assigneeID <= 0 {
+		return envelope.NewError(envelope.InputError, "Empty task `assigned_user_id`", nil)
+	}
+	if _, err := m.userStore.GetAgent(assigneeID); err != nil {
+		return envelope.NewError(envelope.InputError, "Task assignee not found", nil)
+	}
+	return nil
+}
+
+// createTaskFromAction creates a task from the create_task automation action.
+// The action values are the task title, the duration until it's due, e.g. "24h", and optionally the assignee ID,
+// the task is assigned to the conversation assignee if no assignee is set.
+func (m *Manager) createTaskFromAction(values []string, conv models.Conversation, user umodels.User) error {
+	if len(values) < 2 {
+		return fmt.Errorf("create_task action requires a title and a due duration")
+	}
+	dueIn, err := time.ParseDuration(values[1])
+	if err != nil || dueIn < 0 {
+		return fmt.Errorf("invalid create_task due duration %q", values[1])
+	}
+
+	assigneeID := conv.AssignedUserID.Int
+	if len(values) > 2 && values[2] != "" {
+		if assigneeID, err = strconv.Atoi(values[2]); err != nil {
+			return fmt.Errorf("invalid create_task assignee %q", values[2])
+		}
+	}
+	if assigneeID == 0 {
+		return fmt.Errorf("create_task action has no assignee and the conversation is unassigned")
+	}
+
+	_, err = m.CreateTask(conv.UUID, user.ID, assigneeID, values[0], time.Now().Add(dueIn))
+	return err
+}
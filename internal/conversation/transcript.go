@@ -0,0 +1,176 @@
+package conversation
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/abhinavxd/libredesk/internal/attachment"
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	notifier "github.com/abhinavxd/libredesk/internal/notification"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/template"
+	"github.com/knadh/smtppool"
+)
+
+// maxTranscriptAttachmentsSize is the total size of attachments included in EML and emailed transcripts,
+// attachments beyond it are only listed in the transcript.
+const maxTranscriptAttachmentsSize = 20 * 1024 * 1024
+
+// transcript is a rendered conversation transcript.
+type transcript struct {
+	conversation models.Conversation
+	messages     []models.TranscriptMessage
+	subject      string
+	html         string
+}
+
+// GetTranscriptHTML returns a printable HTML transcript of a conversation with messages, attachments list and activity log.
+func (m *Manager) GetTranscriptHTML(uuid string, includePrivate bool) (string, error) {
+	t, err := m.renderTranscript(uuid, includePrivate)
+	if err != nil {
+		return "", err
+	}
+	return t.html, nil
+}
+
+// GetTranscriptEML returns the transcript of a conversation as a multipart EML file with message attachments attached.
+func (m *Manager) GetTranscriptEML(uuid string, includePrivate bool) ([]byte, error) {
+	t, err := m.renderTranscript(uuid, includePrivate)
+	if err != nil {
+		return nil, err
+	}
+
+	inbox, err := m.inboxStore.GetDBRecord(t.conversation.InboxID)
+	if err != nil {
+		m.lo.Error("error fetching inbox for transcript", "uuid", uuid, "inbox_id", t.conversation.InboxID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error exporting transcript", nil)
+	}
+
+	em := smtppool.Email{
+		From:    inbox.From,
+		Subject: t.subject,
+		HTML:    []byte(t.html),
+		Text:    []byte(stringutil.HTML2Text(t.html)),
+	}
+	for _, a := range m.getTranscriptAttachments(t.messages) {
+		em.Attachments = append(em.Attachments, smtppool.Attachment{
+			Filename: a.Name,
+			Header:   a.Header,
+			Content:  a.Content,
+		})
+	}
+	if t.conversation.Contact.Email.String != "" {
+		em.To = []string{t.conversation.Contact.Email.String}
+	}
+
+	b, err := em.Bytes()
+	if err != nil {
+		m.lo.Error("error building transcript EML", "uuid", uuid, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error exporting transcript", nil)
+	}
+	return b, nil
+}
+
+// SendTranscript emails the transcript of a conversation to the passed addresses, or to the contact if none are passed.
+func (m *Manager) SendTranscript(uuid string, includePrivate bool, to []string) error {
+	t, err := m.renderTranscript(uuid, includePrivate)
+	if err != nil {
+		return err
+	}
+
+	to = stringutil.RemoveEmpty(to)
+	for _, addr := range to {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return envelope.NewError(envelope.InputError, fmt.Sprintf("Invalid email address `%s`", addr), nil)
+		}
+	}
+	if len(to) == 0 {
+		if t.conversation.Contact.Email.String == "" {
+			return envelope.NewError(envelope.InputError, "Contact has no email address", nil)
+		}
+		to = []string{t.conversation.Contact.Email.String}
+	}
+
+	if err := m.notifier.Send(notifier.Message{
+		To:          to,
+		Subject:     t.subject,
+		Content:     t.html,
+		AltContent:  stringutil.HTML2Text(t.html),
+		Attachments: m.getTranscriptAttachments(t.messages),
+		Provider:    notifier.ProviderEmail,
+	}); err != nil {
+		m.lo.Error("error sending transcript", "uuid", uuid, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error sending transcript", nil)
+	}
+	return nil
+}
+
+// renderTranscript fetches the conversation and its messages and renders the transcript template.
+func (m *Manager) renderTranscript(uuid string, includePrivate bool) (transcript, error) {
+	var t transcript
+	conversation, err := m.GetConversation(0, uuid)
+	if err != nil {
+		return t, err
+	}
+	t.conversation = conversation
+
+	t.messages = make([]models.TranscriptMessage, 0)
+	if err := m.q.GetTranscriptMessages.Select(&t.messages, uuid, includePrivate); err != nil {
+		m.lo.Error("error fetching transcript messages", "uuid", uuid, "error", err)
+		return t, envelope.NewError(envelope.GeneralError, "Error fetching messages", nil)
+	}
+
+	t.html, err = m.template.RenderInMemoryTemplate(template.TmplTranscript, map[string]any{
+		"Conversation": map[string]any{
+			"ReferenceNumber": conversation.ReferenceNumber,
+			"Subject":         conversation.Subject.String,
+			"InboxName":       conversation.InboxName,
+			"Status":          conversation.Status.String,
+			"Priority":        conversation.Priority.String,
+			"CreatedAt":       conversation.CreatedAt.Format("Jan 02, 2006 15:04 MST"),
+		},
+		"Contact": map[string]any{
+			"FullName": conversation.Contact.FullName(),
+			"Email":    conversation.Contact.Email.String,
+		},
+		"Messages": t.messages,
+	})
+	if err != nil {
+		m.lo.Error("error rendering transcript", "uuid", uuid, "error", err)
+		return t, envelope.NewError(envelope.GeneralError, "Error rendering transcript", nil)
+	}
+
+	t.subject = fmt.Sprintf("Transcript of conversation #%s", conversation.ReferenceNumber)
+	if conversation.Subject.String != "" {
+		t.subject += " - " + conversation.Subject.String
+	}
+	return t, nil
+}
+
+// getTranscriptAttachments fetches the attachments of transcript messages up to maxTranscriptAttachmentsSize.
+func (m *Manager) getTranscriptAttachments(messages []models.TranscriptMessage) []attachment.Attachment {
+	var (
+		attachments = make([]attachment.Attachment, 0)
+		total       = 0
+	)
+	for _, msg := range messages {
+		for _, a := range msg.Attachments {
+			if a.Disposition == attachment.DispositionInline || total+a.Size > maxTranscriptAttachmentsSize {
+				continue
+			}
+			blob, err := m.mediaStore.GetBlob(a.UUID)
+			if err != nil {
+				m.lo.Error("error fetching media blob for transcript", "uuid", a.UUID, "error", err)
+				continue
+			}
+			total += len(blob)
+			attachments = append(attachments, attachment.Attachment{
+				Name:    a.Name,
+				Content: blob,
+				Header:  attachment.MakeHeader(a.ContentType, a.ContentID, a.Name, "base64", attachment.DispositionAttachment),
+			})
+		}
+	}
+	return attachments
+}
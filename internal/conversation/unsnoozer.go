@@ -4,31 +4,114 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/abhinavxd/libredesk/internal/conversation/models"
+	smodels "github.com/abhinavxd/libredesk/internal/conversation/status/models"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/template"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
+	"github.com/volatiletech/null/v9"
+)
+
+const (
+	// NotificationEventSnoozeEnded is sent to the assignee when a snoozed conversation wakes up.
+	NotificationEventSnoozeEnded = "snooze_ended"
+
+	// maxUnsnoozeInterval keeps snooze wake ups accurate to the minute.
+	maxUnsnoozeInterval = time.Minute
 )
 
-// RunUnsnoozer runs the conversation unsnoozer.
+// RunUnsnoozer runs the conversation unsnoozer at the start of every interval, the interval is capped at a minute.
 func (c *Manager) RunUnsnoozer(ctx context.Context, unsnoozeInterval time.Duration) {
-	ticker := time.NewTicker(unsnoozeInterval)
-	defer ticker.Stop()
+	if unsnoozeInterval <= 0 || unsnoozeInterval > maxUnsnoozeInterval {
+		unsnoozeInterval = maxUnsnoozeInterval
+	}
+
+	// Align runs to the interval so conversations snoozed until a minute wake up right at that minute.
+	timer := time.NewTimer(time.Until(time.Now().Truncate(unsnoozeInterval).Add(unsnoozeInterval)))
+	defer timer.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-timer.C:
 			c.unsnoozeAll(ctx)
+			timer.Reset(time.Until(time.Now().Truncate(unsnoozeInterval).Add(unsnoozeInterval)))
 		}
 	}
 }
 
-// unsnoozeAll unsnoozes all snoozed conversations.
+// unsnoozeAll unsnoozes all snoozed conversations that are due and notifies their assignees.
 func (c *Manager) unsnoozeAll(ctx context.Context) {
-	res, err := c.q.UnsnoozeAll.ExecContext(ctx)
+	status, err := c.statusStore.GetByCategory(smodels.CategoryOpen)
 	if err != nil {
-		c.lo.Error("error unsnoozing all conversations", err)
+		c.lo.Error("error fetching open status for unsnoozing", "error", err)
+		return
+	}
+	var uuids []string
+	if err := c.q.UnsnoozeAll.SelectContext(ctx, &uuids, status.ID); err != nil {
+		c.lo.Error("error unsnoozing all conversations", "error", err)
+		return
+	}
+	if len(uuids) == 0 {
 		return
 	}
-	rows, _ := res.RowsAffected()
-	if rows > 0 {
-		c.lo.Info(fmt.Sprintf("unsnoozed %d conversations", rows))
+	c.lo.Info(fmt.Sprintf("unsnoozed %d conversations", len(uuids)))
+
+	for _, uuid := range uuids {
+		c.BroadcastConversationUpdate(uuid, "status", status.Name)
+		conversation, err := c.GetConversation(0, uuid)
+		if err != nil {
+			continue
+		}
+		if err := c.slaStore.ResumeSLA(conversation.ID); err != nil {
+			c.lo.Error("error resuming SLA", "uuid", uuid, "error", err)
+		}
+		c.triggerWebhook(wmodels.EventConversationStatusChange, conversation.ID, uuid, nil)
+		c.notifySnoozeEnded(conversation, "Snooze ended, the conversation has been reopened.")
+	}
+}
+
+// notifySnoozeEnded notifies the assigned agent that a snoozed conversation has been reopened.
+func (c *Manager) notifySnoozeEnded(conversation models.Conversation, summary string) {
+	if !conversation.AssignedUserID.Valid {
+		return
+	}
+	c.notifyAgents([]int{conversation.AssignedUserID.Int}, conversation, NotificationEventSnoozeEnded, summary, "", template.TmplConversationUpdated)
+}
+
+// getSnoozeWakeTime returns when a conversation snoozed until the passed value should be reopened.
+// An invalid time is returned when snoozing until the customer replies, as only an incoming message reopens the conversation.
+func (c *Manager) getSnoozeWakeTime(uuid, snoozeUntil string) (null.Time, error) {
+	switch snoozeUntil {
+	case models.SnoozeUntilCustomerReply:
+		return null.Time{}, nil
+	case models.SnoozeUntilNextBusinessDay:
+		conversation, err := c.GetConversation(0, uuid)
+		if err != nil {
+			return null.Time{}, err
+		}
+		wakeAt, err := c.slaStore.NextBusinessDayStart(time.Now(), conversation.AssignedTeamID.Int)
+		if err != nil {
+			c.lo.Error("error calculating next business day", "uuid", uuid, "error", err)
+			return null.Time{}, envelope.NewError(envelope.InputError, "Error calculating next business day, check if business hours are configured", nil)
+		}
+		return null.TimeFrom(wakeAt), nil
+	}
+
+	// Snooze until a specific time.
+	if wakeAt, err := time.Parse(time.RFC3339, snoozeUntil); err == nil {
+		if !wakeAt.After(time.Now()) {
+			return null.Time{}, envelope.NewError(envelope.InputError, "Snooze time must be in the future", nil)
+		}
+		return null.TimeFrom(wakeAt), nil
+	}
+
+	// Snooze for a duration.
+	duration, err := time.ParseDuration(snoozeUntil)
+	if err != nil || duration <= 0 {
+		c.lo.Error("error parsing snooze duration", "snooze_until", snoozeUntil, "error", err)
+		return null.Time{}, envelope.NewError(envelope.InputError, "Invalid snooze duration format", nil)
 	}
+	return null.TimeFrom(time.Now().Add(duration)), nil
 }
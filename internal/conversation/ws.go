@@ -20,6 +20,7 @@ func (m *Manager) BroadcastNewMessage(message *cmodels.Message) {
 			"private":           message.Private,
 			"type":              message.Type,
 			"sender_type":       message.SenderType,
+			"side_thread_id":    message.SideThreadID,
 		},
 	})
 }
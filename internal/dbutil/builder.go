@@ -3,7 +3,9 @@ package dbutil
 import (
 	"encoding/json"
 	"fmt"
+	"regexp/syntax"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -27,6 +29,8 @@ type Filter struct {
 	Field    string `json:"field"`
 	Operator string `json:"operator"`
 	Value    string `json:"value"`
+	// CaseSensitiveMatch makes the `matches regex` operator case sensitive.
+	CaseSensitiveMatch bool `json:"case_sensitive_match"`
 }
 
 // AllowedFields is a map of model names to a list of allowed fields for that model.
@@ -126,6 +130,63 @@ func buildWhereClause(filters []Filter, existingArgs []interface{}, allowedField
 			conditions = append(conditions, fmt.Sprintf("%s BETWEEN $%d AND $%d", field, paramCount, paramCount+1))
 			args = append(args, strings.TrimSpace(values[0]), strings.TrimSpace(values[1]))
 			paramCount += 2
+		case "greater than":
+			conditions = append(conditions, field+fmt.Sprintf(" > $%d", paramCount))
+			args = append(args, f.Value)
+			paramCount++
+		case "less than":
+			conditions = append(conditions, field+fmt.Sprintf(" < $%d", paramCount))
+			args = append(args, f.Value)
+			paramCount++
+		case "contains", "not contains", "starts with", "ends with":
+			pattern := escapeLike(f.Value)
+			switch f.Operator {
+			case "contains", "not contains":
+				pattern = "%" + pattern + "%"
+			case "starts with":
+				pattern = pattern + "%"
+			case "ends with":
+				pattern = "%" + pattern
+			}
+			op := "ILIKE"
+			if f.Operator == "not contains" {
+				op = "NOT ILIKE"
+			}
+			conditions = append(conditions, fmt.Sprintf("%s::text %s $%d", field, op, paramCount))
+			args = append(args, pattern)
+			paramCount++
+		case "in list":
+			values := strings.Split(f.Value, ",")
+			placeholders := make([]string, len(values))
+			for i, v := range values {
+				placeholders[i] = fmt.Sprintf("$%d", paramCount)
+				args = append(args, strings.TrimSpace(v))
+				paramCount++
+			}
+			conditions = append(conditions, field+" IN ("+strings.Join(placeholders, ",")+")")
+		case "matches regex":
+			if err := ValidateRegex(f.Value); err != nil {
+				return "", nil, fmt.Errorf("invalid regex: %v", err)
+			}
+			op := "~*"
+			if f.CaseSensitiveMatch {
+				op = "~"
+			}
+			conditions = append(conditions, fmt.Sprintf("%s::text %s $%d", field, op, paramCount))
+			args = append(args, f.Value)
+			paramCount++
+		case "within last hours", "not within last hours":
+			hours, err := strconv.Atoi(strings.TrimSpace(f.Value))
+			if err != nil || hours <= 0 {
+				return "", nil, fmt.Errorf("%s requires a number of hours", f.Operator)
+			}
+			op := ">="
+			if f.Operator == "not within last hours" {
+				op = "<"
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s NOW() - make_interval(hours => $%d)", field, op, paramCount))
+			args = append(args, hours)
+			paramCount++
 		default:
 			return "", nil, fmt.Errorf("invalid operator: %s", f.Operator)
 		}
@@ -137,3 +198,64 @@ func buildWhereClause(filters []Filter, existingArgs []interface{}, allowedField
 
 	return strings.Join(conditions, " AND "), args, nil
 }
+
+// escapeLike escapes the LIKE wildcard characters in a value.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// maxRegexRepeat is the largest repetition count Postgres accepts in a regex.
+const maxRegexRepeat = 255
+
+// ValidateRegex checks that a pattern compiles and only uses syntax that means the same to Go and Postgres,
+// as regex conditions are matched by Go in automation rules and by Postgres in views.
+func ValidateRegex(pattern string) error {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return err
+	}
+	if err := checkRegexRepeats(re); err != nil {
+		return err
+	}
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			if strings.IndexByte("bBAzpPQEC", pattern[i]) >= 0 {
+				return fmt.Errorf("`\\%c` is not supported", pattern[i])
+			}
+		case inClass && strings.HasPrefix(pattern[i:], "[:"):
+			// Skip named classes like [:alpha:] so their closing bracket doesn't end the class.
+			if end := strings.Index(pattern[i+2:], ":]"); end >= 0 {
+				i += end + 3
+			}
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+			// A leading `]` or `^]` is a literal bracket.
+			if strings.HasPrefix(pattern[i+1:], "^]") {
+				i += 2
+			} else if strings.HasPrefix(pattern[i+1:], "]") {
+				i++
+			}
+		case c == '(' && strings.HasPrefix(pattern[i+1:], "?") && !strings.HasPrefix(pattern[i+1:], "?:"):
+			return fmt.Errorf("flags and named groups are not supported")
+		}
+	}
+	return nil
+}
+
+// checkRegexRepeats checks that the repetition counts in a parsed regex are within what Postgres accepts.
+func checkRegexRepeats(re *syntax.Regexp) error {
+	if re.Op == syntax.OpRepeat && (re.Min > maxRegexRepeat || re.Max > maxRegexRepeat) {
+		return fmt.Errorf("repetition counts over %d are not supported", maxRegexRepeat)
+	}
+	for _, sub := range re.Sub {
+		if err := checkRegexRepeats(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -179,6 +179,15 @@ func (m *Manager) Delete(name string) error {
 	return nil
 }
 
+// DeleteFile deletes a file from the store, leaving its media record. Files that do not exist are ignored.
+func (m *Manager) DeleteFile(name string) error {
+	if err := m.store.Delete(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		m.lo.Error("error deleting media from store", "name", name, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error deleting media from store", nil)
+	}
+	return nil
+}
+
 // DeleteUnlinkedMedia is a blocking function that periodically deletes media files that are not linked to any conversation message.
 func (m *Manager) DeleteUnlinkedMedia(ctx context.Context) {
 	m.deleteUnlinkedMessageMedia()
@@ -48,7 +48,9 @@ SELECT id, created_at, "uuid", store, filename, content_type, model_id, model_ty
 FROM media
 WHERE model_type = 'messages' 
   AND (model_id IS NULL OR model_id = 0) 
-  AND created_at < NOW() - INTERVAL '1 day';
+  AND created_at < NOW() - INTERVAL '1 day'
+  -- Media attached to unsent reply drafts.
+  AND NOT EXISTS (SELECT 1 FROM conversation_drafts d WHERE d.media_uuids @> ARRAY[media.uuid]);
 
 -- name: content-id-exists
 SELECT uuid FROM media WHERE content_id = $1;
\ No newline at end of file
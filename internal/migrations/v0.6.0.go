@@ -0,0 +1,487 @@
+package migrations
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/knadh/koanf/v2"
+	"github.com/knadh/stuffbin"
+)
+
+// V0_6_0 updates the database schema to v0.6.0.
+func V0_6_0(db *sqlx.DB, fs stuffbin.FileSystem, ko *koanf.Koanf) error {
+	// Retention policies.
+	_, err := db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'retention_action') THEN
+				CREATE TYPE "retention_action" AS ENUM ('delete_conversations', 'strip_attachments');
+			END IF;
+		END$$;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS retention_policies (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"name" TEXT NOT NULL,
+			inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+			action retention_action NOT NULL,
+			days INT NOT NULL,
+			enabled BOOL DEFAULT TRUE NOT NULL,
+			CONSTRAINT constraint_retention_policies_on_name CHECK (length("name") <= 140),
+			CONSTRAINT constraint_retention_policies_on_days CHECK (days > 0)
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS index_unique_retention_policies_on_inbox_id_and_action ON retention_policies (COALESCE(inbox_id, 0), action);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS retention_logs (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			policy_id INT REFERENCES retention_policies(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			action retention_action NOT NULL,
+			conversation_uuid UUID NOT NULL,
+			conversation_reference_number TEXT NULL,
+			media_count INT DEFAULT 0 NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_retention_logs_on_created_at ON retention_logs(created_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Applied SLAs and CSAT responses are kept for reports when a conversation is deleted.
+	_, err = db.Exec(`
+		ALTER TABLE applied_slas ALTER COLUMN conversation_id DROP NOT NULL;
+		ALTER TABLE csat_responses ALTER COLUMN conversation_id DROP NOT NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Conversation followers.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_followers (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS index_unique_conversation_followers_on_conversation_id_and_user_id ON conversation_followers (conversation_id, user_id);
+		CREATE INDEX IF NOT EXISTS index_conversation_followers_on_user_id ON conversation_followers (user_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Notification template for conversation followers.
+	_, err = db.Exec(`
+		INSERT INTO templates ("type", body, is_default, "name", subject, is_builtin)
+		SELECT 'email_notification'::template_type, $1, false, 'Conversation updated', 'Update on conversation #{{ .Conversation.ReferenceNumber }}', true
+		WHERE NOT EXISTS (SELECT 1 FROM templates WHERE "name" = 'Conversation updated');
+	`, `
+<p>Hi {{ .Recipient.FirstName }},</p>
+
+<p>{{ .Event.Summary }}</p>
+
+<div>
+    Reference number: {{ .Conversation.ReferenceNumber }} <br>
+    Subject: {{ .Conversation.Subject }}
+</div>
+
+{{ if .Event.Content }}
+<blockquote>{{ .Event.Content }}</blockquote>
+{{ end }}
+
+<p>
+    <a href="{{ RootURL }}/inboxes/all/conversation/{{ .Conversation.UUID }}">View Conversation</a>
+</p>
+
+<div>
+    Best regards,<br>
+    Libredesk
+</div>
+
+`)
+	if err != nil {
+		return err
+	}
+
+	// Mentions in private notes.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_mentions (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			message_id BIGINT REFERENCES conversation_messages(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			mentioned_by_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+			team_id INT REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+			access_expires_at TIMESTAMPTZ NOT NULL,
+			CONSTRAINT constraint_conversation_mentions_on_user_id_or_team_id CHECK ((user_id IS NULL) <> (team_id IS NULL))
+		);
+		CREATE INDEX IF NOT EXISTS index_conversation_mentions_on_conversation_id ON conversation_mentions (conversation_id);
+		CREATE INDEX IF NOT EXISTS index_conversation_mentions_on_user_id ON conversation_mentions (user_id);
+		CREATE INDEX IF NOT EXISTS index_conversation_mentions_on_team_id ON conversation_mentions (team_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO templates ("type", body, is_default, "name", subject, is_builtin)
+		SELECT 'email_notification'::template_type, $1, false, 'Mentioned in conversation', 'You were mentioned in conversation #{{ .Conversation.ReferenceNumber }}', true
+		WHERE NOT EXISTS (SELECT 1 FROM templates WHERE "name" = 'Mentioned in conversation');
+	`, `
+<p>Hi {{ .Recipient.FirstName }},</p>
+
+<p>{{ .Event.Summary }}</p>
+
+<div>
+    Reference number: {{ .Conversation.ReferenceNumber }} <br>
+    Subject: {{ .Conversation.Subject }}
+</div>
+
+{{ if .Event.Content }}
+<blockquote>{{ .Event.Content }}</blockquote>
+{{ end }}
+
+<p>
+    <a href="{{ RootURL }}/inboxes/all/conversation/{{ .Conversation.UUID }}">View Conversation</a>
+</p>
+
+<div>
+    Best regards,<br>
+    Libredesk
+</div>
+
+`)
+	if err != nil {
+		return err
+	}
+
+	// Reply drafts.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_drafts (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			"content" TEXT DEFAULT '' NOT NULL,
+			cc TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			bcc TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			media_uuids UUID[] DEFAULT '{}'::UUID[] NOT NULL
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS index_unique_conversation_drafts_on_conversation_id_and_user_id ON conversation_drafts (conversation_id, user_id);
+		CREATE INDEX IF NOT EXISTS index_gin_conversation_drafts_on_media_uuids ON conversation_drafts USING GIN (media_uuids);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Scheduled messages.
+	_, err = db.Exec(`ALTER TYPE message_status ADD VALUE IF NOT EXISTS 'scheduled';`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`ALTER TABLE conversation_messages ADD COLUMN IF NOT EXISTS send_at TIMESTAMPTZ NULL;`)
+	if err != nil {
+		return err
+	}
+
+	// Forwarded messages.
+	_, err = db.Exec(`ALTER TYPE message_type ADD VALUE IF NOT EXISTS 'forward';`)
+	if err != nil {
+		return err
+	}
+
+	// Side threads.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_side_threads (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"uuid" UUID DEFAULT gen_random_uuid() NOT NULL UNIQUE,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			created_by BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			subject TEXT NOT NULL,
+			"to" TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			cc TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			CONSTRAINT constraint_conversation_side_threads_on_subject CHECK (length(subject) <= 1000)
+		);
+		CREATE INDEX IF NOT EXISTS index_conversation_side_threads_on_conversation_id ON conversation_side_threads (conversation_id);
+		ALTER TABLE conversation_messages ADD COLUMN IF NOT EXISTS side_thread_id BIGINT REFERENCES conversation_side_threads(id) ON DELETE CASCADE ON UPDATE CASCADE NULL;
+		CREATE INDEX IF NOT EXISTS index_conversation_messages_on_side_thread_id ON conversation_messages (side_thread_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Time tracking.
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'time_entry_source') THEN
+				CREATE TYPE "time_entry_source" AS ENUM ('manual', 'timer', 'auto');
+			END IF;
+		END$$;
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_time_entries (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			source time_entry_source NOT NULL,
+			started_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			ended_at TIMESTAMPTZ NULL,
+			duration INT DEFAULT 0 NOT NULL,
+			note TEXT DEFAULT '' NOT NULL,
+			billable BOOL DEFAULT FALSE NOT NULL,
+			CONSTRAINT constraint_conversation_time_entries_on_duration CHECK (duration >= 0),
+			CONSTRAINT constraint_conversation_time_entries_on_note CHECK (length(note) <= 1000)
+		);
+		CREATE INDEX IF NOT EXISTS index_conversation_time_entries_on_conversation_id ON conversation_time_entries (conversation_id);
+		CREATE INDEX IF NOT EXISTS index_conversation_time_entries_on_started_at ON conversation_time_entries (started_at);
+		CREATE UNIQUE INDEX IF NOT EXISTS index_unique_conversation_time_entries_on_user_id_when_running ON conversation_time_entries (user_id) WHERE ended_at IS NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Conversation tasks.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_tasks (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			created_by BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			assigned_user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			title TEXT NOT NULL,
+			due_at TIMESTAMPTZ NOT NULL,
+			done_at TIMESTAMPTZ NULL,
+			notified_at TIMESTAMPTZ NULL,
+			CONSTRAINT constraint_conversation_tasks_on_title CHECK (length(title) <= 1000)
+		);
+		CREATE INDEX IF NOT EXISTS index_conversation_tasks_on_conversation_id ON conversation_tasks (conversation_id);
+		CREATE INDEX IF NOT EXISTS index_conversation_tasks_on_assigned_user_id ON conversation_tasks (assigned_user_id);
+		CREATE INDEX IF NOT EXISTS index_conversation_tasks_on_due_at_when_pending ON conversation_tasks (due_at) WHERE done_at IS NULL AND notified_at IS NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Priority colour, rank and SLA policy.
+	_, err = db.Exec(`
+		ALTER TABLE conversation_priorities ADD COLUMN IF NOT EXISTS color TEXT DEFAULT '' NOT NULL;
+		ALTER TABLE conversation_priorities ADD COLUMN IF NOT EXISTS "rank" INT DEFAULT 0 NOT NULL;
+		ALTER TABLE conversation_priorities ADD COLUMN IF NOT EXISTS sla_policy_id INT REFERENCES sla_policies(id) ON DELETE SET NULL ON UPDATE CASCADE NULL;
+		ALTER TABLE conversation_priorities DROP CONSTRAINT IF EXISTS constraint_conversation_priorities_on_name;
+		ALTER TABLE conversation_priorities ADD CONSTRAINT constraint_conversation_priorities_on_name CHECK (length("name") <= 140);
+		ALTER TABLE conversation_priorities DROP CONSTRAINT IF EXISTS constraint_conversation_priorities_on_color;
+		ALTER TABLE conversation_priorities ADD CONSTRAINT constraint_conversation_priorities_on_color CHECK (length(color) <= 50);
+	`)
+	if err != nil {
+		return err
+	}
+	// Rank existing priorities in the order they were created, which is Low, Medium, High for default installs.
+	_, err = db.Exec(`
+		UPDATE conversation_priorities p
+		SET "rank" = r.rank
+		FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY id) AS rank FROM conversation_priorities) r
+		WHERE p.id = r.id
+		AND NOT EXISTS (SELECT 1 FROM conversation_priorities WHERE "rank" != 0);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Status categories.
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'status_category') THEN
+				CREATE TYPE "status_category" AS ENUM ('open', 'pending', 'solved', 'closed');
+			END IF;
+		END$$;
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		ALTER TABLE conversation_statuses ADD COLUMN IF NOT EXISTS category status_category DEFAULT 'open' NOT NULL;
+		UPDATE conversation_statuses SET category = 'pending' WHERE name = 'Snoozed';
+		UPDATE conversation_statuses SET category = 'solved' WHERE name = 'Resolved';
+		UPDATE conversation_statuses SET category = 'closed' WHERE name = 'Closed';
+		ALTER TABLE conversation_statuses ADD COLUMN IF NOT EXISTS is_snooze BOOL DEFAULT FALSE NOT NULL;
+		UPDATE conversation_statuses SET is_snooze = TRUE WHERE name = 'Snoozed';
+		CREATE UNIQUE INDEX IF NOT EXISTS index_conversation_statuses_on_is_snooze ON conversation_statuses(is_snooze) WHERE is_snooze;
+		ALTER TABLE applied_slas ADD COLUMN IF NOT EXISTS paused_at TIMESTAMPTZ NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Outgoing webhook deliveries.
+	_, err = db.Exec(`
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'webhook_delivery_status') THEN
+				CREATE TYPE "webhook_delivery_status" AS ENUM ('pending', 'delivered', 'failed');
+			END IF;
+		END$$;
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"event" TEXT NOT NULL,
+			url TEXT NOT NULL,
+			headers JSONB DEFAULT '{}'::jsonb NOT NULL,
+			secret TEXT DEFAULT '' NOT NULL,
+			payload TEXT NOT NULL,
+			conversation_uuid UUID NULL,
+			status webhook_delivery_status DEFAULT 'pending' NOT NULL,
+			attempts INT DEFAULT 0 NOT NULL,
+			next_attempt_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			response_status INT NULL,
+			last_error TEXT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_webhook_deliveries_on_status_and_next_attempt_at ON webhook_deliveries(status, next_attempt_at);
+		CREATE INDEX IF NOT EXISTS index_webhook_deliveries_on_created_at ON webhook_deliveries(created_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Webhook subscriptions.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			"name" TEXT NOT NULL,
+			url TEXT NOT NULL,
+			secret TEXT DEFAULT '' NOT NULL,
+			events TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+			headers JSONB DEFAULT '{}'::jsonb NOT NULL,
+			enabled BOOL DEFAULT TRUE NOT NULL,
+			CONSTRAINT constraint_webhooks_on_name CHECK (length("name") <= 140)
+		);
+		ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS webhook_id INT REFERENCES webhooks(id) ON DELETE CASCADE ON UPDATE CASCADE NULL;
+		CREATE INDEX IF NOT EXISTS index_webhook_deliveries_on_webhook_id ON webhook_deliveries(webhook_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Automation rule execution log.
+	_, err = db.Exec(`
+		ALTER TABLE automation_rules ADD COLUMN IF NOT EXISTS "version" INT DEFAULT 1 NOT NULL;
+		CREATE TABLE IF NOT EXISTS automation_rule_executions (
+			id BIGSERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			rule_id INT REFERENCES automation_rules(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+			rule_name TEXT NOT NULL,
+			rule_version INT NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			"event" TEXT NOT NULL,
+			actions JSONB DEFAULT '[]'::jsonb NOT NULL,
+			has_errors BOOL DEFAULT FALSE NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS index_automation_rule_executions_on_rule_id ON automation_rule_executions(rule_id);
+		CREATE INDEX IF NOT EXISTS index_automation_rule_executions_on_conversation_id ON automation_rule_executions(conversation_id);
+		CREATE INDEX IF NOT EXISTS index_automation_rule_executions_on_created_at ON automation_rule_executions(created_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Scheduled time trigger rules.
+	_, err = db.Exec(`
+		ALTER TABLE automation_rules ADD COLUMN IF NOT EXISTS schedule TEXT DEFAULT '' NOT NULL;
+		ALTER TABLE automation_rules ADD COLUMN IF NOT EXISTS last_run_at TIMESTAMPTZ NULL;
+		CREATE TABLE IF NOT EXISTS automation_rule_triggers (
+			rule_id INT REFERENCES automation_rules(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+			rule_version INT NOT NULL,
+			triggered_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+			PRIMARY KEY (rule_id, conversation_id)
+		);
+		CREATE INDEX IF NOT EXISTS index_automation_rule_triggers_on_conversation_id ON automation_rule_triggers(conversation_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Automation rules disabled by loop protection.
+	_, err = db.Exec(`
+		ALTER TABLE automation_rules ADD COLUMN IF NOT EXISTS disabled_reason TEXT DEFAULT '' NOT NULL;
+		CREATE INDEX IF NOT EXISTS index_automation_rule_executions_on_conversation_id_and_created_at ON automation_rule_executions(conversation_id, created_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Clear snooze times left on conversations that are no longer snoozed.
+	_, err = db.Exec(`
+		UPDATE conversations SET snoozed_until = NULL
+		WHERE snoozed_until IS NOT NULL
+		AND status_id NOT IN (SELECT id FROM conversation_statuses WHERE is_snooze);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Admin role gets new permissions.
+	_, err = db.Exec(`
+		UPDATE roles
+		SET permissions = array_append(permissions, 'retention:manage')
+		WHERE name = 'Admin' AND NOT ('retention:manage' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		UPDATE roles
+		SET permissions = array_append(permissions, 'priority:manage')
+		WHERE name = 'Admin' AND NOT ('priority:manage' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		UPDATE roles
+		SET permissions = array_append(permissions, 'webhooks:manage')
+		WHERE name = 'Admin' AND NOT ('webhooks:manage' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		UPDATE roles
+		SET permissions = array_append(permissions, 'conversations:update_custom_attributes')
+		WHERE name IN ('Admin', 'Agent') AND NOT ('conversations:update_custom_attributes' = ANY(permissions));
+	`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -17,6 +17,8 @@ const (
 type Message struct {
 	// Recipients of the message
 	UserIDs []int
+	// Recipient addresses that are not users, e.g. contacts
+	To []string
 	// Subject of the message
 	Subject string
 	// Body of the message
@@ -46,6 +46,7 @@ func (e *Email) Send(msg notifier.Message) error {
 	if err != nil {
 		return err
 	}
+	recipientEmails = append(recipientEmails, msg.To...)
 	emailMessage := e.prepareEmail(msg.Subject, msg.Content, recipientEmails, msg)
 	return e.send(emailMessage)
 }
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
+
+const (
+	// ActionDeleteConversations deletes closed conversations older than the policy days.
+	ActionDeleteConversations = "delete_conversations"
+	// ActionStripAttachments deletes attachments of messages older than the policy days.
+	ActionStripAttachments = "strip_attachments"
+)
+
+// Policy represents a data retention policy.
+// A policy without an inbox is global and applies to all inboxes that don't have their own policy for the same action.
+type Policy struct {
+	ID        int       `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	Name      string    `db:"name" json:"name"`
+	InboxID   null.Int  `db:"inbox_id" json:"inbox_id"`
+	Action    string    `db:"action" json:"action"`
+	Days      int       `db:"days" json:"days"`
+	Enabled   bool      `db:"enabled" json:"enabled"`
+}
+
+// Log represents a single removal performed by a retention policy.
+type Log struct {
+	ID                    int         `db:"id" json:"id"`
+	CreatedAt             time.Time   `db:"created_at" json:"created_at"`
+	PolicyID              null.Int    `db:"policy_id" json:"policy_id"`
+	Action                string      `db:"action" json:"action"`
+	ConversationUUID      string      `db:"conversation_uuid" json:"conversation_uuid"`
+	ConversationReference null.String `db:"conversation_reference_number" json:"conversation_reference_number"`
+	MediaCount            int         `db:"media_count" json:"media_count"`
+	Total                 int         `db:"total" json:"-"`
+}
+
+// Conversation is a conversation picked up for purge by a retention policy.
+type Conversation struct {
+	ID              int    `db:"id"`
+	UUID            string `db:"uuid"`
+	ReferenceNumber string `db:"reference_number"`
+}
+
+// Media is a media file picked up for removal by a retention policy.
+type Media struct {
+	ID               int    `db:"id"`
+	UUID             string `db:"uuid"`
+	ConversationID   int    `db:"conversation_id"`
+	ConversationUUID string `db:"conversation_uuid"`
+	ReferenceNumber  string `db:"reference_number"`
+}
@@ -0,0 +1,103 @@
+-- name: get-all-policies
+SELECT id, created_at, updated_at, name, inbox_id, action, days, enabled
+FROM retention_policies
+ORDER BY updated_at DESC;
+
+-- name: get-enabled-policies
+-- Inbox specific policies first so they're applied before the global ones.
+SELECT id, created_at, updated_at, name, inbox_id, action, days, enabled
+FROM retention_policies
+WHERE enabled = true
+ORDER BY inbox_id NULLS LAST, id;
+
+-- name: get-policy
+SELECT id, created_at, updated_at, name, inbox_id, action, days, enabled
+FROM retention_policies
+WHERE id = $1;
+
+-- name: insert-policy
+INSERT INTO retention_policies (name, inbox_id, action, days, enabled)
+VALUES ($1, NULLIF($2, 0), $3, $4, $5)
+RETURNING id, created_at, updated_at, name, inbox_id, action, days, enabled;
+
+-- name: update-policy
+UPDATE retention_policies
+SET name = $2,
+    inbox_id = NULLIF($3, 0),
+    action = $4,
+    days = $5,
+    enabled = $6,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, inbox_id, action, days, enabled;
+
+-- name: delete-policy
+DELETE FROM retention_policies WHERE id = $1;
+
+-- name: get-purgeable-conversations
+-- Conversations in a closed status older than $2 days that are not under legal hold.
+-- For global policies ($1 = 0) inboxes with their own enabled policy for the same action are skipped.
+SELECT c.id, c.uuid, c.reference_number
+FROM conversations c
+WHERE c.status_id IN (SELECT id FROM conversation_statuses WHERE category = 'closed')
+  AND COALESCE(c.closed_at, c.updated_at) < NOW() - make_interval(days => $2)
+  AND (
+    ($1 > 0 AND c.inbox_id = $1)
+    OR
+    ($1 = 0 AND NOT EXISTS (
+        SELECT 1 FROM retention_policies rp
+        WHERE rp.inbox_id = c.inbox_id AND rp.action = 'delete_conversations' AND rp.enabled = true
+    ))
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM conversation_tags ct
+    JOIN tags t ON t.id = ct.tag_id
+    WHERE ct.conversation_id = c.id AND t.name = ANY($3::TEXT[])
+  )
+ORDER BY c.id
+LIMIT $4;
+
+-- name: get-conversation-media
+SELECT m.id, m.uuid, cm.conversation_id
+FROM media m
+JOIN conversation_messages cm ON m.model_type = 'messages' AND m.model_id = cm.id
+WHERE cm.conversation_id = $1;
+
+-- name: delete-conversation
+DELETE FROM conversations WHERE id = $1;
+
+-- name: delete-media
+DELETE FROM media WHERE id = ANY($1::INT[]);
+
+-- name: get-strippable-media
+-- Message attachments older than $2 days in conversations that are not under legal hold.
+SELECT m.id, m.uuid, c.id AS conversation_id, c.uuid AS conversation_uuid, c.reference_number
+FROM media m
+JOIN conversation_messages cm ON m.model_type = 'messages' AND m.model_id = cm.id
+JOIN conversations c ON c.id = cm.conversation_id
+WHERE cm.created_at < NOW() - make_interval(days => $2)
+  AND (
+    ($1 > 0 AND c.inbox_id = $1)
+    OR
+    ($1 = 0 AND NOT EXISTS (
+        SELECT 1 FROM retention_policies rp
+        WHERE rp.inbox_id = c.inbox_id AND rp.action = 'strip_attachments' AND rp.enabled = true
+    ))
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM conversation_tags ct
+    JOIN tags t ON t.id = ct.tag_id
+    WHERE ct.conversation_id = c.id AND t.name = ANY($3::TEXT[])
+  )
+ORDER BY m.id
+LIMIT $4;
+
+-- name: insert-log
+INSERT INTO retention_logs (policy_id, action, conversation_uuid, conversation_reference_number, media_count)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: get-logs
+SELECT COUNT(*) OVER() AS total, id, created_at, policy_id, action, conversation_uuid, conversation_reference_number, media_count
+FROM retention_logs
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
@@ -0,0 +1,362 @@
+// Package retention handles data retention policies and periodically purges conversations and attachments that are past their retention period.
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/retention/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	// thumbPrefix is the prefix of the thumbnail files stored next to image attachments.
+	thumbPrefix = "thumb_"
+
+	defaultBatchSize = 500
+	defaultInterval  = time.Hour
+	maxLogsPerPage   = 100
+)
+
+type mediaStore interface {
+	DeleteFile(name string) error
+}
+
+// Manager manages retention policies and runs the purge worker.
+type Manager struct {
+	q             queries
+	db            *sqlx.DB
+	lo            *logf.Logger
+	mediaStore    mediaStore
+	batchSize     int
+	legalHoldTags pq.StringArray
+	wg            sync.WaitGroup
+}
+
+// Opts contains options for initializing the Manager.
+type Opts struct {
+	DB *sqlx.DB
+	Lo *logf.Logger
+	// BatchSize is the number of conversations / attachments purged per query.
+	BatchSize int
+	// LegalHoldTags are tag names that exempt a conversation from all retention policies.
+	LegalHoldTags []string
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetAllPolicies            *sqlx.Stmt `query:"get-all-policies"`
+	GetEnabledPolicies        *sqlx.Stmt `query:"get-enabled-policies"`
+	GetPolicy                 *sqlx.Stmt `query:"get-policy"`
+	InsertPolicy              *sqlx.Stmt `query:"insert-policy"`
+	UpdatePolicy              *sqlx.Stmt `query:"update-policy"`
+	DeletePolicy              *sqlx.Stmt `query:"delete-policy"`
+	GetPurgeableConversations *sqlx.Stmt `query:"get-purgeable-conversations"`
+	GetConversationMedia      *sqlx.Stmt `query:"get-conversation-media"`
+	DeleteConversation        *sqlx.Stmt `query:"delete-conversation"`
+	DeleteMedia               *sqlx.Stmt `query:"delete-media"`
+	GetStrippableMedia        *sqlx.Stmt `query:"get-strippable-media"`
+	InsertLog                 *sqlx.Stmt `query:"insert-log"`
+	GetLogs                   *sqlx.Stmt `query:"get-logs"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(media mediaStore, opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	return &Manager{
+		q:             q,
+		db:            opts.DB,
+		lo:            opts.Lo,
+		mediaStore:    media,
+		batchSize:     opts.BatchSize,
+		legalHoldTags: pq.StringArray(opts.LegalHoldTags),
+	}, nil
+}
+
+// GetAll retrieves all retention policies.
+func (m *Manager) GetAll() ([]models.Policy, error) {
+	var policies = make([]models.Policy, 0)
+	if err := m.q.GetAllPolicies.Select(&policies); err != nil {
+		m.lo.Error("error fetching retention policies", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error fetching retention policies", nil)
+	}
+	return policies, nil
+}
+
+// Get retrieves a retention policy by ID.
+func (m *Manager) Get(id int) (models.Policy, error) {
+	var policy models.Policy
+	if err := m.q.GetPolicy.Get(&policy, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return policy, envelope.NewError(envelope.NotFoundError, "Retention policy not found", nil)
+		}
+		m.lo.Error("error fetching retention policy", "error", err)
+		return policy, envelope.NewError(envelope.GeneralError, "Error fetching retention policy", nil)
+	}
+	return policy, nil
+}
+
+// Create creates a new retention policy.
+func (m *Manager) Create(p models.Policy) (models.Policy, error) {
+	var policy models.Policy
+	if err := m.q.InsertPolicy.Get(&policy, p.Name, p.InboxID.Int, p.Action, p.Days, p.Enabled); err != nil {
+		if dbutil.IsUniqueViolationError(err) {
+			return policy, envelope.NewError(envelope.ConflictError, "A retention policy with this action already exists for the inbox", nil)
+		}
+		m.lo.Error("error inserting retention policy", "error", err)
+		return policy, envelope.NewError(envelope.GeneralError, "Error creating retention policy", nil)
+	}
+	return policy, nil
+}
+
+// Update updates a retention policy by ID.
+func (m *Manager) Update(id int, p models.Policy) (models.Policy, error) {
+	var policy models.Policy
+	if err := m.q.UpdatePolicy.Get(&policy, id, p.Name, p.InboxID.Int, p.Action, p.Days, p.Enabled); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return policy, envelope.NewError(envelope.NotFoundError, "Retention policy not found", nil)
+		}
+		if dbutil.IsUniqueViolationError(err) {
+			return policy, envelope.NewError(envelope.ConflictError, "A retention policy with this action already exists for the inbox", nil)
+		}
+		m.lo.Error("error updating retention policy", "error", err)
+		return policy, envelope.NewError(envelope.GeneralError, "Error updating retention policy", nil)
+	}
+	return policy, nil
+}
+
+// Delete deletes a retention policy by ID.
+func (m *Manager) Delete(id int) error {
+	if _, err := m.q.DeletePolicy.Exec(id); err != nil {
+		m.lo.Error("error deleting retention policy", "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error deleting retention policy", nil)
+	}
+	return nil
+}
+
+// GetLogs returns a page of retention logs, most recent first.
+func (m *Manager) GetLogs(page, pageSize int) ([]models.Log, int, error) {
+	var logs = make([]models.Log, 0)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxLogsPerPage {
+		pageSize = maxLogsPerPage
+	}
+	if err := m.q.GetLogs.Select(&logs, pageSize, (page-1)*pageSize); err != nil {
+		m.lo.Error("error fetching retention logs", "error", err)
+		return logs, pageSize, envelope.NewError(envelope.GeneralError, "Error fetching retention logs", nil)
+	}
+	return logs, pageSize, nil
+}
+
+// Run is a blocking function that periodically applies all enabled retention policies.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	m.wg.Add(1)
+	defer func() {
+		m.wg.Done()
+		ticker.Stop()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.applyPolicies(ctx); err != nil {
+				m.lo.Error("error applying retention policies", "error", err)
+			}
+		}
+	}
+}
+
+// Close waits for the running purge to finish.
+func (m *Manager) Close() error {
+	m.wg.Wait()
+	return nil
+}
+
+// applyPolicies applies all enabled retention policies.
+func (m *Manager) applyPolicies(ctx context.Context) error {
+	var policies []models.Policy
+	if err := m.q.GetEnabledPolicies.SelectContext(ctx, &policies); err != nil {
+		m.lo.Error("error fetching enabled retention policies", "error", err)
+		return err
+	}
+	for _, p := range policies {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var err error
+		switch p.Action {
+		case models.ActionDeleteConversations:
+			err = m.purgeConversations(ctx, p)
+		case models.ActionStripAttachments:
+			err = m.stripAttachments(ctx, p)
+		default:
+			m.lo.Warn("unknown retention policy action", "policy_id", p.ID, "action", p.Action)
+		}
+		if err != nil {
+			m.lo.Error("error applying retention policy", "policy_id", p.ID, "action", p.Action, "error", err)
+		}
+	}
+	return nil
+}
+
+// purgeConversations deletes closed conversations matched by the policy in batches along with their attachments.
+func (m *Manager) purgeConversations(ctx context.Context, p models.Policy) error {
+	var total int
+	for {
+		var conversations []models.Conversation
+		if err := m.q.GetPurgeableConversations.SelectContext(ctx, &conversations, p.InboxID.Int, p.Days, m.legalHoldTags, m.batchSize); err != nil {
+			return err
+		}
+
+		deleted := 0
+		for _, c := range conversations {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			mediaCount, err := m.purgeConversation(ctx, c)
+			if err != nil {
+				m.lo.Error("error purging conversation", "policy_id", p.ID, "conversation_uuid", c.UUID, "error", err)
+				continue
+			}
+			m.lo.Info("purged conversation", "policy_id", p.ID, "conversation_uuid", c.UUID, "reference_number", c.ReferenceNumber, "media_count", mediaCount)
+			m.insertLog(p, c.UUID, c.ReferenceNumber, mediaCount)
+			deleted++
+		}
+		total += deleted
+
+		// Stop when the last batch was partial or nothing could be purged to avoid looping over the same rows.
+		if len(conversations) < m.batchSize || deleted == 0 {
+			break
+		}
+	}
+	if total > 0 {
+		m.lo.Info("retention policy applied", "policy_id", p.ID, "action", p.Action, "conversations", total)
+	}
+	return nil
+}
+
+// purgeConversation deletes a conversation and its attachments and returns the number of attachments deleted.
+// The attachment files are deleted only after the records are committed.
+func (m *Manager) purgeConversation(ctx context.Context, c models.Conversation) (int, error) {
+	var media []models.Media
+	if err := m.q.GetConversationMedia.SelectContext(ctx, &media, c.ID); err != nil {
+		return 0, err
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.StmtxContext(ctx, m.q.DeleteMedia).ExecContext(ctx, mediaIDs(media)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.StmtxContext(ctx, m.q.DeleteConversation).ExecContext(ctx, c.ID); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	m.deleteFiles(media)
+	return len(media), nil
+}
+
+// deleteFiles deletes the files of the passed media and their thumbnails from the store. The records are already
+// deleted, so errors are only logged.
+func (m *Manager) deleteFiles(media []models.Media) {
+	for _, med := range media {
+		for _, name := range []string{med.UUID, thumbPrefix + med.UUID} {
+			if err := m.mediaStore.DeleteFile(name); err != nil {
+				m.lo.Error("error deleting media file", "name", name, "error", err)
+			}
+		}
+	}
+}
+
+// mediaIDs returns the IDs of the passed media.
+func mediaIDs(media []models.Media) pq.Int64Array {
+	ids := make(pq.Int64Array, 0, len(media))
+	for _, med := range media {
+		ids = append(ids, int64(med.ID))
+	}
+	return ids
+}
+
+// stripAttachments deletes old message attachments matched by the policy in batches.
+func (m *Manager) stripAttachments(ctx context.Context, p models.Policy) error {
+	var total int
+	for {
+		var media []models.Media
+		if err := m.q.GetStrippableMedia.SelectContext(ctx, &media, p.InboxID.Int, p.Days, m.legalHoldTags, m.batchSize); err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Delete the records first so a failure never leaves records pointing at deleted files.
+		if _, err := m.q.DeleteMedia.ExecContext(ctx, mediaIDs(media)); err != nil {
+			return err
+		}
+		m.deleteFiles(media)
+
+		var (
+			deleted = len(media)
+			counts  = make(map[int]int)
+			convs   = make(map[int]models.Media)
+		)
+		for _, med := range media {
+			counts[med.ConversationID]++
+			convs[med.ConversationID] = med
+		}
+		for id, count := range counts {
+			c := convs[id]
+			m.lo.Info("stripped conversation attachments", "policy_id", p.ID, "conversation_uuid", c.ConversationUUID, "reference_number", c.ReferenceNumber, "media_count", count)
+			m.insertLog(p, c.ConversationUUID, c.ReferenceNumber, count)
+		}
+		total += deleted
+
+		if len(media) < m.batchSize || deleted == 0 {
+			break
+		}
+	}
+	if total > 0 {
+		m.lo.Info("retention policy applied", "policy_id", p.ID, "action", p.Action, "attachments", total)
+	}
+	return nil
+}
+
+// insertLog records a removal performed by a retention policy.
+func (m *Manager) insertLog(p models.Policy, conversationUUID, referenceNumber string, mediaCount int) {
+	if _, err := m.q.InsertLog.Exec(p.ID, p.Action, conversationUUID, referenceNumber, mediaCount); err != nil {
+		m.lo.Error("error inserting retention log", "policy_id", p.ID, "conversation_uuid", conversationUUID, "error", err)
+	}
+}
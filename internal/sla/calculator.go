@@ -117,6 +117,56 @@ func (m *Manager) CalculateDeadline(start time.Time, slaMinutes int, businessHou
 	return currentTime, nil
 }
 
+// CalculateNextBusinessDayStart returns the time business opens on the first working day after the day of start,
+// skipping holidays and days closed all day.
+func (m *Manager) CalculateNextBusinessDayStart(start time.Time, businessHours models.BusinessHours, timeZone string) (time.Time, error) {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time zone %s: %v", timeZone, err)
+	}
+
+	// If business is always open, the next business day starts at midnight.
+	currentTime := nextDay(start.In(loc), loc)
+	if businessHours.IsAlwaysOpen {
+		return currentTime, nil
+	}
+
+	var workingHours map[string]models.WorkingHours
+	if err := json.Unmarshal(businessHours.Hours, &workingHours); err != nil {
+		return time.Time{}, fmt.Errorf("could not unmarshal working hours for next business day calculation: %v", err)
+	}
+	var holidays = []models.Holiday{}
+	if err := json.Unmarshal(businessHours.Holidays, &holidays); err != nil {
+		return time.Time{}, fmt.Errorf("could not unmarshal holidays for next business day calculation: %v", err)
+	}
+	holidaysMap := make(map[string]struct{})
+	for _, holiday := range holidays {
+		holidaysMap[holiday.Date] = struct{}{}
+	}
+
+	// Look ahead at most a year.
+	for i := 0; i < 366; i++ {
+		if _, isHoliday := holidaysMap[currentTime.Format(time.DateOnly)]; isHoliday {
+			currentTime = nextDay(currentTime, loc)
+			continue
+		}
+		workHours, exists := workingHours[currentTime.Weekday().String()]
+		if !exists || workHours.ClosedAllDay {
+			currentTime = nextDay(currentTime, loc)
+			continue
+		}
+		if workHours.OpenAllDay {
+			return currentTime, nil
+		}
+		startOfWork, err := parseTime(currentTime, workHours.Open, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid open time %s for %s: %v", workHours.Open, currentTime.Weekday(), err)
+		}
+		return startOfWork, nil
+	}
+	return time.Time{}, ErrMaxIterations
+}
+
 // nextDay advances the time to the start of the next day in the specified time zone.
 func nextDay(t time.Time, loc *time.Location) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
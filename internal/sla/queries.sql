@@ -48,7 +48,8 @@ SELECT a.id, a.first_response_deadline_at, c.first_reply_at as conversation_firs
 a.resolution_deadline_at, c.resolved_at as conversation_resolved_at, c.id as conversation_id, a.first_response_met_at, a.resolution_met_at, a.first_response_breached_at, a.resolution_breached_at
 FROM applied_slas a 
 JOIN conversations c ON a.conversation_id = c.id and c.sla_policy_id = a.sla_policy_id
-WHERE a.status = 'pending'::applied_sla_status;
+WHERE a.status = 'pending'::applied_sla_status
+AND a.paused_at IS NULL;
 
 -- name: update-breach
 UPDATE applied_slas SET
@@ -67,7 +68,7 @@ WHERE id = $1;
 -- name: set-next-sla-deadline
 UPDATE conversations c
 SET next_sla_deadline_at = CASE 
-    WHEN c.status_id IN (SELECT id from conversation_statuses where name in ('Resolved', 'Closed')) THEN NULL
+    WHEN c.status_id IN (SELECT id from conversation_statuses where category in ('pending', 'solved', 'closed')) THEN NULL
     WHEN c.first_reply_at IS NOT NULL AND c.resolved_at IS NULL AND a.resolution_deadline_at IS NOT NULL THEN a.resolution_deadline_at
     WHEN c.first_reply_at IS NULL AND c.resolved_at IS NULL AND a.first_response_deadline_at IS NOT NULL THEN a.first_response_deadline_at
     WHEN a.first_response_deadline_at IS NOT NULL AND a.resolution_deadline_at IS NOT NULL THEN LEAST(a.first_response_deadline_at, a.resolution_deadline_at)
@@ -90,3 +91,26 @@ SET
   END,
   updated_at = NOW()
 WHERE applied_slas.id = $1;
+
+-- name: pause-sla
+-- Pauses the unmet SLA of a conversation.
+WITH paused AS (
+   UPDATE applied_slas a SET paused_at = NOW(), updated_at = NOW()
+   FROM conversations c
+   WHERE c.id = $1 AND a.conversation_id = c.id AND a.sla_policy_id = c.sla_policy_id
+   AND a.status = 'pending'::applied_sla_status AND a.paused_at IS NULL
+   RETURNING a.conversation_id
+)
+UPDATE conversations SET next_sla_deadline_at = NULL
+WHERE id IN (SELECT conversation_id FROM paused);
+
+-- name: resume-sla
+-- Resumes the paused SLA of a conversation, pushing unmet deadlines back by the time it was paused.
+UPDATE applied_slas SET
+   first_response_deadline_at = CASE WHEN first_response_met_at IS NULL AND first_response_breached_at IS NULL
+      THEN first_response_deadline_at + (NOW() - paused_at) ELSE first_response_deadline_at END,
+   resolution_deadline_at = CASE WHEN resolution_met_at IS NULL AND resolution_breached_at IS NULL
+      THEN resolution_deadline_at + (NOW() - paused_at) ELSE resolution_deadline_at END,
+   paused_at = NULL,
+   updated_at = NOW()
+WHERE conversation_id = $1 AND paused_at IS NOT NULL;
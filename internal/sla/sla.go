@@ -15,6 +15,7 @@ import (
 	"github.com/abhinavxd/libredesk/internal/envelope"
 	models "github.com/abhinavxd/libredesk/internal/sla/models"
 	tmodels "github.com/abhinavxd/libredesk/internal/team/models"
+	wmodels "github.com/abhinavxd/libredesk/internal/webhook/models"
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/types"
 	"github.com/volatiletech/null/v9"
@@ -38,6 +39,7 @@ type Manager struct {
 	teamStore        teamStore
 	appSettingsStore appSettingsStore
 	businessHrsStore businessHrsStore
+	webhookStore     webhookStore
 	wg               sync.WaitGroup
 	opts             Opts
 }
@@ -54,6 +56,10 @@ type Deadlines struct {
 	Resolution    time.Time
 }
 
+type webhookStore interface {
+	TriggerConversationEvent(event string, conversationID int, conversationUUID string, data map[string]any)
+}
+
 type teamStore interface {
 	Get(id int) (tmodels.Team, error)
 }
@@ -79,6 +85,8 @@ type queries struct {
 	UpdateMet          *sqlx.Stmt `query:"update-met"`
 	SetNextSLADeadline *sqlx.Stmt `query:"set-next-sla-deadline"`
 	UpdateSLAStatus    *sqlx.Stmt `query:"update-sla-status"`
+	PauseSLA           *sqlx.Stmt `query:"pause-sla"`
+	ResumeSLA          *sqlx.Stmt `query:"resume-sla"`
 }
 
 // New creates a new SLA manager.
@@ -230,6 +238,15 @@ func (m *Manager) CalculateDeadlines(startTime time.Time, slaPolicyID, assignedT
 	return deadlines, nil
 }
 
+// NextBusinessDayStart returns the time business opens on the next working day for a team, falling back to the default business hours.
+func (m *Manager) NextBusinessDayStart(start time.Time, assignedTeamID int) (time.Time, error) {
+	businessHrs, timezone, err := m.getBusinessHoursAndTimezone(assignedTeamID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return m.CalculateNextBusinessDayStart(start, businessHrs, timezone)
+}
+
 // ApplySLA applies an SLA policy to a conversation.
 func (m *Manager) ApplySLA(startTime time.Time, conversationID, assignedTeamID, slaPolicyID int) (models.SLAPolicy, error) {
 	var sla models.SLAPolicy
@@ -254,6 +271,33 @@ func (m *Manager) ApplySLA(startTime time.Time, conversationID, assignedTeamID,
 	return sla, nil
 }
 
+// SetWebhookStore sets the webhook store that SLA breaches are sent to.
+func (m *Manager) SetWebhookStore(store webhookStore) {
+	m.webhookStore = store
+}
+
+// PauseSLA pauses the SLA clock of a conversation, used while it is in a pending status.
+func (m *Manager) PauseSLA(conversationID int) error {
+	if _, err := m.q.PauseSLA.Exec(conversationID); err != nil {
+		m.lo.Error("error pausing SLA", "conversation_id", conversationID, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error pausing SLA", nil)
+	}
+	return nil
+}
+
+// ResumeSLA resumes a paused SLA clock of a conversation, unmet deadlines are pushed back by the time spent paused.
+func (m *Manager) ResumeSLA(conversationID int) error {
+	if _, err := m.q.ResumeSLA.Exec(conversationID); err != nil {
+		m.lo.Error("error resuming SLA", "conversation_id", conversationID, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error resuming SLA", nil)
+	}
+	if _, err := m.q.SetNextSLADeadline.Exec(conversationID); err != nil {
+		m.lo.Error("error setting next SLA deadline", "conversation_id", conversationID, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error resuming SLA", nil)
+	}
+	return nil
+}
+
 // Run starts the SLA evaluation loop and evaluates pending SLAs.
 func (m *Manager) Run(ctx context.Context, evalInterval time.Duration) {
 	ticker := time.NewTicker(evalInterval)
@@ -319,6 +363,7 @@ func (m *Manager) evaluateSLA(sla models.AppliedSLA) error {
 			if _, err := m.q.UpdateBreach.Exec(sla.ID, slaType); err != nil {
 				return fmt.Errorf("updating SLA breach: %w", err)
 			}
+			m.triggerBreachWebhook(sla, slaType, deadline)
 			return nil
 		}
 
@@ -328,6 +373,7 @@ func (m *Manager) evaluateSLA(sla models.AppliedSLA) error {
 				if _, err := m.q.UpdateBreach.Exec(sla.ID, slaType); err != nil {
 					return fmt.Errorf("updating SLA breach: %w", err)
 				}
+				m.triggerBreachWebhook(sla, slaType, deadline)
 			} else {
 				m.lo.Debug("SLA type met", "deadline", deadline, "met_at", metAt.Time, "sla_type", slaType)
 				if _, err := m.q.UpdateMet.Exec(sla.ID, slaType); err != nil {
@@ -366,3 +412,18 @@ func (m *Manager) evaluateSLA(sla models.AppliedSLA) error {
 
 	return nil
 }
+
+// triggerBreachWebhook sends an SLA breach of a conversation to the subscribed webhooks.
+func (m *Manager) triggerBreachWebhook(sla models.AppliedSLA, slaType string, deadline time.Time) {
+	if m.webhookStore == nil {
+		return
+	}
+	m.webhookStore.TriggerConversationEvent(wmodels.EventSLABreached, sla.ConversationID, "", map[string]any{
+		"sla": map[string]any{
+			"applied_sla_id": sla.ID,
+			"sla_policy_id":  sla.SLAPolicyID,
+			"type":           slaType,
+			"deadline":       deadline,
+		},
+	})
+}
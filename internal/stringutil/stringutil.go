@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"html"
 	"net/mail"
 	"net/url"
 	"path/filepath"
@@ -29,6 +30,12 @@ func HTML2Text(html string) string {
 	return strings.TrimSpace(html2text.HTML2Text(html))
 }
 
+// TextToHTML escapes plain text for use as HTML content, keeping its line breaks.
+func TextToHTML(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
 // SanitizeFilename sanitizes the provided filename.
 func SanitizeFilename(fName string) string {
 	// Trim whitespace.
@@ -12,10 +12,13 @@ import (
 const (
 	// Built-in templates names stored in the database.
 	TmplConversationAssigned = "Conversation assigned"
+	TmplConversationUpdated  = "Conversation updated"
+	TmplMentioned            = "Mentioned in conversation"
 
 	// Built-in templates fetched from memory stored in `static` directory.
 	TmplResetPassword = "reset-password"
 	TmplWelcome       = "welcome"
+	TmplTranscript    = "transcript"
 
 	// Template names for rendering.
 	TmplBase    = "base"
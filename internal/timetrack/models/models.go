@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/volatiletech/null/v9"
+)
+
+const (
+	// SourceManual entries are added by agents with a duration.
+	SourceManual = "manual"
+	// SourceTimer entries are tracked with the start / stop timer.
+	SourceTimer = "timer"
+	// SourceAuto entries are captured while an agent has the conversation open.
+	SourceAuto = "auto"
+
+	ReportByAgent        = "agent"
+	ReportByTeam         = "team"
+	ReportByOrganization = "organization"
+	ReportByTag          = "tag"
+)
+
+// Entry is time spent by an agent on a conversation, duration is in seconds.
+// Running timers have no end time and their duration is the time elapsed so far.
+type Entry struct {
+	ID               int       `db:"id" json:"id"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
+	ConversationUUID string    `db:"conversation_uuid" json:"conversation_uuid"`
+	UserID           int       `db:"user_id" json:"user_id"`
+	UserName         string    `db:"user_name" json:"user_name"`
+	Source           string    `db:"source" json:"source"`
+	StartedAt        time.Time `db:"started_at" json:"started_at"`
+	EndedAt          null.Time `db:"ended_at" json:"ended_at"`
+	Duration         int       `db:"duration" json:"duration"`
+	Note             string    `db:"note" json:"note"`
+	Billable         bool      `db:"billable" json:"billable"`
+}
+
+// ReportRow is the tracked time of a single agent, team, contact organization or tag, durations are in seconds.
+// The viewed duration is the time captured automatically from conversation views, it is not part of the totals.
+type ReportRow struct {
+	Key              string `db:"key" json:"key"`
+	Label            string `db:"label" json:"label"`
+	Entries          int    `db:"entries" json:"entries"`
+	TotalDuration    int    `db:"total_duration" json:"total_duration"`
+	BillableDuration int    `db:"billable_duration" json:"billable_duration"`
+	ViewedDuration   int    `db:"viewed_duration" json:"viewed_duration"`
+}
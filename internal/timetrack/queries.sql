@@ -0,0 +1,136 @@
+-- name: get-entries
+SELECT te.id, te.created_at, te.updated_at, c.uuid AS conversation_uuid, te.user_id,
+    TRIM(CONCAT(u.first_name, ' ', u.last_name)) AS user_name, te.source, te.started_at, te.ended_at,
+    CASE WHEN te.ended_at IS NULL THEN EXTRACT(EPOCH FROM NOW() - te.started_at)::INT ELSE te.duration END AS duration,
+    te.note, te.billable
+FROM conversation_time_entries te
+JOIN conversations c ON c.id = te.conversation_id
+JOIN users u ON u.id = te.user_id
+WHERE c.uuid = $1
+ORDER BY te.started_at DESC;
+
+-- name: get-entry
+SELECT te.id, te.created_at, te.updated_at, c.uuid AS conversation_uuid, te.user_id,
+    TRIM(CONCAT(u.first_name, ' ', u.last_name)) AS user_name, te.source, te.started_at, te.ended_at,
+    CASE WHEN te.ended_at IS NULL THEN EXTRACT(EPOCH FROM NOW() - te.started_at)::INT ELSE te.duration END AS duration,
+    te.note, te.billable
+FROM conversation_time_entries te
+JOIN conversations c ON c.id = te.conversation_id
+JOIN users u ON u.id = te.user_id
+WHERE te.id = $1;
+
+-- name: get-running-timer
+SELECT te.id, te.created_at, te.updated_at, c.uuid AS conversation_uuid, te.user_id,
+    TRIM(CONCAT(u.first_name, ' ', u.last_name)) AS user_name, te.source, te.started_at, te.ended_at,
+    EXTRACT(EPOCH FROM NOW() - te.started_at)::INT AS duration,
+    te.note, te.billable
+FROM conversation_time_entries te
+JOIN conversations c ON c.id = te.conversation_id
+JOIN users u ON u.id = te.user_id
+WHERE te.user_id = $1 AND te.ended_at IS NULL;
+
+-- name: insert-entry
+INSERT INTO conversation_time_entries (conversation_id, user_id, source, started_at, ended_at, duration, note, billable)
+VALUES ((SELECT id FROM conversations WHERE uuid = $1), $2, $3, $4, $5, $6, $7, $8)
+RETURNING id;
+
+-- name: insert-auto-entry
+-- Time is not captured while the agent has a timer running on the conversation, it's already tracked by the timer.
+INSERT INTO conversation_time_entries (conversation_id, user_id, source, started_at, ended_at, duration)
+SELECT c.id, $2, 'auto', $3, $4, $5
+FROM conversations c
+WHERE c.uuid = $1
+AND NOT EXISTS (
+    SELECT 1 FROM conversation_time_entries
+    WHERE conversation_id = c.id AND user_id = $2 AND ended_at IS NULL
+);
+
+-- name: update-entry
+-- The duration of a running timer is set when it's stopped.
+UPDATE conversation_time_entries
+SET duration = CASE WHEN ended_at IS NULL THEN duration ELSE $4 END,
+    ended_at = CASE WHEN ended_at IS NULL THEN NULL ELSE started_at + make_interval(secs => $4) END,
+    note = $5,
+    billable = $6,
+    updated_at = NOW()
+WHERE id = $1
+AND user_id = $2
+AND conversation_id = (SELECT id FROM conversations WHERE uuid = $3);
+
+-- name: delete-entry
+DELETE FROM conversation_time_entries
+WHERE id = $1
+AND user_id = $2
+AND conversation_id = (SELECT id FROM conversations WHERE uuid = $3);
+
+-- name: stop-timer
+UPDATE conversation_time_entries
+SET ended_at = NOW(),
+    duration = EXTRACT(EPOCH FROM NOW() - started_at)::INT,
+    updated_at = NOW()
+WHERE user_id = $1 AND ended_at IS NULL
+RETURNING id;
+
+-- name: get-report-by-agent
+-- Time captured automatically from conversation views overlaps with manual and timer entries for the same work,
+-- so it is reported separately as the viewed duration and left out of the totals.
+SELECT te.user_id::TEXT AS key,
+    TRIM(CONCAT(u.first_name, ' ', u.last_name)) AS label,
+    COUNT(*) FILTER (WHERE te.source != 'auto') AS entries,
+    COALESCE(SUM(te.duration) FILTER (WHERE te.source != 'auto'), 0) AS total_duration,
+    COALESCE(SUM(te.duration) FILTER (WHERE te.billable AND te.source != 'auto'), 0) AS billable_duration,
+    COALESCE(SUM(te.duration) FILTER (WHERE te.source = 'auto'), 0) AS viewed_duration
+FROM conversation_time_entries te
+JOIN users u ON u.id = te.user_id
+WHERE te.ended_at IS NOT NULL AND te.started_at >= $1 AND te.started_at < $2
+GROUP BY te.user_id, u.first_name, u.last_name
+ORDER BY total_duration DESC;
+
+-- name: get-report-by-team
+-- Time is attributed to the team the conversation is currently assigned to.
+SELECT COALESCE(t.id::TEXT, '') AS key,
+    COALESCE(t.name, 'Unassigned') AS label,
+    COUNT(*) FILTER (WHERE te.source != 'auto') AS entries,
+    COALESCE(SUM(te.duration) FILTER (WHERE te.source != 'auto'), 0) AS total_duration,
+    COALESCE(SUM(te.duration) FILTER (WHERE te.billable AND te.source != 'auto'), 0) AS billable_duration,
+    COALESCE(SUM(te.duration) FILTER (WHERE te.source = 'auto'), 0) AS viewed_duration
+FROM conversation_time_entries te
+JOIN conversations c ON c.id = te.conversation_id
+LEFT JOIN teams t ON t.id = c.assigned_team_id
+WHERE te.ended_at IS NOT NULL AND te.started_at >= $1 AND te.started_at < $2
+GROUP BY t.id, t.name
+ORDER BY total_duration DESC;
+
+-- name: get-report-by-organization
+-- The organization of a contact is the `organization` custom attribute, falling back to the domain of their email.
+SELECT org AS key,
+    org AS label,
+    COUNT(*) FILTER (WHERE source != 'auto') AS entries,
+    COALESCE(SUM(duration) FILTER (WHERE source != 'auto'), 0) AS total_duration,
+    COALESCE(SUM(duration) FILTER (WHERE billable AND source != 'auto'), 0) AS billable_duration,
+    COALESCE(SUM(duration) FILTER (WHERE source = 'auto'), 0) AS viewed_duration
+FROM (
+    SELECT te.duration, te.billable, te.source,
+        COALESCE(NULLIF(ct.custom_attributes->>'organization', ''), NULLIF(split_part(ct.email, '@', 2), ''), 'Unknown') AS org
+    FROM conversation_time_entries te
+    JOIN conversations c ON c.id = te.conversation_id
+    JOIN users ct ON ct.id = c.contact_id
+    WHERE te.ended_at IS NOT NULL AND te.started_at >= $1 AND te.started_at < $2
+) e
+GROUP BY org
+ORDER BY total_duration DESC;
+
+-- name: get-report-by-tag
+-- Time on a conversation with multiple tags counts towards each tag.
+SELECT t.id::TEXT AS key,
+    t.name AS label,
+    COUNT(*) FILTER (WHERE te.source != 'auto') AS entries,
+    COALESCE(SUM(te.duration) FILTER (WHERE te.source != 'auto'), 0) AS total_duration,
+    COALESCE(SUM(te.duration) FILTER (WHERE te.billable AND te.source != 'auto'), 0) AS billable_duration,
+    COALESCE(SUM(te.duration) FILTER (WHERE te.source = 'auto'), 0) AS viewed_duration
+FROM conversation_time_entries te
+JOIN conversation_tags ctg ON ctg.conversation_id = te.conversation_id
+JOIN tags t ON t.id = ctg.tag_id
+WHERE te.ended_at IS NOT NULL AND te.started_at >= $1 AND te.started_at < $2
+GROUP BY t.id, t.name
+ORDER BY total_duration DESC;
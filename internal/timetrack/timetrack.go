@@ -0,0 +1,253 @@
+// Package timetrack handles time tracked by agents on conversations and time tracking reports.
+package timetrack
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/timetrack/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	maxEntryDuration = 24 * time.Hour
+	maxNoteLen       = 1000
+
+	defaultAutoCaptureMin = 30 * time.Second
+	defaultAutoCaptureMax = time.Hour
+)
+
+// Manager manages conversation time entries.
+type Manager struct {
+	q              queries
+	lo             *logf.Logger
+	autoCaptureMin time.Duration
+	autoCaptureMax time.Duration
+}
+
+// Opts contains options for initializing the Manager.
+type Opts struct {
+	DB *sqlx.DB
+	Lo *logf.Logger
+	// AutoCaptureMin is the shortest conversation view that is captured, shorter views are ignored.
+	AutoCaptureMin time.Duration
+	// AutoCaptureMax caps a single captured conversation view, e.g. when a conversation is left open overnight.
+	AutoCaptureMax time.Duration
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetEntries              *sqlx.Stmt `query:"get-entries"`
+	GetEntry                *sqlx.Stmt `query:"get-entry"`
+	GetRunningTimer         *sqlx.Stmt `query:"get-running-timer"`
+	InsertEntry             *sqlx.Stmt `query:"insert-entry"`
+	InsertAutoEntry         *sqlx.Stmt `query:"insert-auto-entry"`
+	UpdateEntry             *sqlx.Stmt `query:"update-entry"`
+	DeleteEntry             *sqlx.Stmt `query:"delete-entry"`
+	StopTimer               *sqlx.Stmt `query:"stop-timer"`
+	GetReportByAgent        *sqlx.Stmt `query:"get-report-by-agent"`
+	GetReportByTeam         *sqlx.Stmt `query:"get-report-by-team"`
+	GetReportByOrganization *sqlx.Stmt `query:"get-report-by-organization"`
+	GetReportByTag          *sqlx.Stmt `query:"get-report-by-tag"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	if opts.AutoCaptureMin <= 0 {
+		opts.AutoCaptureMin = defaultAutoCaptureMin
+	}
+	if opts.AutoCaptureMax <= 0 {
+		opts.AutoCaptureMax = defaultAutoCaptureMax
+	}
+	return &Manager{
+		q:              q,
+		lo:             opts.Lo,
+		autoCaptureMin: opts.AutoCaptureMin,
+		autoCaptureMax: opts.AutoCaptureMax,
+	}, nil
+}
+
+// GetEntries returns the time entries of a conversation, latest first.
+func (m *Manager) GetEntries(conversationUUID string) ([]models.Entry, error) {
+	var entries = make([]models.Entry, 0)
+	if err := m.q.GetEntries.Select(&entries, conversationUUID); err != nil {
+		m.lo.Error("error fetching time entries", "conversation_uuid", conversationUUID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error fetching time entries", nil)
+	}
+	return entries, nil
+}
+
+// Get returns a time entry by ID.
+func (m *Manager) Get(id int) (models.Entry, error) {
+	var entry models.Entry
+	if err := m.q.GetEntry.Get(&entry, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entry, envelope.NewError(envelope.NotFoundError, "Time entry not found", nil)
+		}
+		m.lo.Error("error fetching time entry", "id", id, "error", err)
+		return entry, envelope.NewError(envelope.GeneralError, "Error fetching time entry", nil)
+	}
+	return entry, nil
+}
+
+// Create adds a time entry of the passed duration to a conversation, a zero start time means the time was just spent.
+func (m *Manager) Create(conversationUUID string, userID int, duration time.Duration, note string, billable bool, startedAt time.Time) (models.Entry, error) {
+	if err := validateEntry(duration, note); err != nil {
+		return models.Entry{}, err
+	}
+	if startedAt.IsZero() {
+		startedAt = time.Now().Add(-duration)
+	}
+
+	var id int
+	if err := m.q.InsertEntry.Get(&id, conversationUUID, userID, models.SourceManual, startedAt, startedAt.Add(duration), int(duration.Seconds()), note, billable); err != nil {
+		m.lo.Error("error inserting time entry", "conversation_uuid", conversationUUID, "error", err)
+		return models.Entry{}, envelope.NewError(envelope.GeneralError, "Error adding time entry", nil)
+	}
+	return m.Get(id)
+}
+
+// Update updates the duration, note and billable flag of an agent's own time entry.
+// The duration of a running timer is only set when it's stopped.
+func (m *Manager) Update(id int, conversationUUID string, userID int, duration time.Duration, note string, billable bool) (models.Entry, error) {
+	if err := validateEntry(duration, note); err != nil {
+		return models.Entry{}, err
+	}
+	res, err := m.q.UpdateEntry.Exec(id, userID, conversationUUID, int(duration.Seconds()), note, billable)
+	if err != nil {
+		m.lo.Error("error updating time entry", "id", id, "error", err)
+		return models.Entry{}, envelope.NewError(envelope.GeneralError, "Error updating time entry", nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Entry{}, envelope.NewError(envelope.NotFoundError, "Time entry not found", nil)
+	}
+	return m.Get(id)
+}
+
+// Delete deletes an agent's own time entry.
+func (m *Manager) Delete(id int, conversationUUID string, userID int) error {
+	res, err := m.q.DeleteEntry.Exec(id, userID, conversationUUID)
+	if err != nil {
+		m.lo.Error("error deleting time entry", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error deleting time entry", nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.NotFoundError, "Time entry not found", nil)
+	}
+	return nil
+}
+
+// StartTimer starts a timer for the agent on a conversation, a timer already running on any conversation is stopped first.
+func (m *Manager) StartTimer(conversationUUID string, userID int, note string, billable bool) (models.Entry, error) {
+	if err := validateEntry(0, note); err != nil {
+		return models.Entry{}, err
+	}
+	if _, err := m.q.StopTimer.Exec(userID); err != nil {
+		m.lo.Error("error stopping running timer", "user_id", userID, "error", err)
+		return models.Entry{}, envelope.NewError(envelope.GeneralError, "Error starting timer", nil)
+	}
+
+	var id int
+	if err := m.q.InsertEntry.Get(&id, conversationUUID, userID, models.SourceTimer, time.Now(), null.Time{}, 0, note, billable); err != nil {
+		if dbutil.IsUniqueViolationError(err) {
+			return models.Entry{}, envelope.NewError(envelope.ConflictError, "A timer is already running", nil)
+		}
+		m.lo.Error("error starting timer", "conversation_uuid", conversationUUID, "error", err)
+		return models.Entry{}, envelope.NewError(envelope.GeneralError, "Error starting timer", nil)
+	}
+	return m.Get(id)
+}
+
+// StopTimer stops the running timer of the agent.
+func (m *Manager) StopTimer(userID int) (models.Entry, error) {
+	var id int
+	if err := m.q.StopTimer.Get(&id, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Entry{}, envelope.NewError(envelope.NotFoundError, "No timer is running", nil)
+		}
+		m.lo.Error("error stopping timer", "user_id", userID, "error", err)
+		return models.Entry{}, envelope.NewError(envelope.GeneralError, "Error stopping timer", nil)
+	}
+	return m.Get(id)
+}
+
+// GetRunningTimer returns the running timer of the agent, nil if no timer is running.
+func (m *Manager) GetRunningTimer(userID int) (*models.Entry, error) {
+	var entry models.Entry
+	if err := m.q.GetRunningTimer.Get(&entry, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		m.lo.Error("error fetching running timer", "user_id", userID, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error fetching timer", nil)
+	}
+	return &entry, nil
+}
+
+// RecordView captures the time an agent spent viewing a conversation as an automatic time entry.
+func (m *Manager) RecordView(userID int, conversationUUID string, from, to time.Time) {
+	duration := to.Sub(from)
+	if duration < m.autoCaptureMin {
+		return
+	}
+	if duration > m.autoCaptureMax {
+		duration = m.autoCaptureMax
+		to = from.Add(duration)
+	}
+	if _, err := m.q.InsertAutoEntry.Exec(conversationUUID, userID, from, to, int(duration.Seconds())); err != nil {
+		m.lo.Error("error capturing conversation view time", "conversation_uuid", conversationUUID, "user_id", userID, "error", err)
+	}
+}
+
+// GetReport returns the time tracked between the passed times, grouped by agent, team, contact organization or tag.
+func (m *Manager) GetReport(groupBy string, from, to time.Time) ([]models.ReportRow, error) {
+	var stmt *sqlx.Stmt
+	switch groupBy {
+	case models.ReportByAgent:
+		stmt = m.q.GetReportByAgent
+	case models.ReportByTeam:
+		stmt = m.q.GetReportByTeam
+	case models.ReportByOrganization:
+		stmt = m.q.GetReportByOrganization
+	case models.ReportByTag:
+		stmt = m.q.GetReportByTag
+	default:
+		return nil, envelope.NewError(envelope.InputError, "Invalid `group_by`, should be one of agent, team, organization or tag", nil)
+	}
+	if !from.Before(to) {
+		return nil, envelope.NewError(envelope.InputError, "`from` should be before `to`", nil)
+	}
+
+	var rows = make([]models.ReportRow, 0)
+	if err := stmt.Select(&rows, from, to); err != nil {
+		m.lo.Error("error fetching time tracking report", "group_by", groupBy, "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error fetching time tracking report", nil)
+	}
+	return rows, nil
+}
+
+// validateEntry validates the duration and note of a time entry.
+func validateEntry(duration time.Duration, note string) error {
+	if duration < 0 || duration > maxEntryDuration {
+		return envelope.NewError(envelope.InputError, "Duration should be between 0 and 24 hours", nil)
+	}
+	if len(note) > maxNoteLen {
+		return envelope.NewError(envelope.InputError, "Note is too long", nil)
+	}
+	return nil
+}
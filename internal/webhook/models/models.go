@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/volatiletech/null/v9"
+)
+
+// Events webhooks can subscribe to.
+const (
+	EventConversationCreated        = "conversation.created"
+	EventConversationUserAssigned   = "conversation.user.assigned"
+	EventConversationTeamAssigned   = "conversation.team.assigned"
+	EventConversationStatusChange   = "conversation.status.change"
+	EventConversationPriorityChange = "conversation.priority.change"
+	EventMessageIncoming            = "conversation.message.incoming"
+	EventMessageOutgoing            = "conversation.message.outgoing"
+	EventSLABreached                = "conversation.sla.breached"
+	EventCSATReceived               = "conversation.csat.received"
+
+	// EventTest is sent by the send test event endpoint, webhooks can't subscribe to it.
+	EventTest = "webhook.test"
+)
+
+// Events is the list of events webhooks can subscribe to.
+var Events = []string{
+	EventConversationCreated,
+	EventConversationUserAssigned,
+	EventConversationTeamAssigned,
+	EventConversationStatusChange,
+	EventConversationPriorityChange,
+	EventMessageIncoming,
+	EventMessageOutgoing,
+	EventSLABreached,
+	EventCSATReceived,
+}
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Webhook represents an endpoint subscribed to events.
+type Webhook struct {
+	ID        int             `db:"id" json:"id"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time       `db:"updated_at" json:"updated_at"`
+	Name      string          `db:"name" json:"name"`
+	URL       string          `db:"url" json:"url"`
+	Secret    string          `db:"secret" json:"secret"`
+	Events    pq.StringArray  `db:"events" json:"events"`
+	Headers   json.RawMessage `db:"headers" json:"headers"`
+	Enabled   bool            `db:"enabled" json:"enabled"`
+}
+
+// TestResult is the outcome of sending a test event to a webhook.
+type TestResult struct {
+	ResponseStatus int    `json:"response_status"`
+	Error          string `json:"error"`
+}
+
+// Delivery represents a queued outgoing webhook request and the result of its last attempt.
+type Delivery struct {
+	ID               int             `db:"id" json:"id"`
+	CreatedAt        time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time       `db:"updated_at" json:"updated_at"`
+	Event            string          `db:"event" json:"event"`
+	URL              string          `db:"url" json:"url"`
+	Headers          json.RawMessage `db:"headers" json:"headers"`
+	Secret           string          `db:"secret" json:"-"`
+	Payload          string          `db:"payload" json:"payload"`
+	ConversationUUID null.String     `db:"conversation_uuid" json:"conversation_uuid"`
+	WebhookID        null.Int        `db:"webhook_id" json:"webhook_id"`
+	Status           string          `db:"status" json:"status"`
+	Attempts         int             `db:"attempts" json:"attempts"`
+	NextAttemptAt    time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	ResponseStatus   null.Int        `db:"response_status" json:"response_status"`
+	LastError        null.String     `db:"last_error" json:"last_error"`
+	Total            int             `db:"total" json:"-"`
+}
+
+// Request is an outgoing webhook request to be queued for delivery.
+type Request struct {
+	Event            string
+	URL              string
+	Headers          map[string]string
+	Secret           string
+	Payload          []byte
+	ConversationUUID string
+	// WebhookID is set for deliveries of webhook subscriptions.
+	WebhookID int
+}
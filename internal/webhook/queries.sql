@@ -0,0 +1,66 @@
+-- name: get-all-webhooks
+SELECT id, created_at, updated_at, "name", url, secret, events, headers, enabled FROM webhooks ORDER BY id;
+
+-- name: get-enabled-webhooks
+SELECT id, created_at, updated_at, "name", url, secret, events, headers, enabled FROM webhooks WHERE enabled = TRUE ORDER BY id;
+
+-- name: get-webhook
+SELECT id, created_at, updated_at, "name", url, secret, events, headers, enabled FROM webhooks WHERE id = $1;
+
+-- name: insert-webhook
+INSERT INTO webhooks ("name", url, secret, events, headers, enabled)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, "name", url, secret, events, headers, enabled;
+
+-- name: update-webhook
+UPDATE webhooks SET "name" = $2, url = $3, secret = $4, events = $5, headers = $6, enabled = $7, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, "name", url, secret, events, headers, enabled;
+
+-- name: delete-webhook
+DELETE FROM webhooks WHERE id = $1;
+
+-- name: insert-delivery
+INSERT INTO webhook_deliveries ("event", url, headers, secret, payload, conversation_uuid, webhook_id)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, NULLIF($7, 0));
+
+-- name: claim-due-deliveries
+-- Claims due deliveries by pushing their next attempt forward so other instances don't pick them up.
+UPDATE webhook_deliveries SET next_attempt_at = NOW() + $2::interval, updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, "event", url, headers, secret, payload, conversation_uuid, webhook_id, status, attempts, next_attempt_at, response_status, last_error;
+
+-- name: update-delivery-attempt
+UPDATE webhook_deliveries SET
+    status = $2::webhook_delivery_status,
+    attempts = attempts + 1,
+    next_attempt_at = COALESCE($3, next_attempt_at),
+    response_status = $4,
+    last_error = NULLIF($5, ''),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: get-deliveries
+-- Webhook ID 0 returns the deliveries of the send webhook automation action.
+SELECT COUNT(*) OVER() AS total, id, created_at, updated_at, "event", url, headers, payload, conversation_uuid, webhook_id, status, attempts, next_attempt_at, response_status, last_error
+FROM webhook_deliveries
+WHERE ($1 = '' OR status = $1::webhook_delivery_status)
+AND (($4 = 0 AND webhook_id IS NULL) OR webhook_id = $4)
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: retry-delivery
+-- Webhook ID 0 matches the deliveries of the send webhook automation action.
+UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status = 'failed'
+AND (($2 = 0 AND webhook_id IS NULL) OR webhook_id = $2);
+
+-- name: delete-old-deliveries
+-- Pending deliveries are kept until they are delivered or fail.
+DELETE FROM webhook_deliveries WHERE status != 'pending' AND created_at < NOW() - make_interval(secs => $1);
@@ -0,0 +1,532 @@
+// Package webhook manages webhook subscriptions to conversation events and queues outgoing webhook requests in the database,
+// delivering them with retries and exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cmodels "github.com/abhinavxd/libredesk/internal/conversation/models"
+	"github.com/abhinavxd/libredesk/internal/dbutil"
+	"github.com/abhinavxd/libredesk/internal/envelope"
+	"github.com/abhinavxd/libredesk/internal/stringutil"
+	"github.com/abhinavxd/libredesk/internal/webhook/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/volatiletech/null/v9"
+	"github.com/zerodha/logf"
+)
+
+var (
+	//go:embed queries.sql
+	efs embed.FS
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultTimeout     = 10 * time.Second
+	defaultBackoff     = 30 * time.Second
+	defaultInterval    = 10 * time.Second
+	defaultRetention   = 30 * 24 * time.Hour
+	maxBackoff         = 6 * time.Hour
+	batchSize          = 100
+	maxDeliveriesPage  = 100
+	maxErrorLen        = 1000
+	secretLen          = 32
+
+	// HeaderSignature carries the hex encoded HMAC-SHA256 of the request body, signed with the webhook secret.
+	HeaderSignature = "X-Libredesk-Signature"
+	// HeaderEvent carries the event that triggered the webhook.
+	HeaderEvent = "X-Libredesk-Event"
+	// HeaderDelivery carries the delivery ID, which stays the same across retries.
+	HeaderDelivery = "X-Libredesk-Delivery"
+)
+
+// Manager manages webhook subscriptions and queues and delivers outgoing webhooks.
+type Manager struct {
+	q                 queries
+	lo                *logf.Logger
+	client            *http.Client
+	conversationStore conversationStore
+	maxAttempts       int
+	backoff           time.Duration
+	timeout           time.Duration
+	retention         time.Duration
+	wg                sync.WaitGroup
+
+	// Enabled webhooks, reloaded whenever webhooks are changed.
+	webhooks   []models.Webhook
+	webhooksMu sync.RWMutex
+}
+
+type conversationStore interface {
+	GetConversation(id int, uuid string) (cmodels.Conversation, error)
+}
+
+// Opts contains options for initializing the Manager.
+type Opts struct {
+	DB *sqlx.DB
+	Lo *logf.Logger
+	// MaxAttempts is the number of attempts after which a delivery is marked as failed.
+	MaxAttempts int
+	// Timeout is the HTTP request timeout of a single attempt.
+	Timeout time.Duration
+	// Backoff is the wait after the first failed attempt, it doubles with every failed attempt.
+	Backoff time.Duration
+	// Retention is how long delivered and failed deliveries are kept.
+	Retention time.Duration
+}
+
+// queries contains prepared SQL queries.
+type queries struct {
+	GetAllWebhooks        *sqlx.Stmt `query:"get-all-webhooks"`
+	GetEnabledWebhooks    *sqlx.Stmt `query:"get-enabled-webhooks"`
+	GetWebhook            *sqlx.Stmt `query:"get-webhook"`
+	InsertWebhook         *sqlx.Stmt `query:"insert-webhook"`
+	UpdateWebhook         *sqlx.Stmt `query:"update-webhook"`
+	DeleteWebhook         *sqlx.Stmt `query:"delete-webhook"`
+	InsertDelivery        *sqlx.Stmt `query:"insert-delivery"`
+	ClaimDueDeliveries    *sqlx.Stmt `query:"claim-due-deliveries"`
+	UpdateDeliveryAttempt *sqlx.Stmt `query:"update-delivery-attempt"`
+	GetDeliveries         *sqlx.Stmt `query:"get-deliveries"`
+	RetryDelivery         *sqlx.Stmt `query:"retry-delivery"`
+	DeleteOldDeliveries   *sqlx.Stmt `query:"delete-old-deliveries"`
+}
+
+// New creates and returns a new instance of the Manager.
+func New(opts Opts) (*Manager, error) {
+	var q queries
+	if err := dbutil.ScanSQLFile("queries.sql", &q, opts.DB, efs); err != nil {
+		return nil, err
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBackoff
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = defaultRetention
+	}
+	m := &Manager{
+		q:           q,
+		lo:          opts.Lo,
+		client:      &http.Client{Timeout: opts.Timeout},
+		maxAttempts: opts.MaxAttempts,
+		backoff:     opts.Backoff,
+		timeout:     opts.Timeout,
+		retention:   opts.Retention,
+	}
+	m.reloadWebhooks()
+	return m, nil
+}
+
+// SetConversationStore sets the conversation store used to build conversation event payloads.
+func (m *Manager) SetConversationStore(store conversationStore) {
+	m.conversationStore = store
+}
+
+// GetAll returns all webhooks with their secrets masked.
+func (m *Manager) GetAll() ([]models.Webhook, error) {
+	var webhooks = make([]models.Webhook, 0)
+	if err := m.q.GetAllWebhooks.Select(&webhooks); err != nil {
+		m.lo.Error("error fetching webhooks", "error", err)
+		return nil, envelope.NewError(envelope.GeneralError, "Error fetching webhooks", nil)
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = maskSecret(webhooks[i].Secret)
+	}
+	return webhooks, nil
+}
+
+// Get returns a webhook by ID with its secret masked.
+func (m *Manager) Get(id int) (models.Webhook, error) {
+	webhook, err := m.get(id)
+	if err != nil {
+		return webhook, err
+	}
+	webhook.Secret = maskSecret(webhook.Secret)
+	return webhook, nil
+}
+
+// Create creates a webhook, a signing secret is generated if one isn't set. The created webhook is returned with its secret.
+func (m *Manager) Create(w models.Webhook) (models.Webhook, error) {
+	var webhook models.Webhook
+	if w.Secret == "" {
+		secret, err := stringutil.RandomAlphanumeric(secretLen)
+		if err != nil {
+			m.lo.Error("error generating webhook secret", "error", err)
+			return webhook, envelope.NewError(envelope.GeneralError, "Error creating webhook", nil)
+		}
+		w.Secret = secret
+	}
+	if err := m.q.InsertWebhook.Get(&webhook, w.Name, w.URL, w.Secret, w.Events, w.Headers, w.Enabled); err != nil {
+		m.lo.Error("error inserting webhook", "error", err)
+		return webhook, envelope.NewError(envelope.GeneralError, "Error creating webhook", nil)
+	}
+	m.reloadWebhooks()
+	return webhook, nil
+}
+
+// Update updates a webhook, an empty or masked secret keeps the current secret.
+func (m *Manager) Update(id int, w models.Webhook) (models.Webhook, error) {
+	current, err := m.get(id)
+	if err != nil {
+		return current, err
+	}
+	if w.Secret == "" || strings.Contains(w.Secret, stringutil.PasswordDummy) {
+		w.Secret = current.Secret
+	}
+	var webhook models.Webhook
+	if err := m.q.UpdateWebhook.Get(&webhook, id, w.Name, w.URL, w.Secret, w.Events, w.Headers, w.Enabled); err != nil {
+		m.lo.Error("error updating webhook", "id", id, "error", err)
+		return webhook, envelope.NewError(envelope.GeneralError, "Error updating webhook", nil)
+	}
+	m.reloadWebhooks()
+	webhook.Secret = maskSecret(webhook.Secret)
+	return webhook, nil
+}
+
+// Delete deletes a webhook along with its deliveries.
+func (m *Manager) Delete(id int) error {
+	if _, err := m.q.DeleteWebhook.Exec(id); err != nil {
+		m.lo.Error("error deleting webhook", "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error deleting webhook", nil)
+	}
+	m.reloadWebhooks()
+	return nil
+}
+
+// SendTest sends a test event to a webhook right away and returns the outcome, the request is not queued or retried.
+func (m *Manager) SendTest(id int) (models.TestResult, error) {
+	var result models.TestResult
+	webhook, err := m.get(id)
+	if err != nil {
+		return result, err
+	}
+	payload, err := json.Marshal(map[string]any{
+		"event":     models.EventTest,
+		"timestamp": time.Now(),
+		"webhook":   map[string]any{"id": webhook.ID, "name": webhook.Name},
+	})
+	if err != nil {
+		return result, envelope.NewError(envelope.GeneralError, "Error sending test event", nil)
+	}
+	code, err := m.send(context.Background(), models.Delivery{
+		Event:   models.EventTest,
+		URL:     webhook.URL,
+		Headers: webhook.Headers,
+		Secret:  webhook.Secret,
+		Payload: string(payload),
+	})
+	result.ResponseStatus = code
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+// TriggerConversationEvent queues a delivery of a conversation event to every enabled webhook subscribed to it.
+// The payload has the event, the conversation and its contact along with the passed data, e.g. the message of a message event.
+func (m *Manager) TriggerConversationEvent(event string, conversationID int, conversationUUID string, data map[string]any) {
+	webhooks := m.subscribers(event)
+	if len(webhooks) == 0 || m.conversationStore == nil {
+		return
+	}
+	conversation, err := m.conversationStore.GetConversation(conversationID, conversationUUID)
+	if err != nil {
+		m.lo.Error("error fetching conversation for webhook event", "event", event, "conversation_uuid", conversationUUID, "error", err)
+		return
+	}
+	body := map[string]any{
+		"event":        event,
+		"timestamp":    time.Now(),
+		"conversation": conversation,
+		"contact":      conversation.Contact,
+	}
+	for k, v := range data {
+		body[k] = v
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		m.lo.Error("error marshalling webhook payload", "event", event, "error", err)
+		return
+	}
+	for _, w := range webhooks {
+		var headers map[string]string
+		if len(w.Headers) > 0 {
+			json.Unmarshal(w.Headers, &headers)
+		}
+		if err := m.Enqueue(models.Request{
+			Event:            event,
+			URL:              w.URL,
+			Headers:          headers,
+			Secret:           w.Secret,
+			Payload:          payload,
+			ConversationUUID: conversation.UUID,
+			WebhookID:        w.ID,
+		}); err != nil {
+			m.lo.Error("error queuing webhook event", "event", event, "webhook_id", w.ID, "conversation_uuid", conversation.UUID, "error", err)
+		}
+	}
+}
+
+// Enqueue queues a webhook request for delivery.
+func (m *Manager) Enqueue(req models.Request) error {
+	headers, err := json.Marshal(req.Headers)
+	if err != nil {
+		return err
+	}
+	if req.Headers == nil {
+		headers = []byte("{}")
+	}
+	if _, err := m.q.InsertDelivery.Exec(req.Event, req.URL, headers, req.Secret, string(req.Payload), req.ConversationUUID, req.WebhookID); err != nil {
+		m.lo.Error("error inserting webhook delivery", "url", req.URL, "event", req.Event, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error queuing webhook", nil)
+	}
+	return nil
+}
+
+// GetDeliveries returns a page of deliveries of a webhook optionally filtered by status, most recent first.
+// Webhook ID 0 returns the deliveries of the send webhook automation action.
+func (m *Manager) GetDeliveries(webhookID int, status string, page, pageSize int) ([]models.Delivery, int, error) {
+	var deliveries = make([]models.Delivery, 0)
+	if status != "" && status != models.DeliveryStatusPending && status != models.DeliveryStatusDelivered && status != models.DeliveryStatusFailed {
+		return deliveries, pageSize, envelope.NewError(envelope.InputError, "Invalid delivery `status`", nil)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxDeliveriesPage {
+		pageSize = maxDeliveriesPage
+	}
+	if err := m.q.GetDeliveries.Select(&deliveries, status, pageSize, (page-1)*pageSize, webhookID); err != nil {
+		m.lo.Error("error fetching webhook deliveries", "error", err)
+		return deliveries, pageSize, envelope.NewError(envelope.GeneralError, "Error fetching webhook deliveries", nil)
+	}
+	return deliveries, pageSize, nil
+}
+
+// RetryDelivery queues a failed delivery of a webhook for another round of attempts, webhook ID 0 is for the send webhook automation action.
+func (m *Manager) RetryDelivery(webhookID, id int) error {
+	res, err := m.q.RetryDelivery.Exec(id, webhookID)
+	if err != nil {
+		m.lo.Error("error retrying webhook delivery", "webhook_id", webhookID, "id", id, "error", err)
+		return envelope.NewError(envelope.GeneralError, "Error retrying webhook delivery", nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return envelope.NewError(envelope.NotFoundError, "Failed webhook delivery not found", nil)
+	}
+	return nil
+}
+
+// Run is a blocking function that periodically delivers due webhooks and deletes old deliveries.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	cleanupTicker := time.NewTicker(1 * time.Hour)
+	m.wg.Add(1)
+	defer func() {
+		m.wg.Done()
+		ticker.Stop()
+		cleanupTicker.Stop()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.deliverDue(ctx); err != nil {
+				m.lo.Error("error delivering webhooks", "error", err)
+			}
+		case <-cleanupTicker.C:
+			m.deleteOldDeliveries()
+		}
+	}
+}
+
+// deleteOldDeliveries deletes delivered and failed deliveries older than the configured retention.
+func (m *Manager) deleteOldDeliveries() {
+	res, err := m.q.DeleteOldDeliveries.Exec(m.retention.Seconds())
+	if err != nil {
+		m.lo.Error("error deleting old webhook deliveries", "error", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		m.lo.Info("deleted old webhook deliveries", "count", n)
+	}
+}
+
+// Close waits for the running deliveries to finish.
+func (m *Manager) Close() error {
+	m.wg.Wait()
+	return nil
+}
+
+// deliverDue claims due deliveries in batches and attempts them.
+func (m *Manager) deliverDue(ctx context.Context) error {
+	// Claimed deliveries are hidden from other workers for a little longer than a request can take.
+	lockFor := fmt.Sprintf("%d seconds", int((m.timeout + time.Minute).Seconds()))
+	for {
+		var deliveries []models.Delivery
+		if err := m.q.ClaimDueDeliveries.SelectContext(ctx, &deliveries, batchSize, lockFor); err != nil {
+			return err
+		}
+		for _, d := range deliveries {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			m.attempt(ctx, d)
+		}
+		if len(deliveries) < batchSize {
+			return nil
+		}
+	}
+}
+
+// attempt sends a delivery and records the outcome, scheduling a retry with backoff on failure.
+func (m *Manager) attempt(ctx context.Context, d models.Delivery) {
+	var (
+		status     = models.DeliveryStatusDelivered
+		nextAt     null.Time
+		respStatus null.Int
+	)
+	code, err := m.send(ctx, d)
+	if code > 0 {
+		respStatus = null.IntFrom(code)
+	}
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+		if len(errMsg) > maxErrorLen {
+			errMsg = errMsg[:maxErrorLen]
+		}
+		status = models.DeliveryStatusPending
+		if d.Attempts+1 >= m.maxAttempts {
+			status = models.DeliveryStatusFailed
+		} else {
+			nextAt = null.TimeFrom(time.Now().Add(m.backoffFor(d.Attempts + 1)))
+		}
+		m.lo.Warn("webhook delivery attempt failed", "id", d.ID, "url", d.URL, "attempt", d.Attempts+1, "error", err)
+	}
+	if _, err := m.q.UpdateDeliveryAttempt.Exec(d.ID, status, nextAt, respStatus, errMsg); err != nil {
+		m.lo.Error("error updating webhook delivery", "id", d.ID, "error", err)
+	}
+}
+
+// send makes the HTTP request for a delivery and returns the response status code.
+func (m *Manager) send(ctx context.Context, d models.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewBufferString(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	var headers map[string]string
+	if len(d.Headers) > 0 {
+		if err := json.Unmarshal(d.Headers, &headers); err != nil {
+			return 0, fmt.Errorf("invalid headers: %w", err)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Libredesk-Webhook")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(d.ID))
+	if d.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(d.Secret, []byte(d.Payload)))
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// get returns a webhook by ID including its secret.
+func (m *Manager) get(id int) (models.Webhook, error) {
+	var webhook models.Webhook
+	if err := m.q.GetWebhook.Get(&webhook, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhook, envelope.NewError(envelope.NotFoundError, "Webhook not found", nil)
+		}
+		m.lo.Error("error fetching webhook", "id", id, "error", err)
+		return webhook, envelope.NewError(envelope.GeneralError, "Error fetching webhook", nil)
+	}
+	return webhook, nil
+}
+
+// reloadWebhooks reloads the enabled webhooks from the DB.
+func (m *Manager) reloadWebhooks() {
+	var webhooks []models.Webhook
+	if err := m.q.GetEnabledWebhooks.Select(&webhooks); err != nil {
+		m.lo.Error("error fetching enabled webhooks", "error", err)
+		return
+	}
+	m.webhooksMu.Lock()
+	m.webhooks = webhooks
+	m.webhooksMu.Unlock()
+}
+
+// subscribers returns the enabled webhooks subscribed to the event.
+func (m *Manager) subscribers(event string) []models.Webhook {
+	m.webhooksMu.RLock()
+	defer m.webhooksMu.RUnlock()
+	var out []models.Webhook
+	for _, w := range m.webhooks {
+		if slices.Contains(w.Events, event) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// maskSecret replaces a secret with dummy characters.
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return strings.Repeat(stringutil.PasswordDummy, 10)
+}
+
+// backoffFor returns the wait before the next attempt after the given number of failed attempts.
+func (m *Manager) backoffFor(attempts int) time.Duration {
+	wait := m.backoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body using the secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
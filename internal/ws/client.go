@@ -100,7 +100,35 @@ func (c *Client) processIncomingMessage(data []byte) {
 		c.SendMessage([]byte("pong"), websocket.TextMessage)
 		return
 	}
-	c.SendError("unknown incoming message type")
+
+	var msg models.IncomingMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.SendError("invalid incoming message")
+		return
+	}
+
+	switch msg.Type {
+	case models.IncomingTypeConversationView, models.IncomingTypeTyping:
+		var d models.PresenceData
+		if err := json.Unmarshal(msg.Data, &d); err != nil || d.ConversationUUID == "" {
+			c.SendError("invalid conversation presence data")
+			return
+		}
+		// Access is checked once when the client starts viewing the conversation.
+		if !c.Hub.isViewing(c, d.ConversationUUID) && c.Hub.canAccessConversation != nil && !c.Hub.canAccessConversation(c.ID, d.ConversationUUID) {
+			c.SendError("permission denied")
+			return
+		}
+		if msg.Type == models.IncomingTypeTyping {
+			c.Hub.SetTyping(c, d.ConversationUUID, d.Typing)
+		} else {
+			c.Hub.ViewConversation(c, d.ConversationUUID)
+		}
+	case models.IncomingTypeConversationLeave:
+		c.Hub.LeaveConversation(c)
+	default:
+		c.SendError("unknown incoming message type")
+	}
 }
 
 // close closes the client connection.
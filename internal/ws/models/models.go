@@ -1,14 +1,29 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Action constants for WebSocket messages.
 const (
 	MessageTypeMessagePropUpdate          = "message_prop_update"
 	MessageTypeConversationPropertyUpdate = "conversation_prop_update"
 	MessageTypeNewMessage                 = "new_message"
 	MessageTypeNewConversation            = "new_conversation"
+	MessageTypeNotification               = "notification"
+	MessageTypeConversationPresence       = "conversation_presence"
+	MessageTypeBulkJobProgress            = "bulk_job_progress"
 	MessageTypeError                      = "error"
 )
 
+// Types of messages sent by clients.
+const (
+	IncomingTypeConversationView  = "conversation_view"
+	IncomingTypeConversationLeave = "conversation_leave"
+	IncomingTypeTyping            = "typing"
+)
+
 // WSMessage represents a WS message.
 type WSMessage struct {
 	MessageType int
@@ -26,3 +41,23 @@ type BroadcastMessage struct {
 	Data  []byte `json:"data"`
 	Users []int  `json:"users"`
 }
+
+// IncomingMessage represents a message sent by a client.
+type IncomingMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// PresenceData is the data of conversation view and typing messages sent by clients.
+type PresenceData struct {
+	ConversationUUID string `json:"conversation_uuid"`
+	Typing           bool   `json:"typing"`
+}
+
+// ConversationViewer represents an agent viewing a conversation.
+type ConversationViewer struct {
+	UserID      int        `json:"user_id"`
+	Typing      bool       `json:"typing"`
+	ViewingFrom time.Time  `json:"viewing_from"`
+	TypingFrom  *time.Time `json:"typing_from"`
+}
@@ -0,0 +1,164 @@
+package ws
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/abhinavxd/libredesk/internal/ws/models"
+)
+
+// presence is the state of a client in the conversation it is viewing.
+type presence struct {
+	conversationUUID string
+	viewingFrom      time.Time
+	typingFrom       time.Time
+}
+
+// ViewConversation marks the client as viewing a conversation, a client views one conversation at a time.
+func (h *Hub) ViewConversation(client *Client, conversationUUID string) {
+	if conversationUUID == "" {
+		return
+	}
+	h.presenceMutex.Lock()
+	prev, ok := h.presence[client]
+	if ok && prev.conversationUUID == conversationUUID {
+		h.presenceMutex.Unlock()
+		return
+	}
+	h.presence[client] = &presence{conversationUUID: conversationUUID, viewingFrom: time.Now()}
+	h.presenceMutex.Unlock()
+
+	if ok {
+		h.endView(client, prev)
+		h.broadcastPresence(prev.conversationUUID)
+	}
+	h.broadcastPresence(conversationUUID)
+}
+
+// isViewing returns true if the client is viewing the conversation.
+func (h *Hub) isViewing(client *Client, conversationUUID string) bool {
+	h.presenceMutex.Lock()
+	defer h.presenceMutex.Unlock()
+	p, ok := h.presence[client]
+	return ok && p.conversationUUID == conversationUUID
+}
+
+// LeaveConversation removes the client from the conversation it is viewing.
+func (h *Hub) LeaveConversation(client *Client) {
+	h.presenceMutex.Lock()
+	prev, ok := h.presence[client]
+	delete(h.presence, client)
+	h.presenceMutex.Unlock()
+
+	if ok {
+		h.endView(client, prev)
+		h.broadcastPresence(prev.conversationUUID)
+	}
+}
+
+// endView records the time an agent spent viewing a conversation once none of their clients are viewing it anymore.
+// If another client of the agent is still viewing the conversation, the view carries over to it.
+func (h *Hub) endView(client *Client, prev *presence) {
+	if h.recordConversationView == nil {
+		return
+	}
+
+	h.presenceMutex.Lock()
+	for c, p := range h.presence {
+		if c.ID == client.ID && c != client && p.conversationUUID == prev.conversationUUID {
+			if prev.viewingFrom.Before(p.viewingFrom) {
+				p.viewingFrom = prev.viewingFrom
+			}
+			h.presenceMutex.Unlock()
+			return
+		}
+	}
+	h.presenceMutex.Unlock()
+
+	// Recording is a DB write, keep it out of the client's read loop.
+	go h.recordConversationView(client.ID, prev.conversationUUID, prev.viewingFrom, time.Now())
+}
+
+// SetTyping sets the reply typing state of the client in a conversation, viewing the conversation if not already.
+func (h *Hub) SetTyping(client *Client, conversationUUID string, typing bool) {
+	if conversationUUID == "" {
+		return
+	}
+	h.ViewConversation(client, conversationUUID)
+
+	h.presenceMutex.Lock()
+	p, ok := h.presence[client]
+	if !ok || typing == !p.typingFrom.IsZero() {
+		h.presenceMutex.Unlock()
+		return
+	}
+	if typing {
+		p.typingFrom = time.Now()
+	} else {
+		p.typingFrom = time.Time{}
+	}
+	h.presenceMutex.Unlock()
+
+	h.broadcastPresence(conversationUUID)
+}
+
+// GetConversationViewers returns the agents viewing a conversation, an agent connected from multiple devices is listed once.
+func (h *Hub) GetConversationViewers(conversationUUID string) []models.ConversationViewer {
+	h.presenceMutex.Lock()
+	defer h.presenceMutex.Unlock()
+
+	var viewers = make(map[int]*models.ConversationViewer)
+	for client, p := range h.presence {
+		if p.conversationUUID != conversationUUID {
+			continue
+		}
+		v, ok := viewers[client.ID]
+		if !ok {
+			v = &models.ConversationViewer{UserID: client.ID, ViewingFrom: p.viewingFrom}
+			viewers[client.ID] = v
+		}
+		if p.viewingFrom.Before(v.ViewingFrom) {
+			v.ViewingFrom = p.viewingFrom
+		}
+		if !p.typingFrom.IsZero() && (v.TypingFrom == nil || p.typingFrom.Before(*v.TypingFrom)) {
+			typingFrom := p.typingFrom
+			v.Typing = true
+			v.TypingFrom = &typingFrom
+		}
+	}
+
+	var out = make([]models.ConversationViewer, 0, len(viewers))
+	for _, v := range viewers {
+		out = append(out, *v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ViewingFrom.Before(out[j].ViewingFrom)
+	})
+	return out
+}
+
+// broadcastPresence sends the viewers of a conversation to every agent viewing it.
+func (h *Hub) broadcastPresence(conversationUUID string) {
+	viewers := h.GetConversationViewers(conversationUUID)
+	if len(viewers) == 0 {
+		return
+	}
+
+	userIDs := make([]int, 0, len(viewers))
+	for _, v := range viewers {
+		userIDs = append(userIDs, v.UserID)
+	}
+
+	b, err := json.Marshal(models.Message{
+		Type: models.MessageTypeConversationPresence,
+		Data: map[string]interface{}{
+			"conversation_uuid": conversationUUID,
+			"viewers":           viewers,
+		},
+	})
+	if err != nil {
+		return
+	}
+	h.BroadcastMessage(models.BroadcastMessage{Data: b, Users: userIDs})
+}
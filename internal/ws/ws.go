@@ -3,6 +3,7 @@ package ws
 
 import (
 	"sync"
+	"time"
 
 	"github.com/abhinavxd/libredesk/internal/ws/models"
 	"github.com/fasthttp/websocket"
@@ -14,6 +15,16 @@ type Hub struct {
 	clients      map[int][]*Client
 	clientsMutex sync.Mutex
 
+	// Conversation each client is viewing, used for collision detection between agents.
+	presence      map[*Client]*presence
+	presenceMutex sync.Mutex
+
+	// canAccessConversation checks if a user can view a conversation before tracking their presence in it.
+	canAccessConversation func(userID int, conversationUUID string) bool
+
+	// recordConversationView is called with the time an agent spent viewing a conversation, across all their devices.
+	recordConversationView func(userID int, conversationUUID string, from, to time.Time)
+
 	userStore userStore
 }
 
@@ -26,10 +37,21 @@ func NewHub(userStore userStore) *Hub {
 	return &Hub{
 		clients:      make(map[int][]*Client, 10000),
 		clientsMutex: sync.Mutex{},
+		presence:     make(map[*Client]*presence),
 		userStore:    userStore,
 	}
 }
 
+// SetConversationAccessChecker sets the function used to check conversation access before tracking presence.
+func (h *Hub) SetConversationAccessChecker(fn func(userID int, conversationUUID string) bool) {
+	h.canAccessConversation = fn
+}
+
+// SetConversationViewRecorder sets the function called with the time an agent spent viewing a conversation when they leave it.
+func (h *Hub) SetConversationViewRecorder(fn func(userID int, conversationUUID string, from, to time.Time)) {
+	h.recordConversationView = fn
+}
+
 // AddClient adds a new client to the hub.
 func (h *Hub) AddClient(client *Client) {
 	h.clientsMutex.Lock()
@@ -37,10 +59,9 @@ func (h *Hub) AddClient(client *Client) {
 	h.clients[client.ID] = append(h.clients[client.ID], client)
 }
 
-// RemoveClient removes a client from the hub.
+// RemoveClient removes a client from the hub and from the conversation it was viewing.
 func (h *Hub) RemoveClient(client *Client) {
 	h.clientsMutex.Lock()
-	defer h.clientsMutex.Unlock()
 	if clients, ok := h.clients[client.ID]; ok {
 		for i, c := range clients {
 			if c == client {
@@ -49,6 +70,10 @@ func (h *Hub) RemoveClient(client *Client) {
 			}
 		}
 	}
+	h.clientsMutex.Unlock()
+
+	// Broadcasts to other clients, so called after releasing the clients lock.
+	h.LeaveConversation(client)
 }
 
 // BroadcastMessage broadcasts a message to the specified users.
@@ -1,20 +1,24 @@
 CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
 DROP TYPE IF EXISTS "channels" CASCADE; CREATE TYPE "channels" AS ENUM ('email');
-DROP TYPE IF EXISTS "message_type" CASCADE; CREATE TYPE "message_type" AS ENUM ('incoming','outgoing','activity');
+DROP TYPE IF EXISTS "message_type" CASCADE; CREATE TYPE "message_type" AS ENUM ('incoming','outgoing','activity','forward');
 DROP TYPE IF EXISTS "message_sender_type" CASCADE; CREATE TYPE "message_sender_type" AS ENUM ('agent','contact');
-DROP TYPE IF EXISTS "message_status" CASCADE; CREATE TYPE "message_status" AS ENUM ('received','sent','failed','pending');
+DROP TYPE IF EXISTS "message_status" CASCADE; CREATE TYPE "message_status" AS ENUM ('received','sent','failed','pending','scheduled');
 DROP TYPE IF EXISTS "content_type" CASCADE; CREATE TYPE "content_type" AS ENUM ('text','html');
 DROP TYPE IF EXISTS "conversation_assignment_type" CASCADE; CREATE TYPE "conversation_assignment_type" AS ENUM ('Round robin','Manual');
 DROP TYPE IF EXISTS "template_type" CASCADE; CREATE TYPE "template_type" AS ENUM ('email_outgoing', 'email_notification');
 DROP TYPE IF EXISTS "user_type" CASCADE; CREATE TYPE "user_type" AS ENUM ('agent', 'contact');
 DROP TYPE IF EXISTS "ai_provider" CASCADE; CREATE TYPE "ai_provider" AS ENUM ('openai');
 DROP TYPE IF EXISTS "automation_execution_mode" CASCADE; CREATE TYPE "automation_execution_mode" AS ENUM ('all', 'first_match');
+DROP TYPE IF EXISTS "time_entry_source" CASCADE; CREATE TYPE "time_entry_source" AS ENUM ('manual', 'timer', 'auto');
 DROP TYPE IF EXISTS "macro_visibility" CASCADE; CREATE TYPE "macro_visibility" AS ENUM ('all', 'team', 'user');
 DROP TYPE IF EXISTS "media_disposition" CASCADE; CREATE TYPE "media_disposition" AS ENUM ('inline', 'attachment');
 DROP TYPE IF EXISTS "media_store" CASCADE; CREATE TYPE "media_store" AS ENUM ('s3', 'fs');
 DROP TYPE IF EXISTS "user_availability_status" CASCADE; CREATE TYPE "user_availability_status" AS ENUM ('online', 'away', 'away_manual', 'offline');
 DROP TYPE IF EXISTS "applied_sla_status" CASCADE; CREATE TYPE "applied_sla_status" AS ENUM ('pending', 'breached', 'met', 'partially_met');
+DROP TYPE IF EXISTS "status_category" CASCADE; CREATE TYPE "status_category" AS ENUM ('open', 'pending', 'solved', 'closed');
+DROP TYPE IF EXISTS "webhook_delivery_status" CASCADE; CREATE TYPE "webhook_delivery_status" AS ENUM ('pending', 'delivered', 'failed');
+DROP TYPE IF EXISTS "retention_action" CASCADE; CREATE TYPE "retention_action" AS ENUM ('delete_conversations', 'strip_attachments');
 
 -- Sequence to generate reference number for conversations.
 DROP SEQUENCE IF EXISTS conversation_reference_number_sequence; CREATE SEQUENCE conversation_reference_number_sequence START 100;
@@ -151,15 +155,27 @@ CREATE TABLE conversation_statuses (
 	id SERIAL PRIMARY KEY,
 	created_at TIMESTAMPTZ DEFAULT NOW(),
 	updated_at TIMESTAMPTZ DEFAULT NOW(),
-	"name" TEXT NOT NULL UNIQUE
+	"name" TEXT NOT NULL UNIQUE,
+	-- Drives reopening, SLA pause and met, dashboard counts and CSAT instead of the status name.
+	category status_category DEFAULT 'open' NOT NULL,
+	-- Marks the status conversations are snoozed with, there is only one.
+	is_snooze BOOL DEFAULT FALSE NOT NULL
 );
+CREATE UNIQUE INDEX index_conversation_statuses_on_is_snooze ON conversation_statuses(is_snooze) WHERE is_snooze;
 
 DROP TABLE IF EXISTS conversation_priorities CASCADE;
 CREATE TABLE conversation_priorities (
 	id SERIAL PRIMARY KEY,
 	created_at TIMESTAMPTZ DEFAULT NOW(),
 	updated_at TIMESTAMPTZ DEFAULT NOW(),
-	"name" TEXT NOT NULL UNIQUE
+	"name" TEXT NOT NULL UNIQUE,
+	color TEXT DEFAULT '' NOT NULL,
+	-- Higher rank is more urgent, drives priority sorting of conversations.
+	"rank" INT DEFAULT 0 NOT NULL,
+	-- SLA policy applied to conversations when they are set to this priority.
+	sla_policy_id INT REFERENCES sla_policies(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	CONSTRAINT constraint_conversation_priorities_on_name CHECK (length("name") <= 140),
+	CONSTRAINT constraint_conversation_priorities_on_color CHECK (length(color) <= 50)
 );
 
 DROP TABLE IF EXISTS contact_channels CASCADE;
@@ -230,6 +246,22 @@ CREATE INDEX index_conversations_on_last_message_at ON conversations (last_messa
 CREATE INDEX index_conversations_on_next_sla_deadline_at ON conversations (next_sla_deadline_at);
 CREATE INDEX index_conversations_on_waiting_since ON conversations (waiting_since);
 
+DROP TABLE IF EXISTS conversation_side_threads CASCADE;
+CREATE TABLE conversation_side_threads (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    "uuid" UUID DEFAULT gen_random_uuid() NOT NULL UNIQUE,
+    conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+    subject TEXT NOT NULL,
+    -- External recipients of the side thread, the contact of the conversation is never included.
+    "to" TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+    cc TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+    CONSTRAINT constraint_conversation_side_threads_on_subject CHECK (length(subject) <= 1000)
+);
+CREATE INDEX index_conversation_side_threads_on_conversation_id ON conversation_side_threads (conversation_id);
+
 DROP TABLE IF EXISTS conversation_messages CASCADE;
 CREATE TABLE conversation_messages (
     id BIGSERIAL PRIMARY KEY,
@@ -246,13 +278,18 @@ CREATE TABLE conversation_messages (
     source_id TEXT NULL,
  	sender_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
     sender_type message_sender_type NOT NULL,
-    meta JSONB DEFAULT '{}'::JSONB NULL
+    meta JSONB DEFAULT '{}'::JSONB NULL,
+    -- Scheduled outgoing messages are sent at this time.
+    send_at TIMESTAMPTZ NULL,
+    -- Messages of a side thread with external parties, hidden from the main conversation.
+    side_thread_id BIGINT REFERENCES conversation_side_threads(id) ON DELETE CASCADE ON UPDATE CASCADE NULL
 );
 CREATE INDEX index_trgm_conversation_messages_on_text_content ON conversation_messages USING GIN (text_content gin_trgm_ops);
 CREATE INDEX index_conversation_messages_on_conversation_id ON conversation_messages (conversation_id);
 CREATE INDEX index_conversation_messages_on_created_at ON conversation_messages (created_at);
 CREATE INDEX index_conversation_messages_on_source_id ON conversation_messages (source_id);
 CREATE INDEX index_conversation_messages_on_status ON conversation_messages (status);
+CREATE INDEX index_conversation_messages_on_side_thread_id ON conversation_messages (side_thread_id);
 
 DROP TABLE IF EXISTS automation_rules CASCADE;
 CREATE TABLE automation_rules (
@@ -267,12 +304,47 @@ CREATE TABLE automation_rules (
     enabled BOOL DEFAULT TRUE NOT NULL,
 	weight INT DEFAULT 0 NOT NULL,
 	execution_mode automation_execution_mode DEFAULT 'all' NOT NULL,
+    "version" INT DEFAULT 1 NOT NULL,
+    -- Cron expression of time trigger rules, empty runs the rule every hour.
+    schedule TEXT DEFAULT '' NOT NULL,
+    last_run_at TIMESTAMPTZ NULL,
+    -- Set when the rule is disabled by loop protection.
+    disabled_reason TEXT DEFAULT '' NOT NULL,
     CONSTRAINT constraint_automation_rules_on_name CHECK (length("name") <= 140),
     CONSTRAINT constraint_automation_rules_on_description CHECK (length(description) <= 300)
 );
 CREATE INDEX index_automation_rules_on_enabled_and_weight ON automation_rules(enabled, weight);
 CREATE INDEX index_automation_rules_on_type_and_weight ON automation_rules(type, weight);
 
+DROP TABLE IF EXISTS automation_rule_executions CASCADE;
+CREATE TABLE automation_rule_executions (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    -- Rule name and version are copied so the log stays readable after the rule is changed or deleted.
+    rule_id INT REFERENCES automation_rules(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+    rule_name TEXT NOT NULL,
+    rule_version INT NOT NULL,
+    conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+    "event" TEXT NOT NULL,
+    actions JSONB DEFAULT '[]'::jsonb NOT NULL,
+    has_errors BOOL DEFAULT FALSE NOT NULL
+);
+CREATE INDEX index_automation_rule_executions_on_rule_id ON automation_rule_executions(rule_id);
+CREATE INDEX index_automation_rule_executions_on_conversation_id ON automation_rule_executions(conversation_id);
+CREATE INDEX index_automation_rule_executions_on_created_at ON automation_rule_executions(created_at);
+CREATE INDEX index_automation_rule_executions_on_conversation_id_and_created_at ON automation_rule_executions(conversation_id, created_at);
+
+-- Conversations a time trigger rule has fired on, so the rule does not fire again on every run.
+DROP TABLE IF EXISTS automation_rule_triggers CASCADE;
+CREATE TABLE automation_rule_triggers (
+    rule_id INT REFERENCES automation_rules(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+    conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+    rule_version INT NOT NULL,
+    triggered_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (rule_id, conversation_id)
+);
+CREATE INDEX index_automation_rule_triggers_on_conversation_id ON automation_rule_triggers(conversation_id);
+
 DROP TABLE IF EXISTS macros CASCADE;
 CREATE TABLE macros (
    id SERIAL PRIMARY KEY,
@@ -301,6 +373,94 @@ CREATE TABLE conversation_participants (
 );
 CREATE UNIQUE INDEX index_unique_conversation_participants_on_conversation_id_and_user_id ON conversation_participants (conversation_id, user_id);
 
+DROP TABLE IF EXISTS conversation_followers CASCADE;
+CREATE TABLE conversation_followers (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	-- Cascade deletes when user or conversation is deleted.
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL
+);
+CREATE UNIQUE INDEX index_unique_conversation_followers_on_conversation_id_and_user_id ON conversation_followers (conversation_id, user_id);
+CREATE INDEX index_conversation_followers_on_user_id ON conversation_followers (user_id);
+
+DROP TABLE IF EXISTS conversation_mentions CASCADE;
+CREATE TABLE conversation_mentions (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	message_id BIGINT REFERENCES conversation_messages(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	mentioned_by_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	-- Either an agent or a team is mentioned.
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+	team_id INT REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+	-- Mentioned agents get read access to the conversation until this time.
+	access_expires_at TIMESTAMPTZ NOT NULL,
+	CONSTRAINT constraint_conversation_mentions_on_user_id_or_team_id CHECK ((user_id IS NULL) <> (team_id IS NULL))
+);
+CREATE INDEX index_conversation_mentions_on_conversation_id ON conversation_mentions (conversation_id);
+CREATE INDEX index_conversation_mentions_on_user_id ON conversation_mentions (user_id);
+CREATE INDEX index_conversation_mentions_on_team_id ON conversation_mentions (team_id);
+
+DROP TABLE IF EXISTS conversation_drafts CASCADE;
+CREATE TABLE conversation_drafts (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	"content" TEXT DEFAULT '' NOT NULL,
+	cc TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	bcc TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	-- Media uploaded for the draft, not yet linked to a message.
+	media_uuids UUID[] DEFAULT '{}'::UUID[] NOT NULL
+);
+CREATE UNIQUE INDEX index_unique_conversation_drafts_on_conversation_id_and_user_id ON conversation_drafts (conversation_id, user_id);
+CREATE INDEX index_gin_conversation_drafts_on_media_uuids ON conversation_drafts USING GIN (media_uuids);
+
+DROP TABLE IF EXISTS conversation_time_entries CASCADE;
+CREATE TABLE conversation_time_entries (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	source time_entry_source NOT NULL,
+	started_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	-- Running timers have no end time.
+	ended_at TIMESTAMPTZ NULL,
+	-- Duration in seconds.
+	duration INT DEFAULT 0 NOT NULL,
+	note TEXT DEFAULT '' NOT NULL,
+	billable BOOL DEFAULT FALSE NOT NULL,
+	CONSTRAINT constraint_conversation_time_entries_on_duration CHECK (duration >= 0),
+	CONSTRAINT constraint_conversation_time_entries_on_note CHECK (length(note) <= 1000)
+);
+CREATE INDEX index_conversation_time_entries_on_conversation_id ON conversation_time_entries (conversation_id);
+CREATE INDEX index_conversation_time_entries_on_started_at ON conversation_time_entries (started_at);
+-- An agent has at most one running timer.
+CREATE UNIQUE INDEX index_unique_conversation_time_entries_on_user_id_when_running ON conversation_time_entries (user_id) WHERE ended_at IS NULL;
+
+DROP TABLE IF EXISTS conversation_tasks CASCADE;
+CREATE TABLE conversation_tasks (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	created_by BIGINT REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	assigned_user_id BIGINT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+	title TEXT NOT NULL,
+	due_at TIMESTAMPTZ NOT NULL,
+	done_at TIMESTAMPTZ NULL,
+	-- Set once the assignee has been notified that the task is due.
+	notified_at TIMESTAMPTZ NULL,
+	CONSTRAINT constraint_conversation_tasks_on_title CHECK (length(title) <= 1000)
+);
+CREATE INDEX index_conversation_tasks_on_conversation_id ON conversation_tasks (conversation_id);
+CREATE INDEX index_conversation_tasks_on_assigned_user_id ON conversation_tasks (assigned_user_id);
+CREATE INDEX index_conversation_tasks_on_due_at_when_pending ON conversation_tasks (due_at) WHERE done_at IS NULL AND notified_at IS NULL;
+
 DROP TABLE IF EXISTS media CASCADE;
 CREATE TABLE media (
 	id SERIAL PRIMARY KEY,
@@ -403,7 +563,7 @@ CREATE TABLE csat_responses (
 	uuid UUID DEFAULT gen_random_uuid() NOT NULL UNIQUE,
 
 	-- Keep CSAT responses even if the conversation or agent is deleted.
-    conversation_id BIGINT REFERENCES conversations(id) ON DELETE SET NULL ON UPDATE CASCADE NOT NULL,
+    conversation_id BIGINT REFERENCES conversations(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
 
     rating INT DEFAULT 0 NOT NULL,
     feedback TEXT NULL,
@@ -435,7 +595,7 @@ CREATE TABLE applied_slas (
 	status applied_sla_status DEFAULT 'pending' NOT NULL,
 
 	-- Conversation / SLA policy maybe deleted but for reports the applied SLA should remain.
-	conversation_id BIGINT REFERENCES conversations(id) ON DELETE SET NULL ON UPDATE CASCADE NOT NULL,
+	conversation_id BIGINT REFERENCES conversations(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
 	sla_policy_id INT REFERENCES sla_policies(id) ON DELETE SET NULL ON UPDATE CASCADE NOT NULL,
 
 	first_response_deadline_at TIMESTAMPTZ NULL,
@@ -443,7 +603,9 @@ CREATE TABLE applied_slas (
 	first_response_breached_at TIMESTAMPTZ NULL,
 	resolution_breached_at TIMESTAMPTZ NULL,
 	first_response_met_at TIMESTAMPTZ NULL,
-	resolution_met_at TIMESTAMPTZ NULL
+	resolution_met_at TIMESTAMPTZ NULL,
+	-- Set while the conversation is in a pending status, deadlines are pushed back by the paused time on resume.
+	paused_at TIMESTAMPTZ NULL
 );
 CREATE INDEX index_applied_slas_on_conversation_id ON applied_slas(conversation_id);
 CREATE INDEX index_applied_slas_on_status ON applied_slas(status);
@@ -475,6 +637,73 @@ CREATE TABLE ai_prompts (
 );
 CREATE INDEX index_ai_prompts_on_key ON ai_prompts USING btree (key);
 
+DROP TABLE IF EXISTS retention_policies CASCADE;
+CREATE TABLE retention_policies (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	"name" TEXT NOT NULL,
+	-- NULL inbox means the policy is global, cascade deletes when inbox is deleted.
+	inbox_id INT REFERENCES inboxes(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+	action retention_action NOT NULL,
+	days INT NOT NULL,
+	enabled BOOL DEFAULT TRUE NOT NULL,
+	CONSTRAINT constraint_retention_policies_on_name CHECK (length("name") <= 140),
+	CONSTRAINT constraint_retention_policies_on_days CHECK (days > 0)
+);
+CREATE UNIQUE INDEX index_unique_retention_policies_on_inbox_id_and_action ON retention_policies (COALESCE(inbox_id, 0), action);
+
+DROP TABLE IF EXISTS retention_logs CASCADE;
+CREATE TABLE retention_logs (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	-- Keep logs even if the policy is deleted.
+	policy_id INT REFERENCES retention_policies(id) ON DELETE SET NULL ON UPDATE CASCADE NULL,
+	action retention_action NOT NULL,
+	conversation_uuid UUID NOT NULL,
+	conversation_reference_number TEXT NULL,
+	media_count INT DEFAULT 0 NOT NULL
+);
+CREATE INDEX index_retention_logs_on_created_at ON retention_logs(created_at);
+
+DROP TABLE IF EXISTS webhooks CASCADE;
+CREATE TABLE webhooks (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	"name" TEXT NOT NULL,
+	url TEXT NOT NULL,
+	secret TEXT DEFAULT '' NOT NULL,
+	events TEXT[] DEFAULT '{}'::TEXT[] NOT NULL,
+	headers JSONB DEFAULT '{}'::jsonb NOT NULL,
+	enabled BOOL DEFAULT TRUE NOT NULL,
+	CONSTRAINT constraint_webhooks_on_name CHECK (length("name") <= 140)
+);
+
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+CREATE TABLE webhook_deliveries (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMPTZ DEFAULT NOW(),
+	"event" TEXT NOT NULL,
+	url TEXT NOT NULL,
+	headers JSONB DEFAULT '{}'::jsonb NOT NULL,
+	-- HMAC secret used to sign the payload, empty means the payload is not signed.
+	secret TEXT DEFAULT '' NOT NULL,
+	payload TEXT NOT NULL,
+	conversation_uuid UUID NULL,
+	-- Deliveries of the send webhook automation action have no webhook.
+	webhook_id INT REFERENCES webhooks(id) ON DELETE CASCADE ON UPDATE CASCADE NULL,
+	status webhook_delivery_status DEFAULT 'pending' NOT NULL,
+	attempts INT DEFAULT 0 NOT NULL,
+	next_attempt_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+	response_status INT NULL,
+	last_error TEXT NULL
+);
+CREATE INDEX index_webhook_deliveries_on_status_and_next_attempt_at ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX index_webhook_deliveries_on_created_at ON webhook_deliveries(created_at);
+CREATE INDEX index_webhook_deliveries_on_webhook_id ON webhook_deliveries(webhook_id);
+
 INSERT INTO ai_providers
 ("name", provider, config, is_default)
 VALUES('openai', 'openai', '{"api_key": ""}'::jsonb, true);
@@ -516,17 +745,17 @@ VALUES
     ('notification.email.enabled', 'false'::jsonb);
 
 -- Default conversation priorities
-INSERT INTO conversation_priorities (name) VALUES
-('Low'),
-('Medium'),
-('High');
+INSERT INTO conversation_priorities (name, "rank") VALUES
+('Low', 1),
+('Medium', 2),
+('High', 3);
 
 -- Default conversation statuses
-INSERT INTO conversation_statuses (name) VALUES
-('Open'),          
-('Snoozed'),
-('Resolved'),
-('Closed');
+INSERT INTO conversation_statuses (name, category, is_snooze) VALUES
+('Open', 'open', false),
+('Snoozed', 'pending', true),
+('Resolved', 'solved', false),
+('Closed', 'closed', false);
 
 -- Default roles
 INSERT INTO
@@ -535,7 +764,7 @@ VALUES
 	(
 		'Agent',
 		'Role for all agents with limited access to conversations.',
-		'{conversations:read_all,conversations:read_unassigned,conversations:read_assigned,conversations:read_team_inbox,conversations:read,conversations:update_user_assignee,conversations:update_team_assignee,conversations:update_priority,conversations:update_status,conversations:update_tags,messages:read,messages:write,view:manage}'
+		'{conversations:read_all,conversations:read_unassigned,conversations:read_assigned,conversations:read_team_inbox,conversations:read,conversations:update_user_assignee,conversations:update_team_assignee,conversations:update_priority,conversations:update_status,conversations:update_tags,conversations:update_custom_attributes,messages:read,messages:write,view:manage}'
 	);
 
 INSERT INTO
@@ -544,7 +773,7 @@ VALUES
 	(
 		'Admin',
 		'Role for users who have complete access to everything.',
-		'{conversations:write,ai:manage,general_settings:manage,notification_settings:manage,oidc:manage,conversations:read_all,conversations:read_unassigned,conversations:read_assigned,conversations:read_team_inbox,conversations:read,conversations:update_user_assignee,conversations:update_team_assignee,conversations:update_priority,conversations:update_status,conversations:update_tags,messages:read,messages:write,view:manage,status:manage,tags:manage,macros:manage,users:manage,teams:manage,automations:manage,inboxes:manage,roles:manage,reports:manage,templates:manage,business_hours:manage,sla:manage}'
+		'{conversations:write,ai:manage,general_settings:manage,notification_settings:manage,oidc:manage,conversations:read_all,conversations:read_unassigned,conversations:read_assigned,conversations:read_team_inbox,conversations:read,conversations:update_user_assignee,conversations:update_team_assignee,conversations:update_priority,conversations:update_status,conversations:update_tags,conversations:update_custom_attributes,messages:read,messages:write,view:manage,status:manage,tags:manage,macros:manage,users:manage,teams:manage,automations:manage,inboxes:manage,roles:manage,reports:manage,templates:manage,business_hours:manage,sla:manage,retention:manage,priority:manage,webhooks:manage}'
 	);
 
 
@@ -570,4 +799,58 @@ VALUES('email_notification'::template_type, '
     Libredesk
 </div>
 
-', false, 'Conversation assigned', 'New conversation assigned to you', true);
\ No newline at end of file
+', false, 'Conversation assigned', 'New conversation assigned to you', true);
+
+INSERT INTO templates
+("type", body, is_default, "name", subject, is_builtin)
+VALUES('email_notification'::template_type, '
+<p>Hi {{ .Recipient.FirstName }},</p>
+
+<p>{{ .Event.Summary }}</p>
+
+<div>
+    Reference number: {{ .Conversation.ReferenceNumber }} <br>
+    Subject: {{ .Conversation.Subject }}
+</div>
+
+{{ if .Event.Content }}
+<blockquote>{{ .Event.Content }}</blockquote>
+{{ end }}
+
+<p>
+    <a href="{{ RootURL }}/inboxes/all/conversation/{{ .Conversation.UUID }}">View Conversation</a>
+</p>
+
+<div>
+    Best regards,<br>
+    Libredesk
+</div>
+
+', false, 'Conversation updated', 'Update on conversation #{{ .Conversation.ReferenceNumber }}', true);
+
+INSERT INTO templates
+("type", body, is_default, "name", subject, is_builtin)
+VALUES('email_notification'::template_type, '
+<p>Hi {{ .Recipient.FirstName }},</p>
+
+<p>{{ .Event.Summary }}</p>
+
+<div>
+    Reference number: {{ .Conversation.ReferenceNumber }} <br>
+    Subject: {{ .Conversation.Subject }}
+</div>
+
+{{ if .Event.Content }}
+<blockquote>{{ .Event.Content }}</blockquote>
+{{ end }}
+
+<p>
+    <a href="{{ RootURL }}/inboxes/all/conversation/{{ .Conversation.UUID }}">View Conversation</a>
+</p>
+
+<div>
+    Best regards,<br>
+    Libredesk
+</div>
+
+', false, 'Mentioned in conversation', 'You were mentioned in conversation #{{ .Conversation.ReferenceNumber }}', true);
\ No newline at end of file